@@ -62,8 +62,14 @@ type AuthenticationConfig struct {
 	ClientSecret     string
 	Key              string
 	Sandbox          string
+	Offline          bool
 	LoadToken        func() (*BearerToken, error)
 	SaveToken        func(token *BearerToken) error
+	// LockToken serializes token requests of concurrent processes, it returns
+	// the unlock function
+	LockToken func() (func(), error)
+	// OfflineRequest answers the requests in offline mode
+	OfflineRequest func(req *http.Request) (*http.Response, error)
 }
 
 // UpdateHeader adds the authentication headers to the passed http request
@@ -149,6 +155,15 @@ func (o *AuthenticationConfig) GetToken() (*BearerToken, error) {
 				return token, nil
 			}
 		}
+		// another process may have requested a token in the meantime
+		if o.LockToken != nil {
+			if unlock, err := o.LockToken(); err == nil {
+				defer unlock()
+				if token, _ := o.LoadToken(); token != nil && token.ValidIn(time.Minute) {
+					return token, nil
+				}
+			}
+		}
 	}
 	res, err := o.GetTokenRaw()
 	if err != nil {
@@ -275,6 +290,16 @@ func (o *AuthenticationConfig) FullRequestRaw(ctx context.Context, verb string,
 		return nil, err
 	}
 
+	if o.Offline {
+		if o.OfflineRequest == nil || verb != http.MethodGet {
+			return nil, fmt.Errorf("%s %s is not supported in offline mode", verb, req.URL.Path)
+		}
+		for k, v := range header {
+			req.Header.Add(k, v)
+		}
+		return o.OfflineRequest(req.WithContext(ctx))
+	}
+
 	if err = o.UpdateHeader(req); err != nil {
 		return nil, err
 	}
@@ -50,9 +50,9 @@ type Update struct {
 
 // UpdateOperation defines the update operation
 type UpdateOperation struct {
-	Operation string `json:"op" yaml:"op"`
-	Path      string `json:"path" yaml:"path"`
-	Value     string `json:"value" yaml:"value"`
+	Operation string      `json:"op" yaml:"op"`
+	Path      string      `json:"path" yaml:"path"`
+	Value     interface{} `json:"value" yaml:"value"`
 }
 
 // ListContainer returns a list of container
@@ -29,7 +29,7 @@ func NewContainerCache(auth *api.AuthenticationConfig, pp util.PathProvider) *Ma
 	t := NewTransformMap("_embedded", "https://ns.adobe.com/experience/xcore/container").
 		K("_instance", "parentName").
 		V("instanceId")
-	return NewMapFileCache(NewContainerCall(auth), t, time.Hour*24, util.NewLazyPath(pp, "container.json"))
+	return newMapFileCache(NewContainerCall(auth), t, KindContainer, time.Hour*24, pp, "container.json")
 }
 
 // AutoContainer resolves autmatically the container id
@@ -0,0 +1,93 @@
+/*
+Package cache consists of all caching related functions and data structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"time"
+
+	"github.com/fuxs/aepctl/util"
+)
+
+// entry is the content of a cache file
+type entry interface {
+	// Valid checks if the entry is still valid
+	Valid() bool
+	// expiry returns the expiry date
+	expiry() time.Time
+	// key returns the id of the cached resource
+	key() string
+}
+
+// fileCache contains the file handling shared by the file caches
+type fileCache struct {
+	file *util.JSONFile
+	// id is the id of the cached resource, files of other resources are
+	// ignored
+	id string
+	// stale is true if the expired cached data is served
+	stale bool
+	revalidation
+}
+
+// Path returns the path of the cache file
+func (c *fileCache) Path() string {
+	return c.file.Path()
+}
+
+// read loads the cache file into the passed entry
+func (c *fileCache) read(e entry) bool {
+	return c.file.Load(e) == nil && e.key() == c.id
+}
+
+// load returns the entry of the cache file. Expired entries are served up to
+// MaxStale while they are refreshed in the background. Otherwise fetch is
+// called and nil is returned.
+func (c *fileCache) load(newEntry func() entry, fetch func() error) (entry, error) {
+	// load from disk
+	e := newEntry()
+	if c.read(e) {
+		if e.Valid() {
+			return e, nil
+		}
+		if c.serveStale(e.expiry(), c.file.Path()) {
+			c.stale = true
+			return e, nil
+		}
+	}
+	// wait for concurrent refreshes of the same cache by other processes
+	if unlock, err := c.file.Lock(); err == nil {
+		defer unlock()
+		if e = newEntry(); c.read(e) && e.Valid() {
+			return e, nil
+		}
+	}
+	return nil, fetch()
+}
+
+// refresh calls fetch while the cache file is locked
+func (c *fileCache) refresh(fetch func() error) error {
+	if unlock, err := c.file.Lock(); err == nil {
+		defer unlock()
+	}
+	return fetch()
+}
+
+// save writes the fetched entry to the cache file
+func (c *fileCache) save(e entry) {
+	c.stale = false
+	_ = c.file.Save(e)
+}
@@ -37,44 +37,67 @@ func (c *EatByList) Valid() bool {
 	return time.Now().Before(c.Expires)
 }
 
+func (c *EatByList) expiry() time.Time {
+	return c.Expires
+}
+
+func (c *EatByList) key() string {
+	return ""
+}
+
 type ListFileCache struct {
 	API      APICall
 	Duration time.Duration
 	trans    *TransformList
-	cache    *util.JSONFile
 	cached   *EatByList
+	fileCache
 }
 
 func NewListFileCache(apiCall APICall, trans *TransformList, d time.Duration, file util.Path) *ListFileCache {
 	return &ListFileCache{
-		API:      apiCall,
-		Duration: d,
-		trans:    trans,
-		cache:    util.NewJSONFile(file),
+		API:       apiCall,
+		Duration:  d,
+		trans:     trans,
+		fileCache: fileCache{file: util.NewJSONFile(file)},
 	}
 }
 
+// newListFileCache creates an initialized ListFileCache object with the
+// configured lifetime of the cache kind
+func newListFileCache(apiCall APICall, trans *TransformList, kind string, d time.Duration, pp util.PathProvider, name string) *ListFileCache {
+	result := NewListFileCache(apiCall, trans, ttl(pp, kind, d), util.NewLazyPath(pp, name))
+	result.revalidation = newRevalidation(pp)
+	return result
+}
+
 // Delete deletes the corresponding file
 func (c *ListFileCache) Delete() error {
 	c.cached = nil
-	return c.cache.Delete()
+	return c.file.Delete()
 }
 
-// Load loads the cache
+// Refresh replaces the cached data with the response of the API call
+func (c *ListFileCache) Refresh() error {
+	c.cached = nil
+	return c.refresh(c.fetch)
+}
+
+// Load loads the cache. Expired caches are served up to MaxStale while they
+// are refreshed in the background.
 func (c *ListFileCache) Load() error {
 	// already loaded?
-	if c.cached != nil && c.cached.Valid() {
+	if c.cached != nil && (c.stale || c.cached.Valid()) {
 		return nil
 	}
-	// load from disk
-	eb := &EatByList{}
-	if err := c.cache.Load(&eb); err == nil {
-		if eb.Valid() {
-			c.cached = eb
-			return nil
-		}
+	e, err := c.load(func() entry { return &EatByList{} }, c.fetch)
+	if e != nil {
+		c.cached = e.(*EatByList)
 	}
-	// get data from server
+	return err
+}
+
+// fetch gets the data from the server and saves it
+func (c *ListFileCache) fetch() error {
 	obj, err := c.API.Call()
 	if err != nil {
 		return err
@@ -84,8 +107,7 @@ func (c *ListFileCache) Load() error {
 		Expires: time.Now().Add(c.Duration),
 	}
 	c.cached = result
-	// save result
-	_ = c.cache.Save(result)
+	c.save(result)
 	return nil
 }
 
@@ -33,57 +33,85 @@ func (c *EatByMap) ValidIn(d time.Duration) bool {
 	return time.Now().Add(d).Before(c.Expires)
 }
 
-//Valid checks if the token is still valid
+// Valid checks if the token is still valid
 func (c *EatByMap) Valid() bool {
 	return time.Now().Before(c.Expires)
 }
 
+func (c *EatByMap) expiry() time.Time {
+	return c.Expires
+}
+
+func (c *EatByMap) key() string {
+	return ""
+}
+
 // MapFileCache is a file cache for maps
 type MapFileCache struct {
 	API      APICall
 	Duration time.Duration
-	trans    *TransformMap
-	cache    *util.JSONFile
-	cached   *EatByMap
+	// IsID returns true for keys looking like ids, they do not trigger a
+	// refresh
+	IsID   func(string) bool
+	trans  *TransformMap
+	cached *EatByMap
+	// fetched is true if the cached data is the response of the API call
+	fetched bool
+	fileCache
 }
 
 // NewMapFileCache creates an intialized MapFileCache object
 func NewMapFileCache(apiCall APICall, trans *TransformMap, d time.Duration, file util.Path) *MapFileCache {
 	return &MapFileCache{
-		API:      apiCall,
-		Duration: d,
-		trans:    trans,
-		cache:    util.NewJSONFile(file),
+		API:       apiCall,
+		Duration:  d,
+		trans:     trans,
+		fileCache: fileCache{file: util.NewJSONFile(file)},
 	}
 }
 
+// newMapFileCache creates an initialized MapFileCache object with the
+// configured lifetime of the cache kind
+func newMapFileCache(apiCall APICall, trans *TransformMap, kind string, d time.Duration, pp util.PathProvider, name string) *MapFileCache {
+	result := NewMapFileCache(apiCall, trans, ttl(pp, kind, d), util.NewLazyPath(pp, name))
+	result.revalidation = newRevalidation(pp)
+	return result
+}
+
 // DeleteE deletes the corresponding file
 func (c *MapFileCache) Delete() {
 	c.cached = nil
-	_ = c.cache.Delete()
+	_ = c.file.Delete()
 }
 
 // DeleteE deletes the corresponding file
 func (c *MapFileCache) DeleteE() error {
 	c.cached = nil
-	return c.cache.Delete()
+	return c.file.Delete()
 }
 
-// Load loads the cache
+// Refresh replaces the cached data with the response of the API call
+func (c *MapFileCache) Refresh() error {
+	c.cached = nil
+	return c.refresh(c.fetch)
+}
+
+// Load loads the cache. Expired caches are served up to MaxStale while they
+// are refreshed in the background.
 func (c *MapFileCache) Load() error {
 	// already loaded?
-	if c.cached != nil && c.cached.Valid() {
+	if c.cached != nil && (c.stale || c.cached.Valid()) {
 		return nil
 	}
-	// load from disk
-	eb := &EatByMap{}
-	if err := c.cache.Load(&eb); err == nil {
-		if eb.Valid() {
-			c.cached = eb
-			return nil
-		}
+	e, err := c.load(func() entry { return &EatByMap{} }, c.fetch)
+	if e != nil {
+		c.cached = e.(*EatByMap)
 	}
-	// get data from server
+	return err
+}
+
+// fetch gets the data from the server and saves it
+func (c *MapFileCache) fetch() error {
 	obj, err := c.API.Call()
 	if err != nil {
 		return err
@@ -92,25 +120,31 @@ func (c *MapFileCache) Load() error {
 		Map:     c.trans.Transform(obj),
 		Expires: time.Now().Add(c.Duration),
 	}
-	c.cached = result
-	// save result
-	_ = c.cache.Save(result)
+	c.cached, c.fetched = result, true
+	c.save(result)
 	return nil
 }
 
 // Lookup returns either the related value or the key itself
 func (c *MapFileCache) Lookup(key string) string {
-	if err := c.Load(); err != nil {
-		return key
+	if result, err := c.LookupE(key); err == nil {
+		return result
 	}
-	return c.cached.Map.Lookup(key)
+	return key
 }
 
-// LookupE returns either the related value or an error
+// LookupE returns either the related value or an error. Unknown names of a
+// cache loaded from disk trigger the refresh of the cache, ids and values of
+// the map do not.
 func (c *MapFileCache) LookupE(key string) (string, error) {
 	if err := c.Load(); err != nil {
 		return "", err
 	}
+	if _, ok := c.cached.Map[key]; !ok && !c.fetched && !c.known(key) {
+		if err := c.fetch(); err != nil {
+			return "", err
+		}
+	}
 	return c.cached.Map.Lookup(key), nil
 }
 
@@ -129,3 +163,16 @@ func (c *MapFileCache) Values() []string {
 	}
 	return c.cached.Map.Values()
 }
+
+// known returns true if the key is an id or one of the values
+func (c *MapFileCache) known(key string) bool {
+	if c.IsID != nil && c.IsID(key) {
+		return true
+	}
+	for _, value := range c.cached.Map {
+		if value == key {
+			return true
+		}
+	}
+	return false
+}
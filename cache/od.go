@@ -17,11 +17,22 @@ specific language governing permissions and limitations under the License.
 package cache
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/fuxs/aepctl/util"
 )
 
+// odInstanceID matches the instance ids of Offer Decisioning objects
+var odInstanceID = regexp.MustCompile(`^[0-9a-fA-F]{8}(-?[0-9a-fA-F]{4}){3}-?[0-9a-fA-F]{12}$`)
+
+// IsODID returns true if the passed value is an @id or an instance id of an
+// Offer Decisioning object
+func IsODID(value string) bool {
+	return strings.HasPrefix(value, "xcore:") || odInstanceID.MatchString(value)
+}
+
 // ODNameToID returns a transformation xdm:name -> @id
 func ODNameToID() *TransformMap {
 	return NewTransformMap("_embedded", "results").
@@ -40,14 +51,18 @@ func ODNameToInstanceID() *TransformMap {
 func NewODNameToID(ac *AutoContainer, name, schema string, pp util.PathProvider) *MapFileCache {
 	call := NewODCall(ac, schema)
 	t := ODNameToID()
-	return NewMapFileCache(call, t, time.Minute, util.NewLazyPath(pp, name+"_n2id.json"))
+	result := newMapFileCache(call, t, KindOD, time.Hour, pp, name+"_n2id.json")
+	result.IsID = IsODID
+	return result
 }
 
 // NewODNameToInstanceID creates an initialzed MapFileCache object with a xdm:name -> instanceId
 func NewODNameToInstanceID(ac *AutoContainer, name, schema string, pp util.PathProvider) *MapFileCache {
 	call := NewODCall(ac, schema)
 	t := ODNameToInstanceID()
-	return NewMapFileCache(call, t, time.Minute, util.NewLazyPath(pp, name+"_n2iid.json"))
+	result := newMapFileCache(call, t, KindOD, time.Hour, pp, name+"_n2iid.json")
+	result.IsID = IsODID
+	return result
 }
 
 // NewODNameToIDMem creates an initialzed MemCache object with a xdm:name -> @id
@@ -0,0 +1,125 @@
+/*
+Package cache consists of all caching relted functions and data structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/fuxs/aepctl/util"
+)
+
+// ODResolver resolves the names of offer decisioning objects for a whole
+// command run. Each schema is listed only once and all unresolved names are
+// collected, see Err.
+type ODResolver struct {
+	ac         *AutoContainer
+	trans      func() *TransformMap
+	calls      map[string]APICall
+	names      map[string]util.Mapper
+	ids        map[string]map[string]bool
+	failed     map[string]bool
+	unresolved []string
+	errs       []string
+}
+
+// NewODResolver creates an initialized ODResolver object for xdm:name -> @id
+func NewODResolver(ac *AutoContainer) *ODResolver {
+	return newODResolver(ac, ODNameToID)
+}
+
+// NewODInstanceResolver creates an initialized ODResolver object for
+// xdm:name -> instanceId
+func NewODInstanceResolver(ac *AutoContainer) *ODResolver {
+	return newODResolver(ac, ODNameToInstanceID)
+}
+
+func newODResolver(ac *AutoContainer, trans func() *TransformMap) *ODResolver {
+	return &ODResolver{
+		ac:     ac,
+		trans:  trans,
+		calls:  make(map[string]APICall),
+		names:  make(map[string]util.Mapper),
+		ids:    make(map[string]map[string]bool),
+		failed: make(map[string]bool),
+	}
+}
+
+// Map returns the transformed list of the schema. The list is requested only
+// once per schema, errors are collected and nil is returned.
+func (r *ODResolver) Map(schema string, trans *TransformMap) util.Mapper {
+	if r.failed[schema] {
+		return nil
+	}
+	call, ok := r.calls[schema]
+	if !ok {
+		call = NewODCall(r.ac, schema)
+		r.calls[schema] = call
+	}
+	obj, err := call.Call()
+	if err != nil {
+		r.failed[schema] = true
+		r.errs = append(r.errs, fmt.Sprintf("listing %s failed: %v", path.Base(schema), err))
+		return nil
+	}
+	return trans.Transform(obj)
+}
+
+// Resolve returns the id of the passed name. Empty strings and ids are
+// returned unchanged, unknown names are returned and collected.
+func (r *ODResolver) Resolve(schema, name string) string {
+	if name == "" {
+		return name
+	}
+	names, ok := r.names[schema]
+	if !ok {
+		names = r.Map(schema, r.trans())
+		ids := make(map[string]bool, len(names))
+		for _, id := range names {
+			ids[id] = true
+		}
+		r.names[schema], r.ids[schema] = names, ids
+	}
+	if names == nil {
+		return name
+	}
+	if id, ok := names[name]; ok {
+		return id
+	}
+	if !r.ids[schema][name] {
+		r.unresolved = append(r.unresolved, fmt.Sprintf("%s %q", path.Base(schema), name))
+	}
+	return name
+}
+
+// Err returns an error with all unresolved names and failed requests or nil
+func (r *ODResolver) Err() error {
+	if len(r.unresolved) == 0 && len(r.errs) == 0 {
+		return nil
+	}
+	var sb strings.Builder
+	for _, msg := range r.errs {
+		sb.WriteString("\n  ")
+		sb.WriteString(msg)
+	}
+	for _, name := range r.unresolved {
+		sb.WriteString("\n  unknown ")
+		sb.WriteString(name)
+	}
+	return fmt.Errorf("could not resolve all references, nothing has been changed:%s", sb.String())
+}
@@ -42,5 +42,5 @@ func (c *SandboxCall) Call() (interface{}, error) {
 // NewSandboxCache creates an initilzed ListFileCache object for lists of sandboxes
 func NewSandboxCache(auth *api.AuthenticationConfig, pp util.PathProvider) *ListFileCache {
 	t := NewTransformList("sandboxes").V("name")
-	return NewListFileCache(NewSandboxCall(auth), t, time.Hour*24, util.NewLazyPath(pp, "sandboxes.json"))
+	return newListFileCache(NewSandboxCall(auth), t, KindSandboxes, time.Hour*24, pp, "sandboxes.json")
 }
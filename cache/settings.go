@@ -0,0 +1,77 @@
+/*
+Package cache consists of all caching related functions and data structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"time"
+
+	"github.com/fuxs/aepctl/util"
+)
+
+// Cache kinds for the configuration of the lifetimes
+const (
+	KindSandboxes = "sandboxes"
+	KindContainer = "container"
+	KindOD        = "od"
+)
+
+// Settings is optionally implemented by the PathProvider of a file cache. It
+// configures the lifetime of the cache kinds and the refresh of expired
+// caches in the background.
+type Settings interface {
+	// TTL returns the lifetime of the cache kind or the passed default
+	TTL(kind string, d time.Duration) time.Duration
+	// MaxStale returns the duration an expired cache is still served
+	MaxStale() time.Duration
+	// Revalidate refreshes the cache file in the background
+	Revalidate(path string)
+}
+
+// ttl returns the configured lifetime of the cache kind
+func ttl(pp util.PathProvider, kind string, d time.Duration) time.Duration {
+	if s, ok := pp.(Settings); ok {
+		return s.TTL(kind, d)
+	}
+	return d
+}
+
+// revalidation serves expired caches while they are refreshed in the
+// background
+type revalidation struct {
+	// MaxStale is the duration an expired cache is still served
+	MaxStale time.Duration
+	// Revalidate refreshes the cache file in the background
+	Revalidate func(path string)
+}
+
+// newRevalidation returns the settings of the PathProvider
+func newRevalidation(pp util.PathProvider) revalidation {
+	if s, ok := pp.(Settings); ok {
+		return revalidation{MaxStale: s.MaxStale(), Revalidate: s.Revalidate}
+	}
+	return revalidation{}
+}
+
+// serveStale returns true if the cache expired at the passed time can still
+// be served. The refresh of the cache file is started in this case.
+func (r *revalidation) serveStale(expires time.Time, path string) bool {
+	if r.Revalidate == nil || time.Since(expires) > r.MaxStale {
+		return false
+	}
+	r.Revalidate(path)
+	return true
+}
@@ -0,0 +1,223 @@
+/*
+Package cache consists of all caching related functions and data structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/util"
+)
+
+// KindSR is the cache kind of the Schema Registry title to id maps
+const KindSR = "sr"
+
+// SRSeparator separates the ids of resources with the same title
+const SRSeparator = " "
+
+// SRResources maps the names of the Schema Registry resources with titles to
+// the list functions
+var SRResources = map[string]api.Func{
+	"classes":     api.SRListClassesP,
+	"datatypes":   api.SRListDataTypesP,
+	"fieldgroups": api.SRListFieldGroupsP,
+	"schemas":     api.SRListSchemasP,
+	"unions":      api.SRListUnionsP,
+}
+
+// SRCall lists all resources of a Schema Registry type, by default in the
+// short format
+type SRCall struct {
+	auth   *api.AuthenticationConfig
+	f      api.Func
+	global bool
+	accept string
+}
+
+// NewSRCall creates an initialized SRCall object
+func NewSRCall(auth *api.AuthenticationConfig, f api.Func, global bool) APICall {
+	return NewCachedAPICall(&SRCall{auth: auth, f: f, global: global})
+}
+
+// NewSRAcceptCall creates an initialized SRCall object requesting the passed
+// format, e.g. the complete objects with application/vnd.adobe.xed+json
+func NewSRAcceptCall(auth *api.AuthenticationConfig, f api.Func, global bool, accept string) APICall {
+	return NewCachedAPICall(&SRCall{auth: auth, f: f, global: global, accept: accept})
+}
+
+// request returns the request of the first page
+func (c *SRCall) request(p *api.SRListParams) *api.Request {
+	req := p.Request()
+	if c.accept != "" {
+		req.Accept(c.accept)
+	}
+	return req
+}
+
+// Call is the entry point. It requests all pages and returns the results in
+// one object.
+func (c *SRCall) Call() (interface{}, error) {
+	p := &api.SRListParams{}
+	p.Limit = -1
+	p.Predefined = c.global
+	p.Short = true
+	req := c.request(p)
+	results := []interface{}{}
+	for req != nil {
+		res, err := api.HandleStatusCode(c.f(context.Background(), c.auth, req))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Results []interface{} `json:"results"`
+			Links   struct {
+				Next struct {
+					Href string `json:"href"`
+				} `json:"next"`
+			} `json:"_links"`
+		}
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, page.Results...)
+		next := c.request(p)
+		req = nil
+		if page.Links.Next.Href != "" {
+			u, err := url.Parse(page.Links.Next.Href)
+			if err != nil {
+				return nil, err
+			}
+			if start := u.Query().Get("start"); start != "" {
+				next.AddQueries("start", start, "orderby", u.Query().Get("orderby"))
+				req = next
+			}
+		}
+	}
+	return map[string]interface{}{"results": results}, nil
+}
+
+// SRTitleToID returns a transformation title -> $id. The ids of resources
+// with the same title are separated by SRSeparator.
+func SRTitleToID() *TransformMap {
+	return NewTransformMap("results").
+		K("title").
+		V("$id").
+		J(SRSeparator)
+}
+
+// NewSRTitleToID creates an initialized MapFileCache object with a title ->
+// $id map of the Schema Registry resources, e.g. schemas
+func NewSRTitleToID(auth *api.AuthenticationConfig, name string, global bool, pp util.PathProvider) *MapFileCache {
+	file := name + "_t2id.json"
+	if global {
+		file = "global_" + file
+	}
+	result := newMapFileCache(NewSRCall(auth, SRResources[name], global), SRTitleToID(), KindSR, time.Hour, pp, file)
+	result.IsID = IsSRID
+	return result
+}
+
+// SRTitles resolves the titles of Schema Registry resources
+type SRTitles struct {
+	caches []*MapFileCache
+}
+
+// NewSRTitles creates an initialized SRTitles object for the passed resources,
+// e.g. schemas. Without names all resources with titles are used.
+func NewSRTitles(auth *api.AuthenticationConfig, global bool, pp util.PathProvider, names ...string) *SRTitles {
+	if len(names) == 0 {
+		for name := range SRResources {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+	result := &SRTitles{caches: make([]*MapFileCache, len(names))}
+	for i, name := range names {
+		result.caches[i] = NewSRTitleToID(auth, name, global, pp)
+	}
+	return result
+}
+
+// IsSRID returns true if the passed value is a $id or meta:altId
+func IsSRID(value string) bool {
+	return strings.HasPrefix(value, "https://") || strings.HasPrefix(value, "_")
+}
+
+// Resolve returns the $id of the resource with the passed title. Ids and
+// unknown titles are returned unchanged, titles of multiple resources are an
+// error.
+func (t *SRTitles) Resolve(title string) (string, error) {
+	if IsSRID(title) {
+		return title, nil
+	}
+	var ids []string
+	for _, c := range t.caches {
+		value, err := c.LookupE(title)
+		if err != nil {
+			return "", err
+		}
+		if value != title {
+			ids = append(ids, strings.Split(value, SRSeparator)...)
+		}
+	}
+	switch len(ids) {
+	case 0:
+		return title, nil
+	case 1:
+		return ids[0], nil
+	}
+	return "", fmt.Errorf("the title %q is ambiguous, use one of the ids:\n  %s", title, strings.Join(ids, "\n  "))
+}
+
+// Keys returns the titles of all resources
+func (t *SRTitles) Keys() []string {
+	var result []string
+	for _, c := range t.caches {
+		result = append(result, c.Keys()...)
+	}
+	return result
+}
+
+// SRDescriptors requests the descriptors of the tenant. The filter is
+// optional, e.g. xdm:sourceSchema==ID.
+func SRDescriptors(auth *api.AuthenticationConfig, filter string) ([]*util.Query, error) {
+	p := &api.SRListDescriptorsParams{SRDescriptorFormat: api.AcceptObjects}
+	p.Limit = -1
+	p.Filter = filter
+	obj, err := decode(api.SRListDescriptors(context.Background(), auth, p))
+	if err != nil {
+		return nil, err
+	}
+	// the descriptors are either listed in results or grouped by type
+	q := util.NewQuery(obj)
+	if results := q.Path("results"); !results.Nil() {
+		return results.QueryArray(), nil
+	}
+	var result []*util.Query
+	q.RangeAttributes(func(_ string, group *util.Query) {
+		result = append(result, group.QueryArray()...)
+	})
+	return result, nil
+}
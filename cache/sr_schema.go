@@ -0,0 +1,184 @@
+/*
+Package cache consists of all caching related functions and data structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/util"
+)
+
+// KindSchema is the cache kind of the resolved schemas
+const KindSchema = "schemas"
+
+// SRSchemaPrefix is the prefix of the cache files with resolved schemas
+const SRSchemaPrefix = "schema_"
+
+// EatByDoc stores a document with an expiry date
+type EatByDoc struct {
+	ID      string
+	Doc     interface{}
+	Expires time.Time
+}
+
+// Valid checks if the document is still valid
+func (c *EatByDoc) Valid() bool {
+	return time.Now().Before(c.Expires)
+}
+
+func (c *EatByDoc) expiry() time.Time {
+	return c.Expires
+}
+
+func (c *EatByDoc) key() string {
+	return c.ID
+}
+
+// DocFileCache is a file cache for the document of a single resource
+type DocFileCache struct {
+	API      APICall
+	Duration time.Duration
+	cached   *EatByDoc
+	fileCache
+}
+
+// Delete deletes the corresponding file
+func (c *DocFileCache) Delete() error {
+	c.cached = nil
+	return c.file.Delete()
+}
+
+// Refresh replaces the cached document with the response of the API call
+func (c *DocFileCache) Refresh() error {
+	c.cached = nil
+	return c.refresh(c.fetch)
+}
+
+// Load loads the cache. Expired caches are served up to MaxStale while they
+// are refreshed in the background.
+func (c *DocFileCache) Load() error {
+	if c.cached != nil && (c.stale || c.cached.Valid()) {
+		return nil
+	}
+	e, err := c.load(func() entry { return &EatByDoc{} }, c.fetch)
+	if e != nil {
+		c.cached = e.(*EatByDoc)
+	}
+	return err
+}
+
+// fetch gets the document from the server and saves it
+func (c *DocFileCache) fetch() error {
+	obj, err := c.API.Call()
+	if err != nil {
+		return err
+	}
+	c.cached = &EatByDoc{
+		ID:      c.id,
+		Doc:     obj,
+		Expires: time.Now().Add(c.Duration),
+	}
+	c.save(c.cached)
+	return nil
+}
+
+// Doc returns the cached document
+func (c *DocFileCache) Doc() (interface{}, error) {
+	if err := c.Load(); err != nil {
+		return nil, err
+	}
+	return c.cached.Doc, nil
+}
+
+// SRSchemaCall requests the fully resolved schema and its identity
+// descriptors
+type SRSchemaCall struct {
+	auth   *api.AuthenticationConfig
+	id     string
+	global bool
+}
+
+// Call is the entry point. The result has the attributes schema and
+// identities.
+func (c *SRSchemaCall) Call() (interface{}, error) {
+	p := &api.SRGetParams{
+		SRGetBaseParams: api.SRGetBaseParams{ID: c.id, Global: c.global},
+		SRFormat:        api.SRFormat{Full: true, Version: "1"},
+	}
+	schema, err := decode(api.SRGetSchema(context.Background(), c.auth, p))
+	if err != nil {
+		return nil, err
+	}
+	// the descriptors reference the $id, the passed id may be the meta:altId
+	id := util.NewQuery(schema).StrOrEmpty("$id")
+	if id == "" {
+		id = c.id
+	}
+	items, err := SRDescriptors(c.auth, "xdm:sourceSchema=="+id)
+	if err != nil {
+		return nil, err
+	}
+	identities := []interface{}{}
+	for _, item := range items {
+		if item.StrOrEmpty("@type") == "xdm:descriptorIdentity" && item.StrOrEmpty("xdm:sourceSchema") == id {
+			identities = append(identities, item.Interface())
+		}
+	}
+	return map[string]interface{}{"schema": schema, "identities": identities}, nil
+}
+
+// decode returns the JSON document of the response
+func decode(res *http.Response, err error) (interface{}, error) {
+	res, err = api.HandleStatusCode(res, err)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var obj interface{}
+	err = json.NewDecoder(res.Body).Decode(&obj)
+	return obj, err
+}
+
+// SRSchemaFile returns the name of the cache file of the schema
+func SRSchemaFile(id string, global bool) string {
+	sum := sha1.Sum([]byte(id))
+	result := SRSchemaPrefix + hex.EncodeToString(sum[:8]) + ".json"
+	if global {
+		result = "global_" + result
+	}
+	return result
+}
+
+// NewSRSchema creates an initialized DocFileCache object with the resolved
+// schema and its identity descriptors
+func NewSRSchema(auth *api.AuthenticationConfig, id string, global bool, pp util.PathProvider) *DocFileCache {
+	return &DocFileCache{
+		API:      NewCachedAPICall(&SRSchemaCall{auth: auth, id: id, global: global}),
+		Duration: ttl(pp, KindSchema, time.Hour),
+		fileCache: fileCache{
+			file:         util.NewJSONFile(util.NewLazyPath(pp, SRSchemaFile(id, global))),
+			id:           id,
+			revalidation: newRevalidation(pp),
+		},
+	}
+}
@@ -23,6 +23,7 @@ type TransformMap struct {
 	Path  []string
 	Key   []string
 	Value []string
+	Join  string
 }
 
 // NewTransformMap creates an initialzed TransformMap object
@@ -48,11 +49,22 @@ func (c *TransformMap) V(v ...string) *TransformMap {
 	return c
 }
 
+// J joins the values of duplicate keys with the passed separator instead of
+// replacing them
+func (c *TransformMap) J(sep string) *TransformMap {
+	c.Join = sep
+	return c
+}
+
 // Transform transforms the passed ojbect to a mapper
 func (c TransformMap) Transform(obj interface{}) util.Mapper {
 	m := make(util.Mapper)
 	util.NewQuery(obj).Path(c.Path...).Range(func(q *util.Query) {
-		m[q.Str(c.Key...)] = q.Str(c.Value...)
+		key, value := q.Str(c.Key...), q.Str(c.Value...)
+		if old, ok := m[key]; ok && c.Join != "" {
+			value = old + c.Join + value
+		}
+		m[key] = value
 	})
 	return m
 }
@@ -17,13 +17,17 @@ specific language governing permissions and limitations under the License.
 package cmd
 
 import (
+	"github.com/fuxs/aepctl/cmd/apply"
 	"github.com/fuxs/aepctl/cmd/audit"
+	"github.com/fuxs/aepctl/cmd/cache"
 	"github.com/fuxs/aepctl/cmd/cancel"
 	"github.com/fuxs/aepctl/cmd/completion"
 	"github.com/fuxs/aepctl/cmd/configure"
 	"github.com/fuxs/aepctl/cmd/create"
 	"github.com/fuxs/aepctl/cmd/delete"
+	"github.com/fuxs/aepctl/cmd/diff"
 	"github.com/fuxs/aepctl/cmd/download"
+	"github.com/fuxs/aepctl/cmd/edit"
 	"github.com/fuxs/aepctl/cmd/export"
 	"github.com/fuxs/aepctl/cmd/extern"
 	"github.com/fuxs/aepctl/cmd/get"
@@ -31,8 +35,13 @@ import (
 	imp "github.com/fuxs/aepctl/cmd/import"
 	"github.com/fuxs/aepctl/cmd/list"
 	"github.com/fuxs/aepctl/cmd/patch"
+	"github.com/fuxs/aepctl/cmd/render"
+	"github.com/fuxs/aepctl/cmd/sr"
+	"github.com/fuxs/aepctl/cmd/sync"
+	"github.com/fuxs/aepctl/cmd/trans"
 	"github.com/fuxs/aepctl/cmd/trigger"
 	"github.com/fuxs/aepctl/cmd/update"
+	"github.com/fuxs/aepctl/cmd/validate"
 	"github.com/fuxs/aepctl/cmd/version"
 	"github.com/fuxs/aepctl/util"
 
@@ -67,6 +76,13 @@ func NewCommand() *cobra.Command {
 	cmd.AddCommand(list.NewCommand(conf))
 	cmd.AddCommand(update.NewCommand(conf))
 	cmd.AddCommand(patch.NewCommand(conf))
+	cmd.AddCommand(diff.NewCommand(conf))
+	cmd.AddCommand(edit.NewCommand(conf))
+	cmd.AddCommand(cache.NewCommand(conf))
+	cmd.AddCommand(sync.NewCommand(conf))
+	cmd.AddCommand(sr.NewCommand(conf))
+	cmd.AddCommand(apply.NewCommand(conf))
+	cmd.AddCommand(validate.NewCommand(conf))
 	cmd.AddCommand(completion.NewCommand())
 	cmd.AddCommand(completion.NewZSHCommand(gcfg))
 	cmd.AddCommand(configure.NewConfigureCommand(gcfg))
@@ -77,6 +93,8 @@ func NewCommand() *cobra.Command {
 	cmd.AddCommand(version.NewCommand(Version))
 	cmd.AddCommand(extern.NewPSQLCommand(conf))
 	cmd.AddCommand(trigger.NewCommand(conf))
+	cmd.AddCommand(trans.NewCommand(gcfg))
+	cmd.AddCommand(render.NewCommand(gcfg))
 	return cmd
 }
 
@@ -0,0 +1,128 @@
+/*
+Package apply contains apply command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package apply
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Create or update the Schema Registry resources of files and directories.
+
+	The classes, data types, field groups, schemas and descriptors are detected
+	by meta:resourceType, the type of the descriptor, the $id or the structure
+	and are applied in this order. Existing resources are matched by $id or
+	title and patched, missing resources are created. References to the $id of
+	other files are replaced by the id of the created resource. The placeholder
+	{TENANT_ID} is replaced by the tenant id of the organization.
+
+	The plan is printed before any change, --dry-run prints only the plan.
+	--prune deletes the resources of the tenant with a kind of the files which
+	are not part of the files, descriptors only for the schemas of the files.
+	The deletions are confirmed interactively or with --yes.`)
+	example = util.Example(`
+	aepctl apply -f schemas/
+	aepctl apply -f schemas/ --dry-run
+	aepctl apply -f classes/ -f schemas/loyalty.yaml --prune
+	aepctl apply -f schemas/ --prune --yes`)
+)
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	var (
+		paths []string
+		prune bool
+		yes   bool
+	)
+	cmd := &cobra.Command{
+		Use:                   "apply -f PATH",
+		Short:                 "Create or update Schema Registry resources of files",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Validate(cmd))
+			if len(paths) == 0 {
+				helper.CheckErr(errors.New("at least one file or directory is required, use -f PATH"))
+			}
+			names, err := files(paths)
+			helper.CheckErr(err)
+			// the plan is always created, even with --dry-run
+			auth := conf.NoDryRun()
+			var tenant string
+			resources, err := read(names, func() (string, error) {
+				if tenant != "" {
+					return tenant, nil
+				}
+				id, err := tenantID(auth)
+				tenant = id
+				return id, err
+			})
+			helper.CheckErr(err)
+			if len(resources) == 0 {
+				helper.CheckErr(fmt.Errorf("no resources found in %v", paths))
+			}
+			p, err := newPlan(auth, resources, prune)
+			helper.CheckErr(err)
+			helper.CheckErr(p.Write(os.Stdout))
+			if conf.Authentication.DryRun {
+				return
+			}
+			if n := len(p.deletes); n > 0 && !yes {
+				ok, err := confirm(n)
+				helper.CheckErr(err)
+				if !ok {
+					fmt.Println("Apply cancelled, no changes made.")
+					return
+				}
+			}
+			helper.CheckErr(p.Apply(os.Stdout))
+		},
+	}
+	conf.AddAuthenticationFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringArrayVarP(&paths, "filename", "f", nil, "file or directory with JSON or YAML resources")
+	flags.BoolVar(&prune, "prune", false, "delete resources of the tenant which are not part of the files")
+	flags.BoolVar(&yes, "yes", false, "delete the pruned resources without confirmation")
+	return cmd
+}
+
+// confirm asks for the confirmation of the deletions. Without a terminal the
+// deletions have to be confirmed with --yes.
+func confirm(n int) (bool, error) {
+	if !util.IsTerminal(os.Stdin) {
+		return false, fmt.Errorf("--prune deletes %d resources, confirm the deletion with --yes", n)
+	}
+	fmt.Printf("Delete %d resources? [y/N] ", n)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
@@ -0,0 +1,464 @@
+/*
+Package apply contains apply command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/util"
+)
+
+// plan actions
+const (
+	actionCreate    = "create"
+	actionUpdate    = "update"
+	actionUnchanged = "unchanged"
+	actionDelete    = "delete"
+)
+
+// plan contains the changes to reach the state of the files
+type plan struct {
+	auth *api.AuthenticationConfig
+	// resources are sorted in the order of creation
+	resources []*resource
+	deletes   []*resource
+	// ids maps the local ids of the files to the ids of the tenant
+	ids map[string]string
+}
+
+// existing is a resource of the tenant
+type existing struct {
+	id    string
+	title string
+	doc   map[string]interface{}
+}
+
+// newPlan compares the resources with the resources of the tenant. With
+// prune all resources of the tenant with a kind of the files which are not
+// part of the files are deleted.
+func newPlan(auth *api.AuthenticationConfig, resources []*resource, prune bool) (*plan, error) {
+	p := &plan{auth: auth, ids: make(map[string]string)}
+	if err := p.sort(resources); err != nil {
+		return nil, err
+	}
+	used := make(map[*kind]bool)
+	for _, r := range resources {
+		used[r.kind] = true
+	}
+	for _, k := range kinds {
+		if !used[k] {
+			continue
+		}
+		current, err := p.list(k)
+		if err != nil {
+			return nil, err
+		}
+		matched := make(map[string]bool)
+		for _, r := range p.resources {
+			if r.kind != k {
+				continue
+			}
+			e, err := p.match(r, current)
+			if err != nil {
+				return nil, err
+			}
+			if e == nil {
+				r.action = actionCreate
+				continue
+			}
+			matched[e.id] = true
+			r.id = e.id
+			if r.local != "" {
+				p.ids[r.local] = e.id
+			}
+			if err := p.compare(r, e); err != nil {
+				return nil, err
+			}
+		}
+		if prune {
+			p.prune(k, current, matched)
+		}
+	}
+	// deletes in the reverse order of creation
+	sort.SliceStable(p.deletes, func(i, j int) bool {
+		return index(p.deletes[i].kind) > index(p.deletes[j].kind)
+	})
+	return p, nil
+}
+
+// index returns the position of the kind in the order of creation
+func index(k *kind) int {
+	for i, other := range kinds {
+		if other == k {
+			return i
+		}
+	}
+	return -1
+}
+
+// sort orders the resources by kind and by the references between resources
+// of the same kind, e.g. data types using other data types
+func (p *plan) sort(resources []*resource) error {
+	byLocal := make(map[string]*resource)
+	for _, r := range resources {
+		if r.local != "" {
+			byLocal[r.local] = r
+		}
+	}
+	state := make(map[*resource]int)
+	var visit func(r *resource) error
+	visit = func(r *resource) error {
+		switch state[r] {
+		case 1:
+			return fmt.Errorf("%s: circular reference of %s", r.file, r.name())
+		case 2:
+			return nil
+		}
+		state[r] = 1
+		refs := make(map[string]bool)
+		collectStrings(r.doc, refs)
+		names := make([]string, 0, len(refs))
+		for ref := range refs {
+			names = append(names, ref)
+		}
+		sort.Strings(names)
+		for _, ref := range names {
+			if other, ok := byLocal[ref]; ok && other != r && other.kind == r.kind {
+				if err := visit(other); err != nil {
+					return err
+				}
+			}
+		}
+		state[r] = 2
+		p.resources = append(p.resources, r)
+		return nil
+	}
+	for _, k := range kinds {
+		for _, r := range resources {
+			if r.kind == k {
+				if err := visit(r); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectStrings adds all string values of the document
+func collectStrings(obj interface{}, result map[string]bool) {
+	switch v := obj.(type) {
+	case string:
+		result[v] = true
+	case map[string]interface{}:
+		for _, value := range v {
+			collectStrings(value, result)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectStrings(value, result)
+		}
+	}
+}
+
+// rewrite replaces the local ids in all string values of the document
+func rewrite(obj interface{}, ids map[string]string) interface{} {
+	switch v := obj.(type) {
+	case string:
+		if id, ok := ids[v]; ok {
+			return id
+		}
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			result[key] = rewrite(value, ids)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, value := range v {
+			result[i] = rewrite(value, ids)
+		}
+		return result
+	}
+	return obj
+}
+
+// payload returns the document with the ids of the tenant and without the
+// read-only fields
+func (p *plan) payload(r *resource) map[string]interface{} {
+	result := rewrite(r.doc, p.ids).(map[string]interface{})
+	for _, f := range readOnly {
+		delete(result, f)
+	}
+	return result
+}
+
+// get requests the URL and decodes the JSON response
+func (p *plan) get(f api.Func, req *api.Request) (interface{}, error) {
+	res, err := api.HandleStatusCode(f(context.Background(), p.auth, req))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var result interface{}
+	if err = json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// list returns the resources of the tenant
+func (p *plan) list(k *kind) ([]*existing, error) {
+	var result []*existing
+	if k == descriptors {
+		items, err := cache.SRDescriptors(p.auth, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			doc, _ := item.Interface().(map[string]interface{})
+			result = append(result, &existing{id: item.StrOrEmpty("@id"), doc: doc})
+		}
+		return result, nil
+	}
+	obj, err := cache.NewSRCall(p.auth, cache.SRResources[k.name], false).Call()
+	if err != nil {
+		return nil, err
+	}
+	util.NewQuery(obj).Path("results").Range(func(q *util.Query) {
+		result = append(result, &existing{id: q.StrOrEmpty("$id"), title: q.StrOrEmpty("title")})
+	})
+	return result, nil
+}
+
+// match returns the resource of the tenant with the same id or title.
+// Descriptors are matched by type, source schema and source property.
+func (p *plan) match(r *resource, current []*existing) (*existing, error) {
+	if r.kind == descriptors {
+		doc := rewrite(r.doc, p.ids).(map[string]interface{})
+		key := descriptorKey(doc)
+		for _, e := range current {
+			if (r.local != "" && e.id == r.local) || descriptorKey(e.doc) == key {
+				return e, nil
+			}
+		}
+		return nil, nil
+	}
+	var candidates []*existing
+	for _, e := range current {
+		if r.local != "" && e.id == r.local {
+			return e, nil
+		}
+		if r.title != "" && e.title == r.title {
+			candidates = append(candidates, e)
+		}
+	}
+	switch len(candidates) {
+	case 0:
+		return nil, nil
+	case 1:
+		return candidates[0], nil
+	}
+	ids := make([]string, len(candidates))
+	for i, e := range candidates {
+		ids[i] = e.id
+	}
+	return nil, fmt.Errorf("%s: the title %q is ambiguous, set $id to one of the ids:\n  %s", r.file, r.title, strings.Join(ids, "\n  "))
+}
+
+// descriptorKey identifies a descriptor by type, source schema and source
+// property
+func descriptorKey(doc map[string]interface{}) string {
+	q := util.NewQuery(doc)
+	return q.StrOrEmpty("@type") + " " + q.StrOrEmpty("xdm:sourceSchema") + " " + q.StrOrEmpty("xdm:sourceProperty")
+}
+
+// compare sets the action and the patch of an existing resource. Only the
+// fields of the file are compared, fields added by the Schema Registry are
+// ignored.
+func (p *plan) compare(r *resource, e *existing) error {
+	desired := p.payload(r)
+	current := e.doc
+	if r.kind != descriptors {
+		req := api.NewRequestValues("cid", "tenant", "id", e.id)
+		req.Accept("application/vnd.adobe.xed+json; version=1")
+		obj, err := p.get(r.kind.get, req)
+		if err != nil {
+			return err
+		}
+		current, _ = obj.(map[string]interface{})
+	}
+	filtered := make(map[string]interface{}, len(desired))
+	for key := range desired {
+		if value, ok := current[key]; ok {
+			filtered[key] = value
+		}
+	}
+	r.current = filtered
+	r.patch = util.Diff(filtered, desired)
+	if len(r.patch) == 0 {
+		r.action = actionUnchanged
+	} else {
+		r.action = actionUpdate
+	}
+	return nil
+}
+
+// prune adds the resources of the tenant which are not part of the files.
+// Only descriptors of managed schemas are deleted.
+func (p *plan) prune(k *kind, current []*existing, matched map[string]bool) {
+	managed := make(map[string]bool)
+	for _, r := range p.resources {
+		if r.kind == schemas && r.id != "" {
+			managed[r.id] = true
+		}
+	}
+	for _, e := range current {
+		if matched[e.id] {
+			continue
+		}
+		if k == descriptors && !managed[util.NewQuery(e.doc).StrOrEmpty("xdm:sourceSchema")] {
+			continue
+		}
+		p.deletes = append(p.deletes, &resource{kind: k, id: e.id, title: e.title, action: actionDelete})
+	}
+}
+
+// counts returns the number of resources per action
+func (p *plan) counts() map[string]int {
+	result := make(map[string]int)
+	for _, r := range p.resources {
+		result[r.action]++
+	}
+	result[actionDelete] = len(p.deletes)
+	return result
+}
+
+// Write prints the plan
+func (p *plan) Write(w io.Writer) error {
+	var sb strings.Builder
+	symbols := map[string]string{actionCreate: "+", actionUpdate: "~", actionUnchanged: " ", actionDelete: "-"}
+	for _, r := range append(append([]*resource{}, p.resources...), p.deletes...) {
+		fmt.Fprintf(&sb, "%s %-9s %-10s %s", symbols[r.action], r.action, r.kind.single, r.name())
+		if r.id != "" && r.id != r.name() {
+			fmt.Fprintf(&sb, " (%s)", r.id)
+		}
+		if r.action == actionUpdate {
+			if len(r.patch) == 1 {
+				sb.WriteString(", 1 change")
+			} else {
+				fmt.Fprintf(&sb, ", %d changes", len(r.patch))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	c := p.counts()
+	fmt.Fprintf(&sb, "Plan: %d to create, %d to update, %d to delete, %d unchanged\n",
+		c[actionCreate], c[actionUpdate], c[actionDelete], c[actionUnchanged])
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// Apply executes the plan. Created resources replace the local ids in the
+// following resources.
+func (p *plan) Apply(w io.Writer) error {
+	ctx := context.Background()
+	for _, r := range p.resources {
+		switch r.action {
+		case actionCreate:
+			data, err := json.Marshal(p.payload(r))
+			if err != nil {
+				return err
+			}
+			q, err := api.NewQuery(r.kind.create(ctx, p.auth, data))
+			if err != nil {
+				return fmt.Errorf("%s: %v", r.file, err)
+			}
+			r.id = q.StrOrEmpty("$id")
+			if r.kind == descriptors {
+				r.id = q.StrOrEmpty("@id")
+			}
+			if r.local != "" {
+				p.ids[r.local] = r.id
+			}
+		case actionUpdate:
+			var err error
+			if r.kind == descriptors {
+				doc := p.payload(r)
+				doc["@id"] = r.id
+				var data []byte
+				if data, err = json.Marshal(doc); err == nil {
+					err = api.DropResponse(r.kind.update(ctx, p.auth, r.id, data))
+				}
+			} else {
+				// the ids of resources created before replace the local ids
+				patch := util.Diff(r.current, p.payload(r))
+				if len(patch) == 0 {
+					continue
+				}
+				var data []byte
+				if data, err = json.Marshal(patch); err == nil {
+					err = api.DropResponse(r.kind.patch(ctx, p.auth, r.id, data))
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("%s: %v", r.file, err)
+			}
+		default:
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "%s %s %s (%s)\n", r.kind.single, r.name(), r.action+"d", r.id); err != nil {
+			return err
+		}
+	}
+	for _, r := range p.deletes {
+		if err := api.DropResponse(r.kind.remove(ctx, p.auth, r.id)); err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s %s deleted\n", r.kind.single, r.name()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tenantID returns the tenant id of the organization
+func tenantID(auth *api.AuthenticationConfig) (string, error) {
+	res, err := api.HandleStatusCode(api.SRGetStatsP(context.Background(), auth))
+	if err != nil {
+		return "", err
+	}
+	defer res.Body.Close()
+	var stats struct {
+		TenantID string `json:"tenantId"`
+	}
+	if err = json.NewDecoder(res.Body).Decode(&stats); err != nil {
+		return "", err
+	}
+	if stats.TenantID == "" {
+		return "", fmt.Errorf("the tenant id is not available")
+	}
+	return stats.TenantID, nil
+}
@@ -0,0 +1,235 @@
+/*
+Package apply contains apply command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/util"
+)
+
+// tenantPlaceholder is replaced by the tenant id of the organization, e.g.
+// https://ns.adobe.com/{TENANT_ID}/mixins/loyalty or _{TENANT_ID}
+const tenantPlaceholder = "{TENANT_ID}"
+
+// kind is a resource kind of the Schema Registry
+type kind struct {
+	name   string
+	single string
+	create api.FuncPost
+	patch  api.FuncPostID
+	update api.FuncPostID
+	remove api.FuncID
+	get    api.Func
+	// types are the values of meta:resourceType
+	types []string
+}
+
+// the kinds in the order of creation
+var (
+	classes = &kind{
+		name: "classes", single: "class",
+		create: api.SRCreateClass, patch: api.SRPatchClass, remove: api.SRDeleteClass,
+		get: api.SRGetClassP, types: []string{"classes"},
+	}
+	datatypes = &kind{
+		name: "datatypes", single: "datatype",
+		create: api.SRCreateDataType, patch: api.SRPatchDataType, remove: api.SRDeleteDataType,
+		get: api.SRGetDataTypeP, types: []string{"datatypes"},
+	}
+	fieldgroups = &kind{
+		name: "fieldgroups", single: "fieldgroup",
+		create: api.SRCreateFieldGroup, patch: api.SRPatchFieldGroup, remove: api.SRDeleteFieldGroup,
+		get: api.SRGetFieldGroupP, types: []string{"fieldgroups", "mixins"},
+	}
+	schemas = &kind{
+		name: "schemas", single: "schema",
+		create: api.SRCreateSchema, patch: api.SRPatchSchema, remove: api.SRDeleteSchema,
+		get: api.SRGetSchemaP, types: []string{"schemas"},
+	}
+	descriptors = &kind{
+		name: "descriptors", single: "descriptor",
+		create: api.SRCreateDescriptor, update: api.SRUpdateDescriptor, remove: api.SRDeleteDescriptor,
+		types: []string{"descriptors"},
+	}
+	kinds = []*kind{classes, datatypes, fieldgroups, schemas, descriptors}
+)
+
+// readOnly contains the fields managed by the Schema Registry
+var readOnly = []string{
+	"$id",
+	"@id",
+	"_links",
+	"meta:altId",
+	"meta:containerId",
+	"meta:registryMetadata",
+	"meta:resourceType",
+	"meta:sandboxId",
+	"meta:sandboxType",
+	"meta:tenantNamespace",
+	"version",
+}
+
+// resource is a Schema Registry resource of a file
+type resource struct {
+	kind *kind
+	file string
+	doc  map[string]interface{}
+	// local is the $id in the file
+	local string
+	// id is the $id or @id of the existing or created resource
+	id     string
+	title  string
+	action string
+	// patch is the difference of the plan, current holds the compared fields
+	// of the existing resource
+	patch   util.JSONPatch
+	current map[string]interface{}
+}
+
+// name returns the title or the id of the resource
+func (r *resource) name() string {
+	if r.title != "" {
+		return r.title
+	}
+	if r.id != "" {
+		return r.id
+	}
+	return r.local
+}
+
+// files returns the JSON and YAML files of the passed paths, directories are
+// read recursively
+func files(paths []string) ([]string, error) {
+	var result []string
+	for _, path := range paths {
+		err := filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if info.IsDir() {
+				return nil
+			}
+			switch strings.ToLower(filepath.Ext(p)) {
+			case ".json", ".yaml", ".yml":
+				result = append(result, p)
+			default:
+				if p == path {
+					return fmt.Errorf("%s is neither a JSON nor a YAML file", p)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// read returns the resources of the files. JSON arrays, e.g. the output of
+// aepctl export, contain several resources. The tenant placeholder is
+// replaced by the result of tenant.
+func read(paths []string, tenant func() (string, error)) ([]*resource, error) {
+	var result []*resource
+	for _, path := range paths {
+		fr := &util.MultiFileReader{Files: []string{path}}
+		err := fr.ReadAll(func(data []byte) error {
+			if bytes.Contains(data, []byte(tenantPlaceholder)) {
+				id, err := tenant()
+				if err != nil {
+					return err
+				}
+				data = bytes.ReplaceAll(data, []byte(tenantPlaceholder), []byte(id))
+			}
+			var obj interface{}
+			if err := json.Unmarshal(data, &obj); err != nil {
+				return err
+			}
+			docs, ok := obj.([]interface{})
+			if !ok {
+				docs = []interface{}{obj}
+			}
+			for _, doc := range docs {
+				m, ok := doc.(map[string]interface{})
+				if !ok {
+					return fmt.Errorf("%s: the resource is not a JSON object", path)
+				}
+				k, err := detect(m)
+				if err != nil {
+					return fmt.Errorf("%s: %v", path, err)
+				}
+				q := util.NewQuery(m)
+				r := &resource{kind: k, file: path, doc: m, local: q.StrOrEmpty("$id"), title: q.StrOrEmpty("title")}
+				if k == descriptors {
+					r.local = q.StrOrEmpty("@id")
+					r.title = strings.TrimSpace(q.StrOrEmpty("@type") + " " + q.StrOrEmpty("xdm:sourceProperty"))
+				}
+				result = append(result, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// detect returns the kind of the resource by meta:resourceType, the type of a
+// descriptor, the $id or the structure
+func detect(doc map[string]interface{}) (*kind, error) {
+	q := util.NewQuery(doc)
+	if t := q.StrOrEmpty("meta:resourceType"); t != "" {
+		for _, k := range kinds {
+			if util.Contains(t, k.types) {
+				return k, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported resource type %s", t)
+	}
+	if strings.HasPrefix(q.StrOrEmpty("@type"), "xdm:descriptor") {
+		return descriptors, nil
+	}
+	id := q.StrOrEmpty("$id")
+	for _, k := range kinds {
+		for _, t := range k.types {
+			if strings.Contains(id, "/"+t+"/") {
+				return k, nil
+			}
+		}
+	}
+	switch {
+	case !q.Path("meta:class").Nil():
+		return schemas, nil
+	case !q.Path("meta:intendedToExtend").Nil():
+		return fieldgroups, nil
+	case !q.Path("meta:extends").Nil() || !q.Path("allOf").Nil():
+		return classes, nil
+	case !q.Path("properties").Nil():
+		return datatypes, nil
+	}
+	return nil, fmt.Errorf("cannot detect the resource kind of %q, set meta:resourceType", q.StrOrEmpty("title"))
+}
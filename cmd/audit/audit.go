@@ -29,7 +29,7 @@ import (
 var auditTransformation string
 
 func NewCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "audit resource_id",
 		Short:                 "get audit log for schema registry resource",
@@ -37,13 +37,17 @@ func NewCommand(conf *helper.Configuration) *cobra.Command {
 		Example:               "example",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactValidArgs(1),
+		ValidArgsFunction:     helper.ValidSRTitle(conf, nil),
 		Run: func(cmd *cobra.Command, args []string) {
 			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
 			helper.CheckErr(output.SetTransformationDesc(auditTransformation))
-			helper.CheckErr(output.PrintResponse(api.SRGetAuditLog(context.Background(), conf.Authentication, args[0])))
+			id, err := helper.ResolveSRTitle(conf, false, args[0])
+			helper.CheckErr(err)
+			helper.CheckErr(output.PrintResponse(api.SRGetAuditLog(context.Background(), conf.Authentication, id)))
 		},
 	}
 	conf.AddAuthenticationFlags(cmd)
 	output.AddOutputFlags(cmd)
+	output.AddTransformation("", auditTransformation)
 	return cmd
 }
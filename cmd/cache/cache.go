@@ -0,0 +1,477 @@
+/*
+Package cache contains cache command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package cache
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/api/od"
+	caches "github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+//go:embed trans/ls.yaml
+var lsTransformation string
+
+//go:embed trans/show.yaml
+var showTransformation string
+
+var (
+	longDesc = util.LongDesc(`
+	Manage the file caches of aepctl.
+
+	The caches are stored in ~/.aepctl/cache/CLIENT_ID and contain the
+	sandboxes, the Offer Decisioning containers, the name to id maps of the
+	Offer Decisioning objects, the title to id maps of the Schema Registry
+	resources, the resolved schemas of aepctl validate and the access token.
+	Caches depending on the sandbox are stored in a sub directory with the
+	name of the sandbox. The global flag --no-cache disables reading and
+	writing of all caches.`)
+	example = util.Example(`
+	aepctl cache ls
+	aepctl cache show offer_n2id
+	aepctl cache clear --sandbox dev
+	aepctl cache refresh`)
+)
+
+// odSchemas maps the names of the Offer Decisioning caches to schemas
+var odSchemas = map[string]string{
+	"activity":   od.ActivitySchema,
+	"collection": od.CollectionSchema,
+	"fallback":   od.FallbackSchema,
+	"offer":      od.OfferSchema,
+	"placement":  od.PlacementSchema,
+	"rule":       od.RuleSchema,
+	"tag":        od.TagSchema,
+}
+
+// entry is a cache file
+type entry struct {
+	Name    string     `json:"name"`
+	Sandbox string     `json:"sandbox"`
+	Type    string     `json:"type"`
+	Entries int        `json:"entries"`
+	Size    int64      `json:"size"`
+	Expires *time.Time `json:"expires,omitempty"`
+	Status  string     `json:"status"`
+	File    string     `json:"file"`
+	// content is not part of the listing
+	content map[string]string
+	list    []string
+	id      string
+}
+
+// content is the common structure of EatByMap, EatByList, EatByDoc and the
+// token
+type content struct {
+	Map     map[string]string
+	List    []string
+	ID      string
+	Doc     interface{}
+	Token   string
+	Expires time.Time
+}
+
+// readEntry reads the cache file
+func readEntry(root, path string) (*entry, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return nil, err
+	}
+	e := &entry{
+		Name:    strings.TrimSuffix(filepath.Base(path), ".json"),
+		Sandbox: filepath.ToSlash(filepath.Dir(rel)),
+		Size:    fi.Size(),
+		File:    path,
+		Status:  "invalid",
+		Type:    "-",
+	}
+	if e.Sandbox == "." {
+		e.Sandbox = "-"
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c content
+	if json.Unmarshal(data, &c) != nil {
+		return e, nil
+	}
+	switch {
+	case c.Map != nil:
+		e.Type, e.Entries, e.content = "map", len(c.Map), c.Map
+	case c.List != nil:
+		e.Type, e.Entries, e.list = "list", len(c.List), c.List
+	case c.Doc != nil:
+		e.Type, e.Entries, e.id = "document", 1, c.ID
+	case c.Token != "":
+		e.Type, e.Entries = "token", 1
+	default:
+		return e, nil
+	}
+	e.Expires = &c.Expires
+	if time.Now().Before(c.Expires) {
+		e.Status = "valid"
+	} else {
+		e.Status = "expired"
+	}
+	return e, nil
+}
+
+// listEntries returns all cache files of the client sorted by sandbox and
+// name
+func listEntries(root string) ([]*entry, error) {
+	result := []*entry{}
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == root {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if info.IsDir() && info.Name() == "store" {
+			// the local store of aepctl sync is not a cache
+			return filepath.SkipDir
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		e, err := readEntry(root, path)
+		if err != nil {
+			return err
+		}
+		result = append(result, e)
+		return nil
+	})
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Sandbox != result[j].Sandbox {
+			return result[i].Sandbox < result[j].Sandbox
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result, err
+}
+
+// resolve returns the file of the named cache. The name is either the path
+// relative to the cache directory, e.g. prod/offer_n2id, or the name of a
+// cache of the current sandbox or of a shared cache, e.g. offer_n2id or
+// sandboxes.
+func resolve(conf *helper.Configuration, name string) (string, error) {
+	name = strings.TrimSuffix(name, ".json")
+	var candidates []string
+	if strings.Contains(name, "/") {
+		candidates = []string{conf.Path(filepath.FromSlash(name) + ".json")}
+	} else {
+		candidates = []string{
+			conf.UniqueSandboxPath(name + ".json"),
+			conf.Path(name + ".json"),
+		}
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c, nil
+		}
+	}
+	return "", fmt.Errorf("unknown cache %s, see aepctl cache ls", name)
+}
+
+// print writes the document with the output configuration
+func print(output *helper.OutputConf, doc interface{}) error {
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return output.Print(ioutil.NopCloser(bytes.NewReader(data)))
+}
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "cache",
+		Short:                 "Manage the file caches",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+	}
+	conf.AddAuthenticationFlags(cmd)
+	cmd.AddCommand(NewListCommand(conf))
+	cmd.AddCommand(NewShowCommand(conf))
+	cmd.AddCommand(NewClearCommand(conf))
+	cmd.AddCommand(NewRefreshCommand(conf))
+	return cmd
+}
+
+// validNames returns the names of the existing caches
+func validNames(conf *helper.Configuration) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err := conf.Update(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		entries, _ := listEntries(conf.Path())
+		names := make([]string, len(entries))
+		for i, e := range entries {
+			if e.Sandbox == "-" {
+				names[i] = e.Name
+			} else {
+				names[i] = e.Sandbox + "/" + e.Name
+			}
+		}
+		return util.Difference(names, args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// NewListCommand creates an initialized command object
+func NewListCommand(conf *helper.Configuration) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
+	cmd := &cobra.Command{
+		Use:                   "ls",
+		Aliases:               []string{"list"},
+		Short:                 "List the cache files with entries, size and expiry date",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErrs(conf.Update(cmd), output.ValidateFlags())
+			helper.CheckErr(output.SetTransformationDesc(lsTransformation))
+			entries, err := listEntries(conf.Path())
+			helper.CheckErr(err)
+			helper.CheckErr(print(output, map[string]interface{}{"items": entries}))
+		},
+	}
+	output.AddOutputFlags(cmd)
+	output.AddTransformation("", lsTransformation)
+	return cmd
+}
+
+// NewShowCommand creates an initialized command object
+func NewShowCommand(conf *helper.Configuration) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
+	cmd := &cobra.Command{
+		Use:                   "show NAME",
+		Short:                 "Display the entries of a cache",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgsFunction:     validNames(conf),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErrs(conf.Update(cmd), output.ValidateFlags())
+			helper.CheckErr(output.SetTransformationDesc(showTransformation))
+			file, err := resolve(conf, args[0])
+			helper.CheckErr(err)
+			e, err := readEntry(conf.Path(), file)
+			helper.CheckErr(err)
+			type kv struct {
+				Key   string `json:"key"`
+				Value string `json:"value,omitempty"`
+			}
+			entries := make([]kv, 0, e.Entries)
+			switch e.Type {
+			case "map":
+				for k, v := range e.content {
+					entries = append(entries, kv{Key: k, Value: v})
+				}
+				sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+			case "list":
+				for _, v := range e.list {
+					entries = append(entries, kv{Key: v})
+				}
+			case "token":
+				// the token is a secret
+				entries = append(entries, kv{Key: "expires", Value: e.Expires.Format(time.RFC3339)})
+			case "document":
+				entries = append(entries, kv{Key: "id", Value: e.id})
+			default:
+				helper.CheckErr(fmt.Errorf("%s is not a valid cache file", file))
+			}
+			helper.CheckErr(print(output, map[string]interface{}{
+				"name":    e.Name,
+				"sandbox": e.Sandbox,
+				"expires": e.Expires,
+				"entries": entries,
+			}))
+		},
+	}
+	output.AddOutputFlags(cmd)
+	output.AddTransformation("", showTransformation)
+	return cmd
+}
+
+// NewClearCommand creates an initialized command object
+func NewClearCommand(conf *helper.Configuration) *cobra.Command {
+	var all bool
+	cmd := &cobra.Command{
+		Use:   "clear [NAME...]",
+		Short: "Remove caches",
+		Long: util.LongDesc(`
+		Remove caches.
+
+		Without arguments all caches except the access token are removed.
+		--sandbox removes only the caches of the passed sandbox and --all
+		removes all caches including the access token.`),
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validNames(conf),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Update(cmd))
+			sandbox := cmd.Flags().Changed("sandbox")
+			if len(args) > 0 && (all || sandbox) {
+				helper.CheckErr(errors.New("names cannot be combined with --all or --sandbox"))
+			}
+			var files []string
+			if len(args) > 0 {
+				for _, name := range args {
+					file, err := resolve(conf, name)
+					helper.CheckErr(err)
+					files = append(files, file)
+				}
+			} else {
+				root := conf.Path()
+				if sandbox {
+					root = conf.UniqueSandboxPath()
+				}
+				entries, err := listEntries(root)
+				helper.CheckErr(err)
+				for _, e := range entries {
+					if all || e.Type != "token" {
+						files = append(files, e.File)
+					}
+				}
+			}
+			for _, file := range files {
+				helper.CheckErr(os.Remove(file))
+				_ = os.Remove(file + ".refresh")
+				_ = os.Remove(file + ".lock")
+			}
+			fmt.Printf("Removed %d cache files\n", len(files))
+		},
+	}
+	cmd.Flags().BoolVar(&all, "all", false, "remove all caches including the access token")
+	return cmd
+}
+
+// refresher is implemented by MapFileCache, ListFileCache and DocFileCache
+type refresher interface {
+	Refresh() error
+}
+
+// newCache returns the cache of the file
+func newCache(conf *helper.Configuration, ac *caches.AutoContainer, e *entry) refresher {
+	if e.Sandbox == "-" {
+		switch e.Name {
+		case "sandboxes":
+			return caches.NewSandboxCache(conf.Authentication, conf)
+		case "container":
+			return caches.NewContainerCache(conf.Authentication, conf)
+		}
+		return nil
+	}
+	if e.Sandbox != conf.Authentication.Sandbox {
+		return nil
+	}
+	if strings.HasSuffix(e.Name, "_t2id") {
+		name := strings.TrimSuffix(e.Name, "_t2id")
+		global := strings.HasPrefix(name, "global_")
+		name = strings.TrimPrefix(name, "global_")
+		if _, ok := caches.SRResources[name]; ok {
+			return caches.NewSRTitleToID(conf.Authentication, name, global, conf.Sandboxed())
+		}
+		return nil
+	}
+	if name := strings.TrimPrefix(e.Name, "global_"); strings.HasPrefix(name, caches.SRSchemaPrefix) && e.id != "" {
+		return caches.NewSRSchema(conf.Authentication, e.id, name != e.Name, conf.Sandboxed())
+	}
+	for suffix, f := range map[string]func(*caches.AutoContainer, string, string, util.PathProvider) *caches.MapFileCache{
+		"_n2id":  caches.NewODNameToID,
+		"_n2iid": caches.NewODNameToInstanceID,
+	} {
+		if strings.HasSuffix(e.Name, suffix) {
+			name := strings.TrimSuffix(e.Name, suffix)
+			for singular, schema := range odSchemas {
+				if name == singular || name == util.Plural(singular) {
+					return f(ac, name, schema, conf.Sandboxed())
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// NewRefreshCommand creates an initialized command object
+func NewRefreshCommand(conf *helper.Configuration) *cobra.Command {
+	ac := caches.NewAutoContainer(conf.Authentication, conf)
+	cmd := &cobra.Command{
+		Use:   "refresh [NAME...]",
+		Short: "Reload caches from the services",
+		Long: util.LongDesc(`
+		Reload caches from the services.
+
+		Without arguments all existing caches of the current sandbox and the
+		shared caches are reloaded. The access token is renewed on expiry and
+		cannot be refreshed.`),
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction:     validNames(conf),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Validate(cmd))
+			var entries []*entry
+			if len(args) > 0 {
+				for _, name := range args {
+					file, err := resolve(conf, name)
+					helper.CheckErr(err)
+					e, err := readEntry(conf.Path(), file)
+					helper.CheckErr(err)
+					entries = append(entries, e)
+				}
+			} else {
+				all, err := listEntries(conf.Path())
+				helper.CheckErr(err)
+				for _, e := range all {
+					if e.Sandbox == "-" || e.Sandbox == conf.Authentication.Sandbox {
+						entries = append(entries, e)
+					}
+				}
+			}
+			for _, e := range entries {
+				c := newCache(conf, ac, e)
+				if c == nil {
+					if len(args) > 0 {
+						helper.CheckErr(fmt.Errorf("cache %s cannot be refreshed", e.Name))
+					}
+					continue
+				}
+				helper.CheckErr(c.Refresh())
+				// remove the marker of the background refresh
+				_ = os.Remove(e.File + ".refresh")
+				fmt.Printf("Refreshed %s\n", e.Name)
+			}
+		},
+	}
+	helper.CheckErr(ac.AddContainerFlag(cmd))
+	return cmd
+}
@@ -26,17 +26,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func prepareActivity(ac *cache.AutoContainer, activity *od.Activity) {
-	ps := cache.NewODNameToIDMem(ac, od.PlacementSchema)
-	cs := cache.NewODNameToIDMem(ac, od.CollectionSchema)
-	fs := cache.NewODNameToIDMem(ac, od.FallbackSchema)
+func prepareActivity(res *cache.ODResolver, activity *od.Activity) {
 	for _, c := range activity.Criteria {
 		for i, p := range c.Placements {
-			c.Placements[i] = ps.Lookup(p)
+			c.Placements[i] = res.Resolve(od.PlacementSchema, p)
 		}
-		c.Selection.Filter = cs.Lookup(c.Selection.Filter)
+		c.Selection.Filter = res.Resolve(od.CollectionSchema, c.Selection.Filter)
 	}
-	activity.Fallback = fs.Lookup(activity.Fallback)
+	activity.Fallback = res.Resolve(od.FallbackSchema, activity.Fallback)
 }
 
 var (
@@ -96,19 +93,26 @@ func NewCreateActivityCommand(conf *helper.Configuration, ac *cache.AutoContaine
 			i, err := fc.Open()
 			helper.CheckErr(err)
 			if i != nil {
+				// resolve the names of all documents before creating anything
+				res := cache.NewODResolver(ac)
+				var activities []*od.Activity
 				for {
 					activity := &od.Activity{}
 					if err := i.Load(activity); err == nil {
 						if fc.IsYAML() {
-							prepareActivity(ac, activity)
+							prepareActivity(res, activity)
 						}
-						_, err = od.Create(context.Background(), conf.Authentication, cid, od.ActivitySchema, activity)
-						helper.CheckErr(err)
+						activities = append(activities, activity)
 					} else {
 						helper.CheckErrEOF(err)
 						break
 					}
 				}
+				helper.CheckErr(res.Err())
+				for _, activity := range activities {
+					_, err = od.Create(context.Background(), conf.Authentication, cid, od.ActivitySchema, activity)
+					helper.CheckErr(err)
+				}
 			}
 		},
 	}
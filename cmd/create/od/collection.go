@@ -27,7 +27,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func prepareCollection(ac *cache.AutoContainer, collection *od.Collection) {
+func prepareCollection(res *cache.ODResolver, collection *od.Collection) {
 	schema := od.OfferSchema
 	if collection.Filter == "" {
 		collection.Filter = "offers"
@@ -39,9 +39,8 @@ func prepareCollection(ac *cache.AutoContainer, collection *od.Collection) {
 		}
 	}
 
-	store := cache.NewODNameToIDMem(ac, schema)
 	for i, o := range collection.IDs {
-		collection.IDs[i] = store.Lookup(o)
+		collection.IDs[i] = res.Resolve(schema, o)
 	}
 }
 
@@ -91,26 +90,35 @@ func NewCreateCollectionCommand(conf *helper.Configuration, ac *cache.AutoContai
 					Filter: filter,
 					IDs:    args[2:],
 				}
-				prepareCollection(ac, collection)
+				res := cache.NewODResolver(ac)
+				prepareCollection(res, collection)
+				helper.CheckErr(res.Err())
 				_, err := od.Create(context.Background(), conf.Authentication, cid, od.CollectionSchema, collection)
 				helper.CheckErr(err)
 			}
 			i, err := fc.Open()
 			helper.CheckErr(err)
 			if i != nil {
+				// resolve the names of all documents before creating anything
+				res := cache.NewODResolver(ac)
+				var collections []*od.Collection
 				for {
 					collection := &od.Collection{}
 					if err := i.Load(collection); err == nil {
 						if fc.IsYAML() {
-							prepareCollection(ac, collection)
+							prepareCollection(res, collection)
 						}
-						_, err = od.Create(context.Background(), conf.Authentication, cid, od.CollectionSchema, collection)
-						helper.CheckErr(err)
+						collections = append(collections, collection)
 					} else {
 						helper.CheckErrEOF(err)
 						break
 					}
 				}
+				helper.CheckErr(res.Err())
+				for _, collection := range collections {
+					_, err = od.Create(context.Background(), conf.Authentication, cid, od.CollectionSchema, collection)
+					helper.CheckErr(err)
+				}
 			}
 			return nil
 		},
@@ -25,17 +25,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func prepareFallback(ac *cache.AutoContainer, fallback *od.Fallback) {
-	call := cache.NewODCall(ac, od.PlacementSchema)
-	ps := cache.NewMapMemCache(call, cache.ODNameToID())
-	cs := cache.NewMapMemCache(call, cache.NewODTrans().K("_instance", "@id").V("_instance", "xdm:channel"))
-
-	ts := cache.NewODNameToIDMem(ac, od.TagSchema)
+func prepareFallback(res *cache.ODResolver, fallback *od.Fallback) {
+	cs := res.Map(od.PlacementSchema, cache.NewODTrans().K("_instance", "@id").V("_instance", "xdm:channel"))
 	for _, r := range fallback.Representations {
 		for _, c := range r.Components {
 			c.Type = helper.ContentSToL.GetL(c.Type)
 		}
-		r.Placement = ps.Lookup(r.Placement)
+		r.Placement = res.Resolve(od.PlacementSchema, r.Placement)
 		if r.Channel == "" {
 			r.Channel = cs.Lookup(r.Placement)
 		} else {
@@ -43,7 +39,7 @@ func prepareFallback(ac *cache.AutoContainer, fallback *od.Fallback) {
 		}
 	}
 	for i, t := range fallback.Tags {
-		fallback.Tags[i] = ts.Lookup(t)
+		fallback.Tags[i] = res.Resolve(od.TagSchema, t)
 	}
 }
 
@@ -61,19 +57,26 @@ func NewCreateFallbackCommand(conf *helper.Configuration, ac *cache.AutoContaine
 			i, err := fc.Open()
 			helper.CheckErr(err)
 			if i != nil {
+				// resolve the names of all documents before creating anything
+				res := cache.NewODResolver(ac)
+				var fallbacks []*od.Fallback
 				for {
 					fallback := &od.Fallback{}
 					if err := i.Load(fallback); err == nil {
 						if fc.IsYAML() {
-							prepareFallback(ac, fallback)
+							prepareFallback(res, fallback)
 						}
-						_, err = od.Create(context.Background(), conf.Authentication, cid, od.FallbackSchema, fallback)
-						helper.CheckErr(err)
+						fallbacks = append(fallbacks, fallback)
 					} else {
 						helper.CheckErrEOF(err)
 						break
 					}
 				}
+				helper.CheckErr(res.Err())
+				for _, fallback := range fallbacks {
+					_, err = od.Create(context.Background(), conf.Authentication, cid, od.FallbackSchema, fallback)
+					helper.CheckErr(err)
+				}
 			}
 		},
 	}
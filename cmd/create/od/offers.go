@@ -25,8 +25,7 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func prepareOffer(ac *cache.AutoContainer, offer *od.Offer) {
-	ps := cache.NewODNameToIDMem(ac, od.PlacementSchema)
+func prepareOffer(res *cache.ODResolver, offer *od.Offer) {
 	for _, r := range offer.Representations {
 		for _, c := range r.Components {
 			c.Type = helper.ContentSToL.GetL(c.Type)
@@ -34,15 +33,13 @@ func prepareOffer(ac *cache.AutoContainer, offer *od.Offer) {
 		if r.Channel != "" {
 			r.Channel = helper.ChannelSToL.GetL(r.Channel)
 		}
-		r.Placement = ps.Lookup(r.Placement)
+		r.Placement = res.Resolve(od.PlacementSchema, r.Placement)
 	}
 	//rules
-	rs := cache.NewODNameToIDMem(ac, od.RuleSchema)
-	offer.Constraint.Rule = rs.Lookup(offer.Constraint.Rule)
+	offer.Constraint.Rule = res.Resolve(od.RuleSchema, offer.Constraint.Rule)
 	// tags
-	ts := cache.NewODNameToIDMem(ac, od.TagSchema)
 	for i, t := range offer.Tags {
-		offer.Tags[i] = ts.Lookup(t)
+		offer.Tags[i] = res.Resolve(od.TagSchema, t)
 	}
 }
 
@@ -60,19 +57,26 @@ func NewCreateOfferCommand(conf *helper.Configuration, ac *cache.AutoContainer)
 			i, err := fc.Open()
 			helper.CheckErr(err)
 			if i != nil {
+				// resolve the names of all documents before creating anything
+				res := cache.NewODResolver(ac)
+				var offers []*od.Offer
 				for {
 					offer := &od.Offer{}
 					if err := i.Load(offer); err == nil {
 						if fc.IsYAML() {
-							prepareOffer(ac, offer)
+							prepareOffer(res, offer)
 						}
-						_, err = od.Create(context.Background(), conf.Authentication, cid, od.OfferSchema, offer)
-						helper.CheckErr(err)
+						offers = append(offers, offer)
 					} else {
 						helper.CheckErrEOF(err)
 						break
 					}
 				}
+				helper.CheckErr(res.Err())
+				for _, offer := range offers {
+					_, err = od.Create(context.Background(), conf.Authentication, cid, od.OfferSchema, offer)
+					helper.CheckErr(err)
+				}
 			}
 		},
 	}
@@ -17,6 +17,9 @@ specific language governing permissions and limitations under the License.
 package delete
 
 import (
+	"context"
+	"net/http"
+
 	"github.com/fuxs/aepctl/api"
 	"github.com/fuxs/aepctl/cmd/helper"
 	"github.com/spf13/cobra"
@@ -24,8 +27,9 @@ import (
 
 // NewDeleteActivitiesCommand creates an initialized command object
 func NewDeleteClassCommand(conf *helper.Configuration) *cobra.Command {
-	return NewDeleteCommand(conf,
+	return newSRDeleteCommand(conf,
 		api.SRDeleteClass,
+		"classes",
 		"class",
 		"Delete a class (Schema Registry)",
 		"long",
@@ -35,8 +39,9 @@ func NewDeleteClassCommand(conf *helper.Configuration) *cobra.Command {
 
 // NewDeleteDataTypeCommand creates an initialized command object
 func NewDeleteDataTypeCommand(conf *helper.Configuration) *cobra.Command {
-	return NewDeleteCommand(conf,
+	return newSRDeleteCommand(conf,
 		api.SRDeleteDataType,
+		"datatypes",
 		"datatype",
 		"Delete a data type (Schema Registry)",
 		"long",
@@ -59,8 +64,9 @@ func NewDeleteDescriptorCommand(conf *helper.Configuration) *cobra.Command {
 
 // NewDeleteActivitiesCommand creates an initialized command object
 func NewDeleteFieldGroupCommand(conf *helper.Configuration) *cobra.Command {
-	return NewDeleteCommand(conf,
+	return newSRDeleteCommand(conf,
 		api.SRDeleteFieldGroup,
+		"fieldgroups",
 		"fieldgroup",
 		"Delete a field group (Schema Registry)",
 		"long",
@@ -72,11 +78,27 @@ func NewDeleteFieldGroupCommand(conf *helper.Configuration) *cobra.Command {
 
 // NewDeleteActivitiesCommand creates an initialized command object
 func NewDeleteSchemaCommand(conf *helper.Configuration) *cobra.Command {
-	return NewDeleteCommand(conf,
+	return newSRDeleteCommand(conf,
 		api.SRDeleteSchema,
+		"schemas",
 		"schema",
 		"Delete a schema (Schema Registry)",
 		"long",
 		"example",
 		"schemas")
 }
+
+// newSRDeleteCommand creates an initialized command object accepting the
+// titles of the Schema Registry resources with the passed name, e.g. schemas
+func newSRDeleteCommand(conf *helper.Configuration, f api.FuncID, name, use, short, long, example string, aliases ...string) *cobra.Command {
+	resolve := func(ctx context.Context, auth *api.AuthenticationConfig, title string) (*http.Response, error) {
+		id, err := helper.ResolveSRTitle(conf, false, title, name)
+		if err != nil {
+			return nil, err
+		}
+		return f(ctx, auth, id)
+	}
+	cmd := NewDeleteCommand(conf, resolve, use, short, long, example, aliases...)
+	cmd.ValidArgsFunction = helper.ValidSRTitles(conf, nil, name)
+	return cmd
+}
@@ -0,0 +1,353 @@
+/*
+Package diff contains diff command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Display the differences between two resources.
+
+	A source is a local JSON or YAML file, - for standard in, or a resource
+	reference RESOURCE/ID. RESOURCE is the path of a get command, e.g. schema,
+	template or od/offer, and the reference is resolved with aepctl get
+	RESOURCE ID -o json. The prefix SANDBOX: selects the sandbox of a
+	reference, otherwise the sandbox of --sandbox is used.
+
+	Volatile fields like the meta: and repo: timestamps, the etags and the
+	_links are removed before the comparison. --ignore removes further fields
+	by name and --no-normalize compares the unmodified documents. The
+	differences are printed as a unified diff of the formatted documents, as
+	a structural diff or as JSON Patch (RFC 6902).`)
+	example = util.Example(`
+	aepctl diff schema/_tenant.schemas.abc dev:schema/_tenant.schemas.abc
+	aepctl diff --sandbox dev template/5ba5c7c7-3b4f-4a13-9c8b-bb3f0f2f8d50 template.json
+	aepctl diff "od/offer/Summer Sale" "staging:od/offer/Summer Sale" -o structural
+	aepctl diff query/1234 query/5678 -o json-patch`)
+	outputFormats = []string{"unified", "structural", "json-patch"}
+)
+
+// source is a local file or a resource reference
+type source struct {
+	name string
+	file string
+	args []string
+}
+
+// getCommand returns the get command of the command tree
+func getCommand(root *cobra.Command) (*cobra.Command, error) {
+	get, _, err := root.Find([]string{"get"})
+	if err != nil || get == root {
+		return nil, fmt.Errorf("the get command is not available")
+	}
+	return get, nil
+}
+
+// subCommand returns the sub command with the passed name or alias
+func subCommand(cmd *cobra.Command, name string) *cobra.Command {
+	for _, c := range cmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return c
+		}
+	}
+	return nil
+}
+
+// splitSandbox returns the sandbox prefix and the reference
+func splitSandbox(ref string) (string, string) {
+	if i := strings.Index(ref, ":"); i >= 0 && i < strings.Index(ref+"/", "/") {
+		return ref[:i], ref[i+1:]
+	}
+	return "", ref
+}
+
+// parseSource returns the file or the get command line of the reference
+func parseSource(root *cobra.Command, ref string) (*source, error) {
+	if ref == "-" {
+		return &source{name: "stdin", file: ref}, nil
+	}
+	if fi, err := os.Stat(ref); err == nil && !fi.IsDir() {
+		return &source{name: ref, file: ref}, nil
+	}
+	sandbox, path := splitSandbox(ref)
+	parts := strings.Split(path, "/")
+	get, err := getCommand(root)
+	if err != nil {
+		return nil, err
+	}
+	c, args := get, []string{"get"}
+	i := 0
+	for ; i < len(parts)-1; i++ {
+		sub := subCommand(c, parts[i])
+		if sub == nil {
+			break
+		}
+		c, args = sub, append(args, sub.Name())
+	}
+	if c == get || !c.Runnable() || c.HasSubCommands() {
+		return nil, fmt.Errorf("%s is neither a file nor a resource reference RESOURCE/ID, e.g. schema/ID", ref)
+	}
+	args = append(args, strings.Join(parts[i:], "/"))
+	if sandbox != "" {
+		args = append(args, "--sandbox="+sandbox)
+	}
+	return &source{name: ref, args: args}, nil
+}
+
+// load returns the decoded JSON document of the source
+func (s *source) load(cmd *cobra.Command) (interface{}, error) {
+	if s.file == "" {
+		return helper.ExecJSON(cmd, s.args...)
+	}
+	var (
+		data []byte
+		err  error
+	)
+	if s.file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(s.file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	docs, err := util.SplitDocuments(data, s.file)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("%s must contain exactly one document, found %d", s.name, len(docs))
+	}
+	var result interface{}
+	dec := json.NewDecoder(bytes.NewReader(docs[0]))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, fmt.Errorf("%s: %v", s.name, err)
+	}
+	return result, nil
+}
+
+// Volatile returns true for fields which are changed by the service without
+// modifying the resource: the meta: and repo: timestamps, the etags, the
+// sandbox of the resource and the _links
+func Volatile(key string) bool {
+	switch key {
+	case "_links", "eTag", "etag", "repo:etag", "meta:sandboxId", "meta:sandboxType":
+		return true
+	}
+	if strings.HasPrefix(key, "meta:") || strings.HasPrefix(key, "repo:") {
+		k := strings.ToLower(key)
+		return strings.Contains(k, "date") || strings.Contains(k, "created") ||
+			strings.Contains(k, "modified") || strings.Contains(k, "updated")
+	}
+	return false
+}
+
+// Options contains the settings of the comparison and the output
+type Options struct {
+	Output      string
+	Context     int
+	Ignore      []string
+	NoNormalize bool
+	Colored     bool
+}
+
+// Normalize removes the volatile and ignored fields of the document
+func (o *Options) Normalize(doc interface{}) interface{} {
+	ignore := make(map[string]bool, len(o.Ignore))
+	for _, name := range o.Ignore {
+		ignore[name] = true
+	}
+	return util.StripJSON(doc, func(key string, _ interface{}) bool {
+		return ignore[key] || (!o.NoNormalize && Volatile(key))
+	})
+}
+
+// Write prints the differences between the documents and returns true if
+// they differ
+func (o *Options) Write(w io.Writer, fromName, toName string, from, to interface{}) (bool, error) {
+	patch := util.Diff(from, to)
+	switch o.Output {
+	case "json-patch":
+		if patch == nil {
+			patch = util.JSONPatch{}
+		}
+		data, err := json.MarshalIndent(patch, "", "  ")
+		if err != nil {
+			return false, err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return len(patch) > 0, err
+	case "structural":
+		return len(patch) > 0, o.writeStructural(w, from, patch)
+	}
+	a, err := formatLines(from)
+	if err != nil {
+		return false, err
+	}
+	b, err := formatLines(to)
+	if err != nil {
+		return false, err
+	}
+	return len(patch) > 0, util.WriteUnifiedDiff(w, fromName, toName, util.DiffLines(a, b), o.Context, o.Colored)
+}
+
+// writeStructural prints one line per operation of the patch
+func (o *Options) writeStructural(w io.Writer, from interface{}, patch util.JSONPatch) error {
+	doc := util.NewJSONDoc(from)
+	paint := func(color, text string) string {
+		if o.Colored {
+			return util.Paint(color, text)
+		}
+		return text
+	}
+	for _, op := range patch {
+		path := op.Path
+		if path == "" {
+			path = "/"
+		}
+		var line string
+		switch op.Op {
+		case util.PatchAdd:
+			line = paint("green", "+ "+path+": "+compact(op.Value))
+		case util.PatchRemove:
+			old, _ := doc.Get(op.Path)
+			line = paint("red", "- "+path+": "+compact(old))
+		default:
+			old, _ := doc.Get(op.Path)
+			line = paint("yellow", "~ "+path+": "+compact(old)+" → "+compact(op.Value))
+		}
+		if _, err := fmt.Fprintln(w, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compact returns the compact JSON representation of the value
+func compact(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// formatLines returns the lines of the indented JSON document with sorted keys
+func formatLines(doc interface{}) ([]string, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(data), "\n"), nil
+}
+
+// completeSource returns the paths of the get commands
+func completeSource(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) >= 2 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	get, err := getCommand(cmd.Root())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	sandbox, _ := splitSandbox(toComplete)
+	if sandbox != "" {
+		sandbox += ":"
+	}
+	var result []string
+	var walk func(c *cobra.Command, path string)
+	walk = func(c *cobra.Command, path string) {
+		for _, sub := range c.Commands() {
+			if !sub.IsAvailableCommand() {
+				continue
+			}
+			p := path + sub.Name() + "/"
+			if sub.HasSubCommands() {
+				walk(sub, p)
+			} else if strings.HasPrefix(sandbox+p, toComplete) {
+				result = append(result, sandbox+p)
+			}
+		}
+	}
+	walk(get, "")
+	if len(result) == 0 {
+		// local files
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return result, cobra.ShellCompDirectiveNoSpace
+}
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	o := &Options{}
+	var exitCode bool
+	cmd := &cobra.Command{
+		Use:                   "diff SOURCE SOURCE",
+		Short:                 "Display the differences between two resources",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		ValidArgsFunction:     completeSource,
+		Run: func(cmd *cobra.Command, args []string) {
+			if !util.Contains(o.Output, outputFormats) {
+				helper.CheckErr(fmt.Errorf("unknown output format %s, use one of %s", o.Output, strings.Join(outputFormats, ", ")))
+			}
+			docs := make([]interface{}, len(args))
+			for i, arg := range args {
+				s, err := parseSource(cmd.Root(), arg)
+				helper.CheckErr(err)
+				if s.file == "" {
+					helper.CheckErr(conf.Validate(cmd))
+				}
+				doc, err := s.load(cmd)
+				helper.CheckErr(err)
+				docs[i] = o.Normalize(doc)
+			}
+			o.Colored = o.Output != "json-patch" && util.ColorEnabled()
+			differ, err := o.Write(os.Stdout, args[0], args[1], docs[0], docs[1])
+			helper.CheckErr(err)
+			if differ && exitCode {
+				helper.Exit(1)
+			}
+		},
+	}
+	conf.AddAuthenticationFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVarP(&o.Output, "output", "o", "unified", "output format: "+strings.Join(outputFormats, ", "))
+	flags.IntVarP(&o.Context, "context", "U", 3, "number of context lines of the unified diff")
+	flags.StringSliceVar(&o.Ignore, "ignore", nil, "names of further fields, which are removed before the comparison")
+	flags.BoolVar(&o.NoNormalize, "no-normalize", false, "keep the volatile fields")
+	flags.BoolVar(&exitCode, "exit-code", false, "exit with 1 if the documents differ")
+	helper.CheckErr(cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
@@ -0,0 +1,237 @@
+/*
+Package edit contains edit command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package edit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Edit a resource with $EDITOR.
+
+	The resource is fetched, the read-only fields are removed and the
+	resource is opened as YAML (default) or JSON. After saving the file and
+	closing the editor the changes are applied with the best method of the
+	service: Schema Registry resources and scheduled queries are patched
+	with JSON Patch, query templates are replaced and Offer Decisioning
+	objects are patched. If the service rejects the changes, the editor is
+	opened again with the edited document and the error. An unchanged or
+	empty file cancels the edit.`)
+	example = util.Example(`
+	aepctl edit fieldgroup _tenant.mixins.abc
+	aepctl edit template 5ba5c7c7-3b4f-4a13-9c8b-bb3f0f2f8d50 -o json
+	aepctl edit od offer "Summer Sale"`)
+	formats = []string{"yaml", "json"}
+)
+
+// header is written at the beginning of YAML files
+const header = `# Edit the resource and save the file to apply the changes. An unchanged or
+# empty file cancels the edit, read-only fields have been removed.
+`
+
+// resource fetches and changes an editable resource
+type resource struct {
+	// fetch returns the resource without the read-only fields
+	fetch func(ctx context.Context, id string) (interface{}, error)
+	// apply sends the changes, patch transforms the fetched into the edited
+	// resource
+	apply func(ctx context.Context, id string, patch util.JSONPatch, edited interface{}) error
+}
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "edit",
+		Short:                 "Edit a resource with $EDITOR",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+	}
+	conf.AddAuthenticationFlags(cmd)
+	cmd.AddCommand(NewClassCommand(conf))
+	cmd.AddCommand(NewDataTypeCommand(conf))
+	cmd.AddCommand(NewFieldGroupCommand(conf))
+	cmd.AddCommand(NewSchemaCommand(conf))
+	cmd.AddCommand(NewQueryTemplateCommand(conf))
+	cmd.AddCommand(NewScheduleCommand(conf))
+	cmd.AddCommand(NewODCommand(conf))
+	return cmd
+}
+
+// newEditCommand creates an initialized command object
+func newEditCommand(conf *helper.Configuration, r *resource, use, short string, aliases ...string) *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:                   use + " ID",
+		Aliases:               aliases,
+		Short:                 short,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Validate(cmd))
+			if !util.Contains(format, formats) {
+				helper.CheckErr(fmt.Errorf("unknown format %s, use yaml or json", format))
+			}
+			helper.CheckErr(edit(r, args[0], format))
+		},
+	}
+	cmd.Flags().StringVarP(&format, "output", "o", "yaml", "format of the edited document: yaml or json")
+	helper.CheckErr(cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return formats, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+// encode returns the document in the passed format
+func encode(doc interface{}, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil || format == "json" {
+		return append(data, '\n'), err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(header)
+	if err := util.JSONToYAML(data, &buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode returns the edited document or nil for an empty file
+func decode(data []byte, path string) (interface{}, error) {
+	docs, err := util.SplitDocuments(data, path)
+	if err != nil {
+		return nil, err
+	}
+	switch len(docs) {
+	case 0:
+		return nil, nil
+	case 1:
+		var result interface{}
+		return result, json.Unmarshal(docs[0], &result)
+	}
+	return nil, fmt.Errorf("the file contains %d documents, only one is allowed", len(docs))
+}
+
+// withError adds the error as comment to the YAML document
+func withError(data []byte, format string, err error) []byte {
+	if format != "yaml" {
+		return data
+	}
+	var buf bytes.Buffer
+	buf.WriteString("# The changes have been rejected:\n#\n")
+	for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+		buf.WriteString("# " + line + "\n")
+	}
+	buf.WriteString("#\n")
+	// remove the comment of the previous error
+	body := data
+	if i := bytes.Index(body, []byte(header)); i >= 0 {
+		body = body[i:]
+	}
+	buf.Write(body)
+	return buf.Bytes()
+}
+
+// edit fetches the resource, opens it in the editor and applies the changes.
+// Rejected changes are opened again until they are accepted or the edit is
+// cancelled.
+func edit(r *resource, id, format string) error {
+	ctx := context.Background()
+	original, err := r.fetch(ctx, id)
+	if err != nil {
+		return err
+	}
+	data, err := encode(original, format)
+	if err != nil {
+		return err
+	}
+	tmp, err := ioutil.TempFile("", "aepctl-edit-*."+format)
+	if err != nil {
+		return err
+	}
+	path := tmp.Name()
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	var rejected error
+	for {
+		if err = ioutil.WriteFile(path, data, 0600); err != nil {
+			return err
+		}
+		if err = helper.EditFile(path); err != nil {
+			return err
+		}
+		edited, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		doc, err := decode(edited, path)
+		if err == nil && (doc == nil || bytes.Equal(edited, data)) {
+			if rejected != nil {
+				return fmt.Errorf("%v\nYour changes have been saved in %s", rejected, path)
+			}
+			fmt.Println("Edit cancelled, no changes made.")
+			return os.Remove(path)
+		}
+		if err == nil {
+			patch := util.Diff(original, doc)
+			if len(patch) == 0 {
+				fmt.Println("Edit cancelled, no changes made.")
+				return os.Remove(path)
+			}
+			if err = r.apply(ctx, id, patch, doc); err == nil {
+				fmt.Printf("Updated %s\n", id)
+				return os.Remove(path)
+			}
+		}
+		rejected = err
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		data = withError(edited, format, err)
+		if format != "yaml" {
+			// the JSON document cannot contain the error
+			fmt.Fprint(os.Stderr, "Press ENTER to edit the resource again or CTRL-C to abort")
+			if _, err := bufio.NewReader(os.Stdin).ReadString('\n'); err != nil {
+				return fmt.Errorf("%v\nYour changes have been saved in %s", rejected, path)
+			}
+		}
+	}
+}
+
+// strip removes the top-level fields of the object
+func strip(doc interface{}, fields ...string) (interface{}, error) {
+	obj, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the resource is not a JSON object")
+	}
+	for _, f := range fields {
+		delete(obj, f)
+	}
+	return obj, nil
+}
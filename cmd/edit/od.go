@@ -0,0 +1,105 @@
+/*
+Package edit contains edit command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package edit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/api/od"
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// NewODCommand creates an initialized command object
+func NewODCommand(conf *helper.Configuration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "od",
+		Short: "Edit an Offer Decisioning object",
+	}
+	ac := cache.NewAutoContainer(conf.Authentication, conf)
+	helper.CheckErr(ac.AddContainerFlag(cmd))
+	cmd.AddCommand(newODEditCommand(conf, ac, "activity", od.ActivitySchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "collection", od.CollectionSchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "fallback", od.FallbackSchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "offer", od.OfferSchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "placement", od.PlacementSchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "rule", od.RuleSchema))
+	cmd.AddCommand(newODEditCommand(conf, ac, "tag", od.TagSchema))
+	return cmd
+}
+
+// newODEditCommand creates an initialized command object for the objects of
+// the passed schema. The objects are selected by name or id.
+func newODEditCommand(conf *helper.Configuration, ac *cache.AutoContainer, use, schema string) *cobra.Command {
+	// the instance id of the fetched object is required for the update
+	var instanceID string
+	r := &resource{
+		fetch: func(ctx context.Context, name string) (interface{}, error) {
+			cid, err := ac.Get()
+			if err != nil {
+				return nil, err
+			}
+			idc := cache.NewODNameToIDMem(ac, schema)
+			q, err := api.NewQuery(api.ODGet(ctx, conf.Authentication, &api.ODGetParams{
+				ContainerID: cid,
+				Schema:      schema,
+				ID:          idc.Lookup(name),
+			}))
+			if err != nil {
+				return nil, err
+			}
+			result := q.Path("_embedded", "results").Get(0)
+			if result.Nil() {
+				return nil, fmt.Errorf("%s %s not found", use, name)
+			}
+			instanceID, _ = result.Value("instanceId").(string)
+			if instanceID == "" {
+				return nil, errors.New("the response contains no instance id")
+			}
+			return strip(result.Path("_instance").Interface(), "@id")
+		},
+		apply: func(ctx context.Context, _ string, p util.JSONPatch, _ interface{}) error {
+			cid, err := ac.Get()
+			if err != nil {
+				return err
+			}
+			ops := make([]*od.UpdateOperation, len(p))
+			for i, op := range p {
+				ops[i] = &od.UpdateOperation{
+					Operation: op.Op,
+					Path:      "/_instance" + op.Path,
+					Value:     op.Value,
+				}
+			}
+			_, err = od.Patch(ctx, conf.Authentication, cid, instanceID, schema, ops...)
+			return err
+		},
+	}
+	cmd := newEditCommand(conf, r, use, "Edit a "+use+" (Offer Decisioning)", util.Plural(use))
+	cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 || conf.Update(cmd) != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return cache.NewODNameToID(ac, use, schema, conf.Sandboxed()).Keys(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return cmd
+}
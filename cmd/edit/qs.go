@@ -0,0 +1,94 @@
+/*
+Package edit contains edit command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package edit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// qsReadOnly contains the fields managed by the Query Service
+var qsReadOnly = []string{
+	"_links",
+	"client",
+	"created",
+	"id",
+	"lastUpdatedBy",
+	"orgId",
+	"sandboxId",
+	"updated",
+	"userId",
+	"version",
+}
+
+// fetchQS returns a function fetching a Query Service resource
+func fetchQS(conf *helper.Configuration, get func(context.Context, *api.AuthenticationConfig, string) (*http.Response, error)) func(context.Context, string) (interface{}, error) {
+	return func(ctx context.Context, id string) (interface{}, error) {
+		q, err := api.NewQuery(get(ctx, conf.Authentication, id))
+		if err != nil {
+			return nil, err
+		}
+		return strip(q.Interface(), qsReadOnly...)
+	}
+}
+
+// NewQueryTemplateCommand creates an initialized command object
+func NewQueryTemplateCommand(conf *helper.Configuration) *cobra.Command {
+	return newEditCommand(conf,
+		&resource{
+			fetch: fetchQS(conf, api.QSGetTemplate),
+			// query templates are replaced
+			apply: func(ctx context.Context, id string, _ util.JSONPatch, edited interface{}) error {
+				data, err := json.Marshal(edited)
+				if err != nil {
+					return err
+				}
+				return api.DropResponse(api.QSUpdateQueryTemplate(ctx, conf.Authentication, id, data))
+			},
+		},
+		"template",
+		"Edit a query template (Query Service)",
+		"templates",
+	)
+}
+
+// NewScheduleCommand creates an initialized command object
+func NewScheduleCommand(conf *helper.Configuration) *cobra.Command {
+	return newEditCommand(conf,
+		&resource{
+			fetch: fetchQS(conf, api.QSGetSchedule),
+			// scheduled queries are patched, the service supports the paths
+			// /state and /schedule/schedule
+			apply: func(ctx context.Context, id string, p util.JSONPatch, _ interface{}) error {
+				data, err := json.Marshal(map[string]interface{}{"body": p})
+				if err != nil {
+					return err
+				}
+				return api.DropResponse(api.QSUpdateSchedule(ctx, conf.Authentication, id, data))
+			},
+		},
+		"schedule",
+		"Edit a scheduled query (Query Service)",
+		"schedules",
+	)
+}
@@ -0,0 +1,132 @@
+/*
+Package edit contains edit command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package edit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// srReadOnly contains the fields managed by the Schema Registry
+var srReadOnly = []string{
+	"$id",
+	"_links",
+	"meta:altId",
+	"meta:containerId",
+	"meta:registryMetadata",
+	"meta:resourceType",
+	"meta:sandboxId",
+	"meta:sandboxType",
+	"meta:tenantNamespace",
+	"version",
+}
+
+// newSRResource returns an editable Schema Registry resource. The resource is
+// selected by id or by the title of a resource with the passed name, e.g.
+// schemas.
+func newSRResource(conf *helper.Configuration,
+	get func(context.Context, *api.AuthenticationConfig, *api.SRGetParams) (*http.Response, error),
+	patch api.FuncPostID, name string) *resource {
+	return &resource{
+		fetch: func(ctx context.Context, title string) (interface{}, error) {
+			id, err := helper.ResolveSRTitle(conf, false, title, name)
+			if err != nil {
+				return nil, err
+			}
+			p := &api.SRGetParams{
+				SRGetBaseParams: api.SRGetBaseParams{ID: id},
+				SRFormat:        api.SRFormat{Version: "1"},
+			}
+			q, err := api.NewQuery(get(ctx, conf.Authentication, p))
+			if err != nil {
+				return nil, err
+			}
+			return strip(q.Interface(), srReadOnly...)
+		},
+		apply: func(ctx context.Context, title string, p util.JSONPatch, _ interface{}) error {
+			id, err := helper.ResolveSRTitle(conf, false, title, name)
+			if err != nil {
+				return err
+			}
+			data, err := json.Marshal(p)
+			if err != nil {
+				return err
+			}
+			return api.DropResponse(patch(ctx, conf.Authentication, id, data))
+		},
+	}
+}
+
+// NewClassCommand creates an initialized command object
+func NewClassCommand(conf *helper.Configuration) *cobra.Command {
+	return newSREditCommand(conf,
+		newSRResource(conf, api.SRGetClass, api.SRPatchClass, "classes"),
+		"classes",
+		"class",
+		"Edit a class (Schema Registry)",
+		"classes",
+	)
+}
+
+// NewDataTypeCommand creates an initialized command object
+func NewDataTypeCommand(conf *helper.Configuration) *cobra.Command {
+	return newSREditCommand(conf,
+		newSRResource(conf, api.SRGetDataType, api.SRPatchDataType, "datatypes"),
+		"datatypes",
+		"datatype",
+		"Edit a data type (Schema Registry)",
+		"datatypes",
+		"data-type",
+		"data-types",
+	)
+}
+
+// NewFieldGroupCommand creates an initialized command object
+func NewFieldGroupCommand(conf *helper.Configuration) *cobra.Command {
+	return newSREditCommand(conf,
+		newSRResource(conf, api.SRGetFieldGroup, api.SRPatchFieldGroup, "fieldgroups"),
+		"fieldgroups",
+		"fieldgroup",
+		"Edit a field group (Schema Registry)",
+		"fieldgroups",
+	)
+}
+
+// NewSchemaCommand creates an initialized command object
+func NewSchemaCommand(conf *helper.Configuration) *cobra.Command {
+	return newSREditCommand(conf,
+		newSRResource(conf, api.SRGetSchema, api.SRPatchSchema, "schemas"),
+		"schemas",
+		"schema",
+		"Edit a schema (Schema Registry)",
+		"schemas",
+	)
+}
+
+// newSREditCommand creates an initialized command object with the completion
+// of the titles of the Schema Registry resources with the passed name
+func newSREditCommand(conf *helper.Configuration, r *resource, name, use, short string, aliases ...string) *cobra.Command {
+	cmd := newEditCommand(conf, r, use, short, aliases...)
+	cmd.ValidArgsFunction = helper.ValidSRTitle(conf, nil, name)
+	return cmd
+}
@@ -0,0 +1,122 @@
+/*
+Package export contains export command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/fuxs/aepctl/xdm"
+	"github.com/spf13/cobra"
+)
+
+var schemaFormats = []string{"jsonschema", "avro", "spark", "sql", "go", "typescript"}
+
+var (
+	schemaLong = util.LongDesc(`
+	Export the fully resolved schema as standalone JSON Schema, Avro schema,
+	Spark StructType JSON (Parquet), SQL DDL, Go structs or TypeScript types.`)
+	schemaExample = util.Example(`
+	aepctl export schema "Loyalty Members"
+	aepctl export schema "Loyalty Members" --format avro
+	aepctl export schema "Loyalty Members" --format sql --name loyalty_copy
+	aepctl export schema "Loyalty Members" --format go --package model`)
+)
+
+// NewSchemaCommand creates an initialized command object
+func NewSchemaCommand(conf *helper.Configuration) *cobra.Command {
+	var (
+		global bool
+		format string
+		name   string
+		pkg    string
+	)
+	cmd := &cobra.Command{
+		Use:                   "schema ID",
+		Short:                 "Export a schema to other schema languages",
+		Long:                  schemaLong,
+		Example:               schemaExample,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactValidArgs(1),
+		ValidArgsFunction:     helper.ValidSRTitle(conf, &global, "schemas"),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Validate(cmd))
+			if !util.Contains(format, schemaFormats) {
+				helper.CheckErr(fmt.Errorf("unknown format %s, use one of %v", format, schemaFormats))
+			}
+			id, err := helper.ResolveSRTitle(conf, global, args[0], "schemas")
+			helper.CheckErr(err)
+			s, err := loadSchema(conf, id, global)
+			helper.CheckErr(err)
+			if name == "" {
+				name = s.Title
+			}
+			helper.CheckErr(writeSchema(s, format, name, pkg))
+		},
+	}
+	conf.AddAuthenticationFlags(cmd)
+	flags := cmd.Flags()
+	flags.BoolVar(&global, "predefined", false, "export a schema defined by Adobe")
+	flags.StringVarP(&format, "format", "f", "jsonschema", "target format: jsonschema, avro, spark, sql, go or typescript")
+	flags.StringVar(&name, "name", "", "name of the record, table or type (default is the title of the schema)")
+	flags.StringVar(&pkg, "package", "schema", "name of the Go package")
+	helper.CheckErr(cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return schemaFormats, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
+
+// loadSchema requests the fully resolved schema
+func loadSchema(conf *helper.Configuration, id string, global bool) (*xdm.Schema, error) {
+	p := &api.SRGetParams{
+		SRGetBaseParams: api.SRGetBaseParams{ID: id, Global: global},
+		SRFormat:        api.SRFormat{Full: true, Version: "1"},
+	}
+	res, err := api.HandleStatusCode(api.SRGetSchema(context.Background(), conf.Authentication, p))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var doc interface{}
+	if err = json.NewDecoder(res.Body).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return xdm.Parse(doc)
+}
+
+// writeSchema writes the schema in the passed format to stdout
+func writeSchema(s *xdm.Schema, format, name, pkg string) error {
+	w := os.Stdout
+	switch format {
+	case "avro":
+		return xdm.WriteAvro(w, s, name)
+	case "spark":
+		return xdm.WriteSpark(w, s)
+	case "sql":
+		return xdm.WriteSQL(w, s, xdm.Snake(name))
+	case "go":
+		return xdm.WriteGo(w, s, name, pkg)
+	case "typescript":
+		return xdm.WriteTypeScript(w, s, name)
+	}
+	return xdm.WriteJSONSchema(w, s)
+}
@@ -26,7 +26,7 @@ import (
 
 // NewStatsCommand creates an initialized command object
 func NewSRCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{Default: "raw"}
+	output := &helper.OutputConf{Root: conf.Root, Default: "raw"}
 	cmd := &cobra.Command{
 		Use:                   "export resource_id",
 		Short:                 "export schema registry resource",
@@ -34,12 +34,16 @@ func NewSRCommand(conf *helper.Configuration) *cobra.Command {
 		Example:               "example",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactValidArgs(1),
+		ValidArgsFunction:     helper.ValidSRTitle(conf, nil),
 		Run: func(cmd *cobra.Command, args []string) {
 			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
-			helper.CheckErr(output.PrintResponse(api.SRExport(context.Background(), conf.Authentication, args[0])))
+			id, err := helper.ResolveSRTitle(conf, false, args[0])
+			helper.CheckErr(err)
+			helper.CheckErr(output.PrintResponse(api.SRExport(context.Background(), conf.Authentication, id)))
 		},
 	}
 	conf.AddAuthenticationFlags(cmd)
 	output.AddOutputFlags(cmd)
+	cmd.AddCommand(NewSchemaCommand(conf))
 	return cmd
 }
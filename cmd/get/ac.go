@@ -122,7 +122,7 @@ var effectiveTransformation string
 
 // NewEffectiveCommand creates an initialized command object
 func NewEffectiveCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "effective [(RESOURCE | PERMISSION)+]",
 		Short:                 "Display effective permissions",
@@ -143,12 +143,14 @@ func NewEffectiveCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", effectiveTransformation)
 	return cmd
 }
 
 // NewACCommand creates an initialized command object
 func NewPermissionsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "permissions",
 		Short:                 "Display all or effictive permissions",
@@ -163,5 +165,7 @@ func NewPermissionsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", permissionsTransformation)
 	return cmd
 }
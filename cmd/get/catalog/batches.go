@@ -31,7 +31,7 @@ var batchesTransformation string
 
 // NewBatchesCommand creates an initialized command object
 func NewBatchesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	bc := &api.BatchesOptions{}
 	cmd := &cobra.Command{
 		Use:                   "batches",
@@ -47,6 +47,8 @@ func NewBatchesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", batchesTransformation)
 	addFlags(bc, cmd)
 	return cmd
 }
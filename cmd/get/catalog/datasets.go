@@ -30,7 +30,7 @@ var datasetsTransformation string
 
 // NewDatasetsCommand creates an initialized command object
 func NewDatasetsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	bc := &api.BatchesOptions{}
 	cmd := &cobra.Command{
 		Use:                   "datasets",
@@ -46,6 +46,8 @@ func NewDatasetsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", datasetsTransformation)
 	addFlags(bc, cmd)
 	return cmd
 }
@@ -26,7 +26,7 @@ import (
 
 // NewDatasetsCommand creates an initialized command object
 func NewFileCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	fc := &api.DAOptions{}
 	cmd := &cobra.Command{
 		Use:                   "file fileId",
@@ -42,6 +42,7 @@ func NewFileCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	addFlags(cmd, fc)
 	return cmd
 }
@@ -30,7 +30,7 @@ var filesTransformation string
 
 // NewDatasetsCommand creates an initialized command object
 func NewFilesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	fc := &api.DAOptions{}
 	cmd := &cobra.Command{
 		Use:                   "files batchId",
@@ -47,6 +47,8 @@ func NewFilesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", filesTransformation)
 	addFlags(cmd, fc)
 	return cmd
 }
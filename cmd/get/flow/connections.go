@@ -29,7 +29,7 @@ var connectionsTransformation string
 
 // NewDatasetsCommand creates an initialized command object
 func NewConnectionsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.FlowGetConnectionsParams{}
 	cmd := &cobra.Command{
 		Use:                   "connections",
@@ -46,6 +46,8 @@ func NewConnectionsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", connectionsTransformation)
 	f := cmd.Flags()
 	helper.AddPagingFlagsToken(&p.PageParams, f)
 	f.BoolVar(&p.Count, "count", false, "boolean value specifying if the count of resources should be returned (true|false)")
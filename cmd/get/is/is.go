@@ -29,7 +29,7 @@ import (
 var xidTransformation string
 
 func NewXIDCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISParams{}
 	cmd := &cobra.Command{
 		Use:                   "xid (--namespace NAMESPACE_CODE|--ns-id NAMESPACE_ID) id",
@@ -52,6 +52,8 @@ func NewXIDCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", xidTransformation)
 	flags := cmd.Flags()
 	flags.StringVarP(&pp.Namespace, "namespace", "n", "", "namespace code, ECID is default")
 	flags.StringVar(&pp.NamespaceID, "ns-id", "", "namespace ID, e.g. 4 for ECID")
@@ -63,7 +65,7 @@ func NewXIDCommand(conf *helper.Configuration) *cobra.Command {
 var nsTransformation string
 
 func NewNamespaceCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	var imsOrg string
 	cmd := &cobra.Command{
 		Use:                   "namespace id",
@@ -83,6 +85,8 @@ func NewNamespaceCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", nsTransformation)
 	flags := cmd.Flags()
 	flags.StringVar(&imsOrg, "ims-org", "", "IMS organization ID")
 	return cmd
@@ -95,7 +99,7 @@ var idsTransformation string
 var xidsTransformation string
 
 func NewClusterCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISClusterParams{}
 	cmd := &cobra.Command{
 		Use:                   "ids xid",
@@ -120,6 +124,9 @@ func NewClusterCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", xidsTransformation)
+	output.AddTransformation("ids", idsTransformation)
 	flags := cmd.Flags()
 	flags.StringVarP(&pp.Namespace, "namespace", "n", "", "namespace code, e.g. ECID")
 	flags.StringVar(&pp.NamespaceID, "ns-id", "", "namespace ID, e.g. 4 for ECID")
@@ -129,7 +136,7 @@ func NewClusterCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewHistoryCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISClusterParams{}
 	cmd := &cobra.Command{
 		Use:                   "history xid",
@@ -153,6 +160,9 @@ func NewHistoryCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", xidsTransformation)
+	output.AddTransformation("ids", idsTransformation)
 	flags := cmd.Flags()
 	flags.StringVarP(&pp.Namespace, "namespace", "n", "", "namespace code, e.g. ECID")
 	flags.StringVar(&pp.NamespaceID, "ns-id", "", "namespace ID, e.g. 4 for ECID")
@@ -162,7 +172,7 @@ func NewHistoryCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewClustersCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISClustersParams{}
 	cmd := &cobra.Command{
 		Use:                   "clusters xid+",
@@ -192,6 +202,9 @@ func NewClustersCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", xidsTransformation)
+	output.AddTransformation("ids", idsTransformation)
 	flags := cmd.Flags()
 	flags.StringArrayVarP(&pp.Namespaces, "namespace", "n", []string{}, "namespace code, e.g. ECID")
 	flags.StringArrayVar(&pp.NamesapceIDs, "ns-id", []string{}, "namespace ID, e.g. 4 for ECID")
@@ -201,7 +214,7 @@ func NewClustersCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewHistoriesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISClustersParams{}
 	cmd := &cobra.Command{
 		Use:                   "histories xid+",
@@ -231,6 +244,9 @@ func NewHistoriesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", xidsTransformation)
+	output.AddTransformation("ids", idsTransformation)
 	flags := cmd.Flags()
 	//flags.StringArrayVarP(&pp.Namespaces, "namespace", "n", []string{}, "namespace code, e.g. ECID")
 	flags.StringArrayVar(&pp.NamesapceIDs, "ns-id", []string{}, "namespace ID, e.g. 4 for ECID")
@@ -240,7 +256,7 @@ func NewHistoriesCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewMappingCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	pp := &api.ISGetMappingParams{}
 	cmd := &cobra.Command{
 		Use:                   "mapping id",
@@ -260,6 +276,8 @@ func NewMappingCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", nsTransformation)
 	flags := cmd.Flags()
 	flags.StringVarP(&pp.Namespace, "namespace", "n", "", "namespace code, e.g. ECID")
 	flags.StringVar(&pp.NamespaceID, "ns-id", "", "namespace ID, e.g. 4 for ECID")
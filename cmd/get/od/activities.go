@@ -41,6 +41,7 @@ func NewActivitiesCommand(conf *helper.Configuration, ac *cache.AutoContainer) *
 		ac,
 		od.ActivitySchema,
 		"activities",
+		"activity",
 		activitiesTransformation, "placements", getPlacementCache(ac))
 }
 
@@ -61,6 +61,7 @@ func NewCollectionsCommand(conf *helper.Configuration, ac *cache.AutoContainer)
 		ac,
 		od.CollectionSchema,
 		"collections",
+		"collection",
 		collectionsTransformation, "", nil)
 }
 
@@ -35,6 +35,7 @@ func NewFallbacksCommand(conf *helper.Configuration, ac *cache.AutoContainer) *c
 		ac,
 		od.FallbackSchema,
 		"fallbacks",
+		"fallback",
 		fallbacksTransformation, "", nil)
 }
 
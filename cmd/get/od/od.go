@@ -28,7 +28,7 @@ import (
 
 // NewGetCommand creates an initialized command object
 func NewGetCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema, use, t, n string, c *cache.MapMemCache) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:  use,
 		Args: cobra.MinimumNArgs(1),
@@ -42,9 +42,8 @@ func NewGetCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema,
 		},
 		Run: func(cmd *cobra.Command, args []string) {
 			idc := cache.NewODNameToID(ac, use, schema, conf.Sandboxed())
-			idc.Delete()
 			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
-			td, err := util.NewTableDescriptor(t)
+			td, err := output.TableDescriptor(t)
 			helper.CheckErr(err)
 			if c != nil {
 				td.AddMapping(n, c.Mapper())
@@ -63,20 +62,22 @@ func NewGetCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema,
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", t)
 	helper.CheckErr(ac.AddContainerFlag(cmd))
 	return cmd
 }
 
 // NewQueryCommand creates an initialized command object
-func NewQueryCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema, use, t, n string, c *cache.MapMemCache) *cobra.Command {
-	output := &helper.OutputConf{}
+func NewQueryCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema, use, get, t, n string, c *cache.MapMemCache) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.ODQueryParames{Schema: schema}
 	cmd := &cobra.Command{
 		Use:  use,
 		Args: cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
-			td, err := util.NewTableDescriptor(t)
+			td, err := output.TableDescriptor(t)
 			helper.CheckErr(err)
 			if c != nil {
 				td.AddMapping(n, c.Mapper())
@@ -90,6 +91,9 @@ func NewQueryCommand(conf *helper.Configuration, ac *cache.AutoContainer, schema
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", t)
+	output.SetDrillDown("get", "od", get, "{ID}")
 	flags := cmd.PersistentFlags()
 	flags.StringVarP(&p.Query, "query", "q", "", "Query string to search for in selected fields")
 	flags.StringVar(&p.QOP, "qop", "", "Applies AND or OR operator to values in q query string param.")
@@ -35,6 +35,7 @@ func NewOffersCommand(conf *helper.Configuration, ac *cache.AutoContainer) *cobr
 		ac,
 		od.OfferSchema,
 		"offers",
+		"offer",
 		offersTransformation, "", nil)
 }
 
@@ -35,6 +35,7 @@ func NewPlacementsCommand(conf *helper.Configuration, ac *cache.AutoContainer) *
 		ac,
 		od.PlacementSchema,
 		"placements",
+		"placement",
 		placementsTransformation, "", nil)
 }
 
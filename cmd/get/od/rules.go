@@ -35,6 +35,7 @@ func NewRulesCommand(conf *helper.Configuration, ac *cache.AutoContainer) *cobra
 		ac,
 		od.RuleSchema,
 		"rules",
+		"rule",
 		rulesTransformation, "", nil)
 }
 
@@ -35,6 +35,7 @@ func NewTagsCommand(auth *helper.Configuration, ac *cache.AutoContainer) *cobra.
 		ac,
 		od.TagSchema,
 		"tags",
+		"tag",
 		tagsTransformation, "", nil)
 }
 
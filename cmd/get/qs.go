@@ -26,7 +26,7 @@ import (
 )
 
 func NewConnectionCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "connection",
 		Short:                 "Display connection parameters (Query Service)",
@@ -40,6 +40,7 @@ func NewConnectionCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	return cmd
 }
 
@@ -47,7 +48,7 @@ func NewConnectionCommand(conf *helper.Configuration) *cobra.Command {
 var queryTransformation string
 
 func NewQueryCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "query",
 		Short:                 "Display a query (Query Service)",
@@ -62,6 +63,8 @@ func NewQueryCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", queryTransformation)
 	return cmd
 }
 
@@ -69,7 +72,7 @@ func NewQueryCommand(conf *helper.Configuration) *cobra.Command {
 var runTransformation string
 
 func NewRunCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "run scheduleId runId",
 		Short:                 "Display scheduled query run (Query Service)",
@@ -84,6 +87,8 @@ func NewRunCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", runTransformation)
 	return cmd
 }
 
@@ -91,7 +96,7 @@ func NewRunCommand(conf *helper.Configuration) *cobra.Command {
 var scheduleTransformation string
 
 func NewScheduleCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "schedule",
 		Short:                 "Display scheduled query (Query Service)",
@@ -106,6 +111,8 @@ func NewScheduleCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", scheduleTransformation)
 	return cmd
 }
 
@@ -113,7 +120,7 @@ func NewScheduleCommand(conf *helper.Configuration) *cobra.Command {
 var templateTransformation string
 
 func NewTemplateCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "template",
 		Short:                 "Display a query template (Query Service)",
@@ -128,5 +135,7 @@ func NewTemplateCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", templateTransformation)
 	return cmd
 }
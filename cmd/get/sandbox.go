@@ -37,7 +37,7 @@ var typesTransformation string
 
 // NewSandboxCommand creates an initialized command object
 func NewSandboxCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:  "sandbox",
 		Args: cobra.MaximumNArgs(1),
@@ -64,12 +64,15 @@ func NewSandboxCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", sandboxesTransformation)
+	output.AddTransformation("details", detailsTransformation)
 	return cmd
 }
 
 // NewSandboxesCommand creates an initialized command object
 func NewSandboxesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:       "sandboxes",
 		Args:      cobra.MaximumNArgs(1),
@@ -93,5 +96,8 @@ func NewSandboxesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", sandboxesTransformation)
+	output.AddTransformation("types", typesTransformation)
 	return cmd
 }
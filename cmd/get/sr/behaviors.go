@@ -27,7 +27,7 @@ import (
 
 // NewBehaviorCommand creates an initialized command object
 func NewBehaviorCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.SRGetGlobalParams{}
 	cmd := &cobra.Command{
 		Use:                   "behavior",
@@ -48,6 +48,7 @@ func NewBehaviorCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	addAcceptVersionedFlags(cmd, &p.SRFormat)
 	return cmd
 }
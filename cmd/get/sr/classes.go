@@ -27,6 +27,7 @@ func NewClassCommand(conf *helper.Configuration) *cobra.Command {
 	return newGetCommand(
 		conf,
 		"class",
+		"classes",
 		"Display a class",
 		"long",
 		"example",
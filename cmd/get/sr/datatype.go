@@ -27,6 +27,7 @@ func NewDataTypeCommand(conf *helper.Configuration) *cobra.Command {
 	return newGetCommand(
 		conf,
 		"datatype",
+		"datatypes",
 		"Display a data type",
 		"long",
 		"example",
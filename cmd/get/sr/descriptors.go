@@ -26,7 +26,7 @@ import (
 
 // NewDescriptorCommand creates an initialized command object
 func NewDescriptorCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.SRListDescriptorsParams{}
 	cmd := &cobra.Command{
 		Use:                   "descriptor",
@@ -44,5 +44,6 @@ func NewDescriptorCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	return cmd
 }
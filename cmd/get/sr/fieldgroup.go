@@ -27,6 +27,7 @@ func NewFieldGroupCommand(conf *helper.Configuration) *cobra.Command {
 	return newGetCommand(
 		conf,
 		"fieldgroup",
+		"fieldgroups",
 		"Display a fieldgroup",
 		"long",
 		"example",
@@ -26,7 +26,7 @@ import (
 
 // NewDescriptorCommand creates an initialized command object
 func NewSampleCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{Default: "json"}
+	output := &helper.OutputConf{Root: conf.Root, Default: "json"}
 	cmd := &cobra.Command{
 		Use:                   "sample schema_id",
 		Short:                 "Display sample data for schema",
@@ -34,11 +34,15 @@ func NewSampleCommand(conf *helper.Configuration) *cobra.Command {
 		Example:               "example",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
+		ValidArgsFunction:     helper.ValidSRTitle(conf, nil, "schemas"),
 		Run: func(cmd *cobra.Command, args []string) {
 			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
-			helper.CheckErr(output.PrintResponse(api.SRGetSample(context.Background(), conf.Authentication, args[0])))
+			id, err := helper.ResolveSRTitle(conf, false, args[0], "schemas")
+			helper.CheckErr(err)
+			helper.CheckErr(output.PrintResponse(api.SRGetSample(context.Background(), conf.Authentication, id)))
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	return cmd
 }
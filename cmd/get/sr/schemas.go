@@ -28,6 +28,7 @@ func NewSchemaCommand(conf *helper.Configuration) *cobra.Command {
 	return newGetCommand(
 		conf,
 		"schema",
+		"schemas",
 		"Display a schema",
 		"long",
 		"example",
@@ -26,8 +26,8 @@ import (
 )
 
 // NewStatsCommand creates an initialized command object
-func newGetCommand(conf *helper.Configuration, use, short, long, example string, f func(context.Context, *api.AuthenticationConfig, *api.SRGetParams) (*http.Response, error)) *cobra.Command {
-	output := &helper.OutputConf{}
+func newGetCommand(conf *helper.Configuration, use, name, short, long, example string, f func(context.Context, *api.AuthenticationConfig, *api.SRGetParams) (*http.Response, error)) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.SRGetParams{}
 	cmd := &cobra.Command{
 		Use:                   use,
@@ -43,11 +43,15 @@ func newGetCommand(conf *helper.Configuration, use, short, long, example string,
 			} else {
 				output.SetTransformation(helper.NewRefTransformer("$"))
 			}
-			p.ID = args[0]
+			id, err := helper.ResolveSRTitle(conf, p.Global, args[0], name)
+			helper.CheckErr(err)
+			p.ID = id
 			helper.CheckErr(output.PrintResponse(f(context.Background(), conf.Authentication, p)))
 		},
 	}
+	cmd.ValidArgsFunction = helper.ValidSRTitle(conf, &p.Global, name)
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
 	addAcceptVersionedFlags(cmd, &p.SRFormat)
 	flags := cmd.Flags()
 	flags.BoolVar(&p.Global, "predefined", false, "return resource defined by Adobe")
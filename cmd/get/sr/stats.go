@@ -33,7 +33,7 @@ var createdTransformation string
 
 // NewStatsCommand creates an initialized command object
 func NewStatsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:                   "stats",
 		Short:                 "Display all stats",
@@ -56,5 +56,8 @@ func NewStatsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", statsTransformation)
+	output.AddTransformation("created", createdTransformation)
 	return cmd
 }
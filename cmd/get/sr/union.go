@@ -28,6 +28,7 @@ func NewUnionCommand(conf *helper.Configuration) *cobra.Command {
 	return newGetCommand(
 		conf,
 		"union",
+		"unions",
 		"Display a union schema",
 		"long",
 		"example",
@@ -28,7 +28,7 @@ var transformation string
 
 // NewTokenCommand creates an initialized command object
 func NewTokenCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	cmd := &cobra.Command{
 		Use:  "token",
 		Args: cobra.NoArgs,
@@ -42,5 +42,7 @@ func NewTokenCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", transformation)
 	return cmd
 }
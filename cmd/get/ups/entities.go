@@ -31,7 +31,7 @@ var profileTransformation string
 
 // NewEntitiesCommand creates an initialized command object
 func NewEntitiesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	ep := &api.UPSEntitiesParams{}
 	cmd := &cobra.Command{
 		Use:                   "entities",
@@ -47,6 +47,8 @@ func NewEntitiesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", profileTransformation)
 	flags := cmd.Flags()
 	flags.StringVar(&ep.TimeFormat, "time-format", time.RFC3339, "format for date parsing, default is '2006-01-02T15:04:05Z07:00' (RFC3339)")
 	flags.StringVar(&ep.Schema, "schema", "_xdm.context.profile", "XED schema class name, default is _xdm.context.profile")
@@ -67,7 +69,7 @@ func NewEntitiesCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewProfileCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	ep := &api.UPSEntitiesParams{Schema: "_xdm.context.profile"}
 	cmd := &cobra.Command{
 		Use:                   "profile",
@@ -84,6 +86,8 @@ func NewProfileCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", profileTransformation)
 	flags := cmd.Flags()
 	// flags.StringVar(&p.Schema, "schema", "_xdm.context.profile", "XED schema class name, default is _xdm.context.profile")
 	// flags.StringVar(&p.RelatedSchema, "related-schema", "", "Must be set if schema is _xdm.context.experienceevent")
@@ -103,7 +107,7 @@ func NewProfileCommand(conf *helper.Configuration) *cobra.Command {
 }
 
 func NewEventsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	ep := &api.UPSEntitiesParams{Schema: "_xdm.context.experienceevent", RelatedSchema: "_xdm.context.profile"}
 	cmd := &cobra.Command{
 		Use:                   "events",
@@ -120,6 +124,8 @@ func NewEventsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", profileTransformation)
 	flags := cmd.Flags()
 	// flags.StringVar(&p.Schema, "schema", "_xdm.context.profile", "XED schema class name, default is _xdm.context.profile")
 	// flags.StringVar(&p.RelatedSchema, "related-schema", "", "Must be set if schema is _xdm.context.experienceevent")
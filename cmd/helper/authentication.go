@@ -18,22 +18,36 @@ package helper
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/fuxs/aepctl/api"
 	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/store"
 
 	"github.com/fuxs/aepctl/util"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+// defaultMaxStale is the default duration an expired cache is still served
+const defaultMaxStale = time.Hour * 24 * 7
+
+// revalidationInterval is the minimum interval between two background
+// refreshes of the same cache
+const revalidationInterval = time.Minute
+
 // Configuration encapsulates the global settings
 type Configuration struct {
 	Root           *util.RootConfig
 	Authentication *api.AuthenticationConfig
 	Read           bool
 	Write          bool
+	NoCache        bool
+	cmd            *cobra.Command
 }
 
 // NewConfiguration creates an initialized Authentication object
@@ -54,6 +68,8 @@ func NewConfiguration(gcfg *util.RootConfig) *Configuration {
 	o.SaveToken = func(token *api.BearerToken) error {
 		return cache.Save(token)
 	}
+	o.LockToken = cache.Lock
+	o.OfflineRequest = store.New(result.Sandboxed()).Handle
 
 	/* ac := NewAutoContainer(result)
 	result.AC = ac
@@ -70,6 +86,7 @@ func NewConfiguration(gcfg *util.RootConfig) *Configuration {
 
 // Update loads the configuration and updates the command flags
 func (a *Configuration) Update(cmd *cobra.Command) error {
+	a.cmd = cmd
 	return a.Root.Configure(cmd)
 }
 
@@ -78,10 +95,12 @@ func (a *Configuration) AddAuthenticationFlags(cmd *cobra.Command) {
 	o := a.Authentication
 	flags := cmd.PersistentFlags()
 	flags.BoolVar(&o.Cache, "cache", true, "stores the retrieved token in ~/.aepctl/token.json")
-	flags.BoolVar(&a.Read, "read-cache", true, "stores the retrieved token in ~/.aepctl/token.json")
-	flags.BoolVar(&a.Write, "write-cache", true, "stores the retrieved token in ~/.aepctl/token.json")
+	flags.BoolVar(&a.Read, "read-cache", true, "reads the file caches in ~/.aepctl/cache")
+	flags.BoolVar(&a.Write, "write-cache", true, "writes the file caches in ~/.aepctl/cache")
+	flags.BoolVar(&a.NoCache, "no-cache", false, "neither reads nor writes the file caches in ~/.aepctl/cache")
 
 	flags.BoolVar(&o.DryRun, "dry-run", false, "builds the request but doesn't execute it.")
+	flags.BoolVar(&o.Offline, "offline", false, "reads the resources from the local store, see aepctl sync")
 	flags.StringVar(&o.Server, "server", "https://ims-na1.adobelogin.com/ims/exchange/jwt/", "OAuth 2.0 server")
 	flags.StringVar(&o.Organization, "organization", "", "organization")
 	flags.StringVar(&o.TechnicalAccount, "tech-account", "", "technical account id")
@@ -223,16 +242,80 @@ func (p *sandboxedProvider) Path(path ...string) string {
 	return p.cfg.UniqueSandboxPath(path...)
 }
 
+func (p *sandboxedProvider) ReadCache() bool {
+	return p.cfg.ReadCache()
+}
+
+func (p *sandboxedProvider) WriteCache() bool {
+	return p.cfg.WriteCache()
+}
+
+func (p *sandboxedProvider) TTL(kind string, d time.Duration) time.Duration {
+	return p.cfg.TTL(kind, d)
+}
+
+func (p *sandboxedProvider) MaxStale() time.Duration {
+	return p.cfg.MaxStale()
+}
+
+func (p *sandboxedProvider) Revalidate(path string) {
+	p.cfg.Revalidate(path)
+}
+
 func (a *Configuration) Sandboxed() util.PathProvider {
 	return &sandboxedProvider{cfg: a}
 }
 
-// ReadCache returns the read-cache flag
+// ReadCache returns the read-cache flag, --no-cache disables it
 func (a *Configuration) ReadCache() bool {
-	return a.Read
+	return a.Read && !a.NoCache
 }
 
-// WriteCache returns the write-cache flag
+// WriteCache returns the write-cache flag, --no-cache disables it
 func (a *Configuration) WriteCache() bool {
-	return a.Write
+	return a.Write && !a.NoCache
+}
+
+// TTL returns the lifetime of the cache kind configured with cache-ttl in the
+// configuration file, e.g. cache-ttl: {od: 10m}
+func (a *Configuration) TTL(kind string, d time.Duration) time.Duration {
+	if v := viper.GetString("cache-ttl." + kind); v != "" {
+		if result, err := time.ParseDuration(v); err == nil {
+			return result
+		}
+	}
+	return d
+}
+
+// MaxStale returns the duration an expired cache is still served configured
+// with cache-stale in the configuration file. 0 disables the serving of
+// expired caches.
+func (a *Configuration) MaxStale() time.Duration {
+	if v := viper.GetString("cache-stale"); v != "" {
+		if result, err := time.ParseDuration(v); err == nil {
+			return result
+		}
+	}
+	return defaultMaxStale
+}
+
+// Revalidate starts aepctl cache refresh for the passed cache file in the
+// background. A marker file prevents multiple refreshes of the same file
+// within a minute.
+func (a *Configuration) Revalidate(path string) {
+	if !a.WriteCache() || a.Authentication.DryRun {
+		return
+	}
+	name, err := filepath.Rel(a.Path(), path)
+	if err != nil {
+		return
+	}
+	marker := path + ".refresh"
+	if fi, err := os.Stat(marker); err == nil && time.Since(fi.ModTime()) < revalidationInterval {
+		return
+	}
+	if err := ioutil.WriteFile(marker, nil, 0600); err != nil {
+		return
+	}
+	_ = Detach(a.cmd, "cache", "refresh", strings.TrimSuffix(filepath.ToSlash(name), ".json"))
 }
@@ -0,0 +1,47 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// Editor returns the command line of the editor defined by $VISUAL or $EDITOR
+func Editor() []string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.Fields(os.Getenv(env)); len(e) > 0 {
+			return e
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// EditFile opens the file in the editor and waits until the editor is closed
+func EditFile(path string) error {
+	e := Editor()
+	c := exec.Command(e[0], append(e[1:], path)...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	return c.Run()
+}
@@ -60,9 +60,32 @@ func info(msg string, code int) {
 	}
 }
 
+// exitHooks are executed before the program exits with an error
+var exitHooks []func()
+
+// atExit registers a function, which is executed before the program exits
+// with an error, e.g. for removing temporary files
+func atExit(f func()) {
+	exitHooks = append(exitHooks, f)
+}
+
+// exit executes the registered hooks and terminates the program
+func exit(code int) {
+	for i := len(exitHooks) - 1; i >= 0; i-- {
+		exitHooks[i]()
+	}
+	os.Exit(code)
+}
+
+// Exit executes the registered hooks and terminates the program with the
+// passed exit code
+func Exit(code int) {
+	exit(code)
+}
+
 func fatal(msg string, code int) {
 	info(msg, code)
-	os.Exit(code)
+	exit(code)
 }
 
 func formatError(err error, handler func(string, int)) {
@@ -84,5 +107,5 @@ func PrintError(msg string, cmd *cobra.Command) {
 	if err := cmd.Help(); err != nil {
 		fmt.Fprintln(os.Stderr, err.Error())
 	}
-	os.Exit(1)
+	exit(1)
 }
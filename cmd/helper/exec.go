@@ -0,0 +1,111 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// ExecJSON executes aepctl with the passed arguments and --output=json and
+// returns the decoded JSON response. The global flags of cmd, e.g. --config
+// or --sandbox, are passed before the arguments, thus flags in the arguments
+// have precedence.
+func ExecJSON(cmd *cobra.Command, args ...string) (interface{}, error) {
+	all := append(append(globalFlags(cmd), args...), "--output=json")
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	c := exec.Command(exe, all...)
+	c.Stdout = &stdout
+	c.Stderr = &stderr
+	if err := c.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %v\n%s", strings.Join(args, " "), err, stderr.String())
+	}
+	var result interface{}
+	dec := json.NewDecoder(&stdout)
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Detach starts aepctl with the passed arguments in a new process without
+// waiting for it. The global flags of cmd are passed before the arguments if
+// the called command supports them.
+func Detach(cmd *cobra.Command, args ...string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	var flags []string
+	if cmd != nil {
+		if target, _, err := cmd.Root().Find(args); err == nil {
+			for _, f := range globalFlags(cmd) {
+				name := strings.TrimPrefix(f[:strings.Index(f, "=")], "--")
+				if target.Flag(name) != nil {
+					flags = append(flags, f)
+				}
+			}
+		}
+	}
+	c := exec.Command(exe, append(flags, args...)...)
+	detach(c)
+	if err := c.Start(); err != nil {
+		return err
+	}
+	return c.Process.Release()
+}
+
+// globalFlags returns the changed global flags of cmd, e.g. the
+// authentication flags. Output flags like --watch are skipped.
+func globalFlags(cmd *cobra.Command) []string {
+	var flags []string
+	if cmd != nil {
+		// Visit would miss the flags, only the flag set of the command knows
+		// the parsed flags
+		add := func(f *pflag.Flag) {
+			if !f.Changed {
+				return
+			}
+			if _, ok := f.Annotations[outputAnnotation]; ok {
+				return
+			}
+			// the string of a slice is formatted like [a,b]
+			if sv, ok := f.Value.(pflag.SliceValue); ok {
+				for _, v := range sv.GetSlice() {
+					flags = append(flags, "--"+f.Name+"="+v)
+				}
+				return
+			}
+			flags = append(flags, "--"+f.Name+"="+f.Value.String())
+		}
+		cmd.InheritedFlags().VisitAll(add)
+		cmd.PersistentFlags().VisitAll(add)
+	}
+	return flags
+}
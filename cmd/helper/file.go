@@ -23,6 +23,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/fuxs/aepctl/util"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -42,7 +43,7 @@ type FileConfig struct {
 func (c *FileConfig) AddFileFlag(cmd *cobra.Command) {
 	flags := cmd.PersistentFlags()
 	flags.StringVarP(&c.Path, "file", "f", "", "a file")
-	flags.StringVarP(&c.Format, "input", "i", "yaml", "the input format (yaml|yaml-raw)")
+	flags.StringVarP(&c.Format, "input", "i", "yaml", "the input format (yaml|yaml-raw|json|ndjson)")
 }
 
 // AddMandatoryFileFlag adds the required file flags to the passed command. --file becomes mandatory.
@@ -58,6 +59,20 @@ func (c *FileConfig) IsYAML() bool {
 	return strings.ToLower(c.Format) == "yaml"
 }
 
+// isJSON checks if the format or the extension of the file is JSON or
+// newline delimited JSON
+func (c *FileConfig) isJSON() bool {
+	switch strings.ToLower(c.Format) {
+	case "json", "ndjson":
+		return true
+	}
+	switch strings.ToLower(filepath.Ext(c.Path)) {
+	case ".json", ".ndjson", ".jsonl":
+		return true
+	}
+	return false
+}
+
 // IsSet returns true if the path is set
 func (c *FileConfig) IsSet() bool {
 	return len(c.Path) > 0
@@ -77,8 +92,9 @@ func (c *FileConfig) Open() (*FileIterator, error) {
 		}
 	}
 	reader := bufio.NewReader(file)
-	if strings.ToLower(filepath.Ext(c.Path)) == "json" {
-		result.Decoder = json.NewDecoder(reader)
+	// the JSON decoder reads concatenated documents and NDJSON
+	if c.isJSON() || (c.Path == "-" && util.IsJSON(reader)) {
+		result.Decoder = &jsonDecoder{dec: json.NewDecoder(reader)}
 	} else {
 		dec := yaml.NewDecoder(reader)
 		//dec.SetStrict(true)
@@ -87,7 +103,22 @@ func (c *FileConfig) Open() (*FileIterator, error) {
 	return result, nil
 }
 
-// FileIterator helps iterating multiple YAML documents in one file
+// jsonDecoder reads concatenated JSON documents and decodes them like YAML,
+// thus JSON and YAML documents have the same attribute names
+type jsonDecoder struct {
+	dec *json.Decoder
+}
+
+// Decode decodes the next JSON document
+func (d *jsonDecoder) Decode(v interface{}) error {
+	var raw json.RawMessage
+	if err := d.dec.Decode(&raw); err != nil {
+		return err
+	}
+	return yaml.Unmarshal(raw, v)
+}
+
+// FileIterator helps iterating multiple YAML or JSON documents in one file
 type FileIterator struct {
 	Decoder Decoder
 }
@@ -19,17 +19,21 @@ package helper
 import (
 	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/PaesslerAG/jsonpath"
 	"github.com/fuxs/aepctl/api"
 	"github.com/fuxs/aepctl/util"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 // OutputType is used for the encoding of different output formats
@@ -52,8 +56,33 @@ const (
 	PVOut
 	// PVOut is using three columns name, value and path
 	NVPOUT
+	// MarkdownOut is used for tables in Markdown format
+	MarkdownOut
+	// HTMLOut is used for tables in HTML format
+	HTMLOut
+	// TUIOut is used for the interactive table viewer
+	TUIOut
+	// NDJSONOut is used for one JSON object per line
+	NDJSONOut
+	// CSVOut is used for tables in CSV format
+	CSVOut
 )
 
+// extensionFormats maps the extensions of output files to output formats
+var extensionFormats = map[string]string{
+	".json":     "json",
+	".ndjson":   "ndjson",
+	".jsonl":    "ndjson",
+	".yaml":     "yaml",
+	".yml":      "yaml",
+	".csv":      "csv",
+	".md":       "markdown",
+	".markdown": "markdown",
+	".html":     "html",
+	".htm":      "html",
+	".txt":      "table",
+}
+
 // Transformer objects will implement transformation logic for certain OutputTypes
 type Transformer interface {
 	Header(wide bool) []string
@@ -64,15 +93,33 @@ type Transformer interface {
 
 // OutputConf contains all options for the output
 type OutputConf struct {
-	Output    string
-	Default   string
-	Type      OutputType
-	Truncate  bool
-	Flush     bool
-	Paging    bool
-	jsonPath  string
-	transPath string
-	tf        Transformer
+	Root        *util.RootConfig // locates the overrides of table descriptors
+	Output      string
+	Default     string
+	Type        OutputType
+	Truncate    bool
+	Flush       bool
+	Paging      bool
+	Generated   bool
+	SortBy      string
+	Where       []string
+	Columns     []string
+	Watch       bool
+	Interval    time.Duration
+	Interactive bool
+	OutputFile  string
+	Force       bool
+	Summary     bool
+	GroupBy     []string
+	jsonPath    string
+	transPath   string
+	custom      []*util.TableColumnDescriptor
+	tf          Transformer
+	cmd         *cobra.Command
+	out         io.Writer       // replaces standard out, e.g. in watch mode
+	seen        map[string]bool // rows written by previous runs in watch mode
+	drill       []string        // get command for the details in the interactive table
+	summary     *util.Summary   // summary of the current table
 }
 
 // SetTransformation changes the Transformer object
@@ -82,51 +129,92 @@ func (o *OutputConf) SetTransformation(tf Transformer) {
 
 // SetTransformationDesc changes the Transformer object
 func (o *OutputConf) SetTransformationDesc(def string) error {
-	yaml := def
-	if o.transPath != "" {
-		f, err := os.Open(o.transPath)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		var d []byte
-		if d, err = ioutil.ReadAll(f); err != nil {
-			return err
-		}
-		yaml = string(d)
-	}
-	td, err := util.NewTableDescriptor(yaml)
+	td, err := o.TableDescriptor(def)
 	o.tf = td
 	return err
 }
 
+// outputAnnotation marks the output flags, they are not passed to child
+// processes (see globalFlags)
+const outputAnnotation = "aepctl.output"
+
+// addOutputFlags adds the flags to the persistent flags of the command and
+// marks them as output flags
+func addOutputFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
+	flags.VisitAll(func(f *pflag.Flag) {
+		f.Annotations = map[string][]string{outputAnnotation: {"true"}}
+	})
+	cmd.PersistentFlags().AddFlagSet(flags)
+}
+
 // AddOutputFlags extends the passed command with flags for output
 func (o *OutputConf) AddOutputFlags(cmd *cobra.Command) {
-	flags := cmd.PersistentFlags()
-	flags.StringVarP(&o.Output, "output", "o", o.Default, "Output format (json|jsonpath=''|nvp|pv|raw|table|wide)")
+	o.cmd = cmd
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.StringVarP(&o.Output, "output", "o", o.Default, "Output format (csv|custom-columns=''|custom-columns-file=''|html|json|jsonpath=''|markdown|ndjson|nvp|pv|raw|table|tui|wide|yaml)")
 	flags.BoolVarP(&o.Truncate, "truncate", "t", false, "Truncate output to terminal width")
+	flags.BoolVar(&o.Generated, "generated", false, "Add a generated-at line with the sandbox name (html|markdown)")
+	flags.StringVar(&o.SortBy, "sort-by", "", "Sort table rows by column, e.g. NAME or NAME:desc")
+	flags.StringArrayVar(&o.Where, "where", nil, "Filter table rows by expression 'COLUMN op VALUE' with op =,!=,<,<=,>,>=,=~ or !~")
+	flags.StringSliceVar(&o.Columns, "columns", nil, "Select and order table columns, e.g. NAME,ID")
+	flags.BoolVar(&o.Interactive, "interactive", false, "Show the table in an interactive viewer (same as -o tui)")
+	flags.StringVar(&o.OutputFile, "output-file", "", "Write the output to a file, the extension selects the format unless --output is set")
+	flags.BoolVar(&o.Force, "force", false, "Overwrite an existing output file")
+	flags.BoolVar(&o.Summary, "summary", false, "Print a summary table below the table, e.g. the number of rows")
+	flags.StringSliceVar(&o.GroupBy, "group-by", nil, "Print a summary table grouped by columns, e.g. STATE")
+	addOutputFlags(cmd, flags)
+	if cmd.Run != nil {
+		cmd.Run = o.writeFile(cmd.Run)
+	}
 	if err := cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
-		return []string{"json", "jsonpath=", "nvp", "pv", "raw", "table", "wide"}, cobra.ShellCompDirectiveNoFileComp
+		return []string{"csv", "custom-columns=", "custom-columns-file=", "html", "json", "jsonpath=", "markdown", "ndjson", "nvp", "pv", "raw", "table", "tui", "wide", "yaml"}, cobra.ShellCompDirectiveNoFileComp
 	}); err != nil {
 		fatal("Error in AddOutputFlags", 1)
 	}
 }
 
+// AddOutputFormatFlag extends the passed command only with the flag --output,
+// e.g. for commands printing tables besides other output
+func (o *OutputConf) AddOutputFormatFlag(cmd *cobra.Command) {
+	o.cmd = cmd
+	cmd.Flags().StringVarP(&o.Output, "output", "o", o.Default, "Output format (csv|html|json|markdown|ndjson|nvp|pv|raw|table|wide|yaml)")
+	if err := cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"csv", "html", "json", "markdown", "ndjson", "nvp", "pv", "raw", "table", "wide", "yaml"}, cobra.ShellCompDirectiveNoFileComp
+	}); err != nil {
+		fatal("Error in AddOutputFormatFlag", 1)
+	}
+}
+
 // AddOutputFlags extends the passed command with flags for output
 func (o *OutputConf) AddOutputFlagsPaging(cmd *cobra.Command) {
 	o.AddOutputFlags(cmd)
-	flags := cmd.PersistentFlags()
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
 	flags.BoolVar(&o.Flush, "flush", true, "Flush each response to output (enabled by default)")
 	flags.BoolVar(&o.Paging, "paging", true, "Enable paging (enabled by default)")
+	addOutputFlags(cmd, flags)
 }
 
 // ValidateFlags checks the passed flags
 func (o *OutputConf) ValidateFlags() error {
+	if o.OutputFile != "" && (o.cmd == nil || !o.cmd.Flag("output").Changed) {
+		ext := strings.ToLower(filepath.Ext(o.OutputFile))
+		format, ok := extensionFormats[ext]
+		if !ok {
+			return fmt.Errorf("unknown extension of output file %s, select the format with --output", o.OutputFile)
+		}
+		o.Output = format
+	}
 	switch o.Output {
 	case "", "table":
 		o.Type = TableOut
 	case "json":
 		o.Type = JSONOut
+	case "ndjson":
+		o.Type = NDJSONOut
+	case "yaml":
+		o.Type = YAMLOut
+	case "csv":
+		o.Type = CSVOut
 	case "pv":
 		o.Type = PVOut
 	case "nvp":
@@ -135,6 +223,12 @@ func (o *OutputConf) ValidateFlags() error {
 		o.Type = RawOut
 	case "wide":
 		o.Type = WideOut
+	case "markdown", "md":
+		o.Type = MarkdownOut
+	case "html":
+		o.Type = HTMLOut
+	case "tui":
+		o.Type = TUIOut
 	default:
 		switch {
 		case strings.HasPrefix(o.Output, "table="):
@@ -147,6 +241,24 @@ func (o *OutputConf) ValidateFlags() error {
 			tp := o.Output[5:l]
 			o.transPath = util.RemoveQuotes(tp)
 			o.Type = TableOut
+		case strings.HasPrefix(o.Output, "custom-columns="):
+			cols, err := util.ParseCustomColumns(util.RemoveQuotes(o.Output[15:]))
+			if err != nil {
+				return err
+			}
+			o.custom = cols
+			o.Type = TableOut
+		case strings.HasPrefix(o.Output, "custom-columns-file="):
+			data, err := ioutil.ReadFile(util.RemoveQuotes(o.Output[20:]))
+			if err != nil {
+				return err
+			}
+			cols, err := util.ParseCustomColumnsFile(string(data))
+			if err != nil {
+				return err
+			}
+			o.custom = cols
+			o.Type = TableOut
 		case strings.HasPrefix(o.Output, "jsonpath="):
 			l := len(o.Output)
 			jp := o.Output[9:l]
@@ -156,11 +268,82 @@ func (o *OutputConf) ValidateFlags() error {
 			return fmt.Errorf("unknown output format %s", o.Output)
 		}
 	}
+	if o.Interactive {
+		switch o.Type {
+		case TableOut, WideOut, TUIOut:
+			o.Type = TUIOut
+		default:
+			return fmt.Errorf("--interactive does not support the output format %s", o.Output)
+		}
+	}
+	if o.Type == TUIOut {
+		if !util.IsTerminal(os.Stdout) {
+			return errors.New("the interactive output requires a terminal")
+		}
+		if o.Watch {
+			return errors.New("--watch is not supported by the interactive output")
+		}
+	}
+	if o.Watch {
+		switch o.Type {
+		case TableOut, WideOut, MarkdownOut, HTMLOut, CSVOut:
+		default:
+			// terminal states are only detected in table rows
+			return fmt.Errorf("--watch does not support the output format %s", o.Output)
+		}
+	}
+	if o.Summary || len(o.GroupBy) > 0 {
+		switch o.Type {
+		case TableOut, WideOut, NVPOUT, PVOut, MarkdownOut, HTMLOut, CSVOut:
+		default:
+			return errors.New("--summary and --group-by require a table format")
+		}
+	}
+	if o.OutputFile != "" {
+		if o.Type == TUIOut {
+			return errors.New("--output-file is not supported by the interactive output")
+		}
+		if o.Watch {
+			return errors.New("--output-file is not supported in watch mode")
+		}
+	}
+	return nil
+}
+
+// customize replaces the columns of the current transformer with the custom
+// columns. The preprocessing of a TableDescriptor, e.g. path and range, is
+// kept. Other transformers are replaced by a TableDescriptor with the passed
+// path.
+func (o *OutputConf) customize(path ...string) error {
+	if o.custom == nil {
+		return nil
+	}
+	var (
+		td  *util.TableDescriptor
+		err error
+	)
+	if current, ok := o.tf.(*util.TableDescriptor); ok {
+		td, err = current.WithColumns(o.custom)
+	} else {
+		td, err = util.NewColumnsDescriptor(path, o.custom)
+	}
+	if err != nil {
+		return err
+	}
+	o.tf = td
 	return nil
 }
 
 func (o *OutputConf) wide() bool {
-	return o.Type == WideOut || o.Type == NVPOUT
+	// the interactive table can be scrolled horizontally
+	if o.Type == WideOut || o.Type == NVPOUT || o.Type == TUIOut {
+		return true
+	}
+	// all columns of a table descriptor are available for the selection
+	if _, ok := o.tf.(*util.TableDescriptor); ok && len(o.Columns) > 0 {
+		return true
+	}
+	return false
 }
 
 func (o *OutputConf) streamTableHeader(w *util.RowWriter) error {
@@ -198,15 +381,40 @@ func (o *OutputConf) PrintPaged(pager *Pager) error {
 		}
 		c := util.NewJSONCursor(res.Body)
 		if o.Type == RawOut {
-			return c.PrintRaw()
+			return c.FprintRaw(o.stdout())
+		}
+		return c.FprintPretty(o.stdout())
+	case YAMLOut:
+		res, err := pager.SingleCall()
+		if err != nil {
+			return err
+		}
+		return util.NewJSONCursor(res.Body).FprintYAML(o.stdout())
+	case NDJSONOut:
+		if err := o.customize(); err != nil {
+			return err
 		}
-		return c.PrintPretty()
+		if o.tf == nil {
+			o.tf = &util.NVPTransformer{}
+		}
+		return o.PrintNDJSON(pager)
 	// table formats
-	case NVPOUT, PVOut, WideOut, TableOut:
+	case NVPOUT, PVOut, WideOut, TableOut, MarkdownOut, HTMLOut, CSVOut:
+		if err := o.customize(); err != nil {
+			return err
+		}
 		if o.tf == nil || o.Type == NVPOUT || o.Type == PVOut {
 			o.tf = &util.NVPTransformer{}
 		}
 		return o.PrintTable(pager)
+	case TUIOut:
+		if err := o.customize(); err != nil {
+			return err
+		}
+		if o.tf == nil {
+			o.tf = &util.NVPTransformer{}
+		}
+		return o.PrintInteractive(pager)
 	}
 	return nil
 }
@@ -220,53 +428,94 @@ func (o *OutputConf) PrintResponse(res *http.Response, err error) error {
 	if err != nil {
 		return err
 	}
+	return o.Print(res.Body)
+}
+
+// Print prints a JSON document, e.g. the body of a response or a local file.
+// Concatenated documents like newline delimited JSON are printed as one
+// table.
+func (o *OutputConf) Print(body io.ReadCloser) error {
 	// check transformer
+	if err := o.customize("$"); err != nil {
+		return err
+	}
 	if o.tf == nil || o.Type == NVPOUT || o.Type == PVOut {
 		o.tf = &util.NVPTransformer{}
 	}
-	// create iterator
-	defer res.Body.Close()
-	i, err := o.tf.Iterator(util.NewJSONCursor(res.Body))
-	if err != nil {
-		return err
-	}
+	defer body.Close()
+	c := util.NewJSONCursor(body)
 	// select mode
 	switch o.Type {
 	case RawOut:
-		return i.PrintRaw()
+		return c.FprintRaw(o.stdout())
 	case JSONOut:
-		return i.PrintPretty()
+		return c.FprintPretty(o.stdout())
+	case YAMLOut:
+		return c.FprintYAML(o.stdout())
+	case NDJSONOut:
+		return o.eachDocument(c, o.streamNDJSON)
 	case JSONPathOut:
-		// unmarshall complete response
-		q, err := i.Next()
+		bout := bufio.NewWriter(o.stdout())
+		defer bout.Flush()
+		enc := json.NewEncoder(bout)
+		enc.SetIndent("", "  ")
+		return o.eachDocument(c, func(i util.JSONResponse) error {
+			// unmarshall complete response
+			var v interface{}
+			if err := i.Cursor().Decode(&v); err != nil {
+				return err
+			}
+			value, err := jsonpath.Get(o.jsonPath, v)
+			if err != nil {
+				return err
+			}
+			return enc.Encode(value)
+		})
+	case NVPOUT, PVOut, WideOut, TableOut, MarkdownOut, HTMLOut, CSVOut:
+		w, err := o.getWriter()
 		if err != nil {
 			return err
 		}
-		v := q.Interface()
-		value, err := jsonpath.Get(o.jsonPath, v)
-		if err != nil {
+		if err := o.streamTableHeader(w); err != nil {
+			_ = w.Close()
 			return err
 		}
-		bout := bufio.NewWriter(os.Stdout)
-		defer bout.Flush()
-		enc := json.NewEncoder(bout)
-		enc.SetIndent("", "  ")
-		return enc.Encode(value)
-	case NVPOUT, PVOut, WideOut, TableOut:
-		w := o.getWriter()
-		defer w.Flush()
-		if err := o.streamTableHeader(w); err != nil {
+		if err := o.eachDocument(c, func(i util.JSONResponse) error {
+			return o.streamTableBody(i, w)
+		}); err != nil {
+			_ = w.Close()
 			return err
 		}
-		return o.streamTableBody(i, w)
+		return o.closeTable(w)
+	case TUIOut:
+		return o.printInteractive(c)
 	}
 	return nil
 }
 
+// eachDocument calls f with a new iterator for each document of the cursor
+func (o *OutputConf) eachDocument(c *util.JSONCursor, f func(util.JSONResponse) error) error {
+	for {
+		more, err := c.NextDocument()
+		if err != nil || !more {
+			return err
+		}
+		i, err := o.tf.Iterator(c)
+		if err != nil {
+			return err
+		}
+		if err = f(i); err != nil {
+			return err
+		}
+	}
+}
+
 // PrintTable prints out multiple JSON responses into one table
 func (o *OutputConf) PrintTable(pager *Pager) error {
-	w := o.getWriter()
-	defer w.Flush()
+	w, err := o.getWriter()
+	if err != nil {
+		return err
+	}
 	// add JSON object handler
 	pager.SetObjectHandler(func(j util.JSONResponse) error {
 		// copy a reseted cursor
@@ -291,25 +540,183 @@ func (o *OutputConf) PrintTable(pager *Pager) error {
 	})
 	// print the header
 	if err := o.streamTableHeader(w); err != nil {
+		_ = w.Close()
 		return err
 	}
 	// print the table body
+	if o.Paging {
+		err = pager.Run()
+	} else {
+		err = pager.RunOnce()
+	}
+	if err != nil {
+		_ = w.Close()
+		return err
+	}
+	return o.closeTable(w)
+}
+
+// closeTable closes the table and prints the summary table, if requested
+func (o *OutputConf) closeTable(w *util.RowWriter) error {
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if o.summary == nil {
+		return nil
+	}
+	if _, err := fmt.Fprintln(o.stdout()); err != nil {
+		return err
+	}
+	sw := o.newWriter("")
+	if err := o.summary.Write(sw); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// PrintNDJSON prints the items of multiple JSON responses as one JSON object
+// per line
+func (o *OutputConf) PrintNDJSON(pager *Pager) error {
+	pager.SetObjectHandler(func(j util.JSONResponse) error {
+		c, err := j.Cursor().New()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = c.End() }()
+		i, err := o.tf.Iterator(c)
+		if err != nil {
+			return err
+		}
+		return o.streamNDJSON(i)
+	})
 	if o.Paging {
 		return pager.Run()
 	}
 	return pager.RunOnce()
 }
 
-func (o *OutputConf) getWriter() *util.RowWriter {
-	var out io.Writer
-	if o.Truncate {
+// streamNDJSON writes each item of the iterator as JSON object in a single
+// line. Responses without a table descriptor for items are written as one
+// line.
+func (o *OutputConf) streamNDJSON(i util.JSONResponse) error {
+	bout := bufio.NewWriter(o.stdout())
+	defer bout.Flush()
+	enc := json.NewEncoder(bout)
+	enc.SetEscapeHTML(false)
+	if td, ok := o.tf.(*util.TableDescriptor); !ok || td.Iter == "value" {
+		var raw json.RawMessage
+		if err := i.Cursor().Decode(&raw); err != nil {
+			return err
+		}
+		return enc.Encode(raw)
+	}
+	if err := o.tf.Preprocess(i); err != nil {
+		return err
+	}
+	for i.More() {
+		q, err := i.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		if err = enc.Encode(q.Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// note returns the generated-at line for markup formats
+func (o *OutputConf) note() string {
+	if !o.Generated {
+		return ""
+	}
+	sandbox := "-"
+	if o.cmd != nil {
+		if f := o.cmd.Flag("sandbox"); f != nil {
+			sandbox = f.Value.String()
+		}
+	}
+	return fmt.Sprintf("Generated at %s for sandbox %s", time.Now().Format(time.RFC1123), sandbox)
+}
+
+// processor returns a RowProcessor if sorting, filtering or column selection
+// is requested
+func (o *OutputConf) processor() (*util.RowProcessor, error) {
+	p, err := util.NewRowProcessor(o.SortBy, o.Where, o.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if o.seen != nil {
+		p.SetSeen(o.seen)
+	}
+	o.summary = nil
+	if o.Summary || len(o.GroupBy) > 0 {
+		groupBy := o.GroupBy
+		var aggs []*util.SummaryDescriptor
+		if td, ok := o.tf.(*util.TableDescriptor); ok {
+			if len(groupBy) == 0 {
+				groupBy = td.GroupBy
+			}
+			aggs = td.Summary
+		}
+		if o.summary, err = util.NewSummary(groupBy, aggs); err != nil {
+			return nil, err
+		}
+		p.SetSummary(o.summary)
+	}
+	if !p.Active() {
+		return nil, nil
+	}
+	if td, ok := o.tf.(*util.TableDescriptor); ok {
+		p.SetAliases(td.Names(o.wide()))
+	}
+	return p, nil
+}
+
+func (o *OutputConf) getWriter() (*util.RowWriter, error) {
+	p, err := o.processor()
+	if err != nil {
+		return nil, err
+	}
+	w := o.newWriter(o.note()).SetProcessor(p)
+	// colors are only used for plain tables in a terminal, truncation would
+	// cut the escape sequences
+	if (o.Type == TableOut || o.Type == WideOut) && o.OutputFile == "" {
+		if td, ok := o.tf.(*util.TableDescriptor); ok && !o.Truncate && util.ColorEnabled() {
+			w.SetStyles(td.Styles(o.wide()))
+		}
+	}
+	return w, nil
+}
+
+// newWriter returns the RowWriter for the output type, note is the
+// generated-at line of markup formats
+func (o *OutputConf) newWriter(note string) *util.RowWriter {
+	stdout := o.stdout()
+	switch o.Type {
+	case MarkdownOut:
+		return util.NewMarkdownWriter(stdout, note)
+	case HTMLOut:
+		return util.NewHTMLWriter(stdout, note)
+	case CSVOut:
+		return util.NewCSVWriter(stdout)
+	}
+	out := stdout
+	if o.Truncate && o.OutputFile == "" {
 		if width, err := util.ConsoleWidth(); err == nil {
-			out = util.NewTruncateWriter(os.Stdout, width)
-		} else {
-			out = os.Stdout
+			out = util.NewTruncateWriter(stdout, width)
 		}
-	} else {
-		out = os.Stdout
 	}
 	return util.NewTableWriter(out)
 }
+
+// stdout returns the writer for the output, usually standard out
+func (o *OutputConf) stdout() io.Writer {
+	if o.out != nil {
+		return o.out
+	}
+	return os.Stdout
+}
@@ -0,0 +1,55 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// writeFile wraps the run function of a command. With --output-file the
+// output is written to a temporary file, which replaces the output file after
+// a successful run. An existing file is only overwritten with --force.
+func (o *OutputConf) writeFile(run func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if o.OutputFile == "" {
+			run(cmd, args)
+			return
+		}
+		if !o.Force {
+			if _, err := os.Stat(o.OutputFile); err == nil {
+				CheckErr(fmt.Errorf("%s already exists, use --force to overwrite it", o.OutputFile))
+			} else if !errors.Is(err, os.ErrNotExist) {
+				CheckErr(err)
+			}
+		}
+		f, err := util.CreateAtomic(o.OutputFile, 0644)
+		CheckErr(err)
+		// remove the temporary file if the command fails
+		atExit(func() { _ = f.Abort() })
+		bout := bufio.NewWriter(f)
+		o.out = bout
+		run(cmd, args)
+		o.out = nil
+		CheckErrs(bout.Flush(), f.Commit())
+	}
+}
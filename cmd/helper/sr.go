@@ -0,0 +1,56 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// ResolveSRTitle returns the $id of the Schema Registry resource with the
+// passed title. names restricts the resources, e.g. schemas, global selects
+// the resources defined by Adobe.
+func ResolveSRTitle(conf *Configuration, global bool, title string, names ...string) (string, error) {
+	return cache.NewSRTitles(conf.Authentication, global, conf.Sandboxed(), names...).Resolve(title)
+}
+
+// ValidSRTitles returns a completion function for the titles of Schema
+// Registry resources. The optional global refers to the value of the flag
+// --predefined.
+func ValidSRTitles(conf *Configuration, global *bool, names ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if err := conf.Update(cmd); err != nil {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+		g := global != nil && *global
+		titles := cache.NewSRTitles(conf.Authentication, g, conf.Sandboxed(), names...).Keys()
+		return util.Difference(titles, args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// ValidSRTitle returns a completion function for commands with a single title
+// of a Schema Registry resource, see ValidSRTitles
+func ValidSRTitle(conf *Configuration, global *bool, names ...string) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	titles := ValidSRTitles(conf, global, names...)
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return []string{}, cobra.ShellCompDirectiveNoFileComp
+		}
+		return titles(cmd, args, toComplete)
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+// transAnnotation is the prefix of the command annotations containing the
+// built-in table descriptors
+const transAnnotation = "aepctl.transformation."
+
+// Transformation is a built-in table descriptor of a command
+type Transformation struct {
+	Path string // relative path, e.g. get/query or get/sandbox/details
+	Def  string // YAML definition
+	Cmd  *cobra.Command
+	Root *util.RootConfig
+}
+
+// AddTransformation registers a built-in table descriptor for the command.
+// The name distinguishes several descriptors of one command, the default
+// descriptor has an empty name. Registered descriptors can be overridden by
+// files in the trans directory, see aepctl trans. AddOutputFlags must be
+// called first.
+func (o *OutputConf) AddTransformation(name, def string) {
+	if o.cmd.Annotations == nil {
+		o.cmd.Annotations = make(map[string]string)
+	}
+	o.cmd.Annotations[transAnnotation+name] = def
+}
+
+// transformationPath returns the relative path of the named descriptor, e.g.
+// get/query for the command aepctl get query
+func transformationPath(cmd *cobra.Command, name string) string {
+	path := strings.Fields(cmd.CommandPath())[1:]
+	if name != "" {
+		path = append(path, name)
+	}
+	return strings.Join(path, "/")
+}
+
+// Transformations returns all registered built-in table descriptors of the
+// command tree sorted by path
+func Transformations(cfg *util.RootConfig, root *cobra.Command) []*Transformation {
+	var result []*Transformation
+	var visit func(cmd *cobra.Command)
+	visit = func(cmd *cobra.Command) {
+		for key, def := range cmd.Annotations {
+			if strings.HasPrefix(key, transAnnotation) {
+				result = append(result, &Transformation{
+					Path: transformationPath(cmd, key[len(transAnnotation):]),
+					Def:  def,
+					Cmd:  cmd,
+					Root: cfg,
+				})
+			}
+		}
+		for _, c := range cmd.Commands() {
+			visit(c)
+		}
+	}
+	visit(root)
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+	return result
+}
+
+// FindTransformation returns the built-in table descriptor with the passed
+// path
+func FindTransformation(cfg *util.RootConfig, root *cobra.Command, path string) (*Transformation, error) {
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".yaml")
+	for _, t := range Transformations(cfg, root) {
+		if t.Path == path {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown transformation %s, see aepctl trans list", path)
+}
+
+// TransformationFiles returns the override files of the descriptor. The first
+// file is specific for the current configuration (--config), e.g.
+// ~/.aepctl/trans/get/query.prod.yaml, the second file is shared by all
+// configurations, e.g. ~/.aepctl/trans/get/query.yaml
+func (t *Transformation) TransformationFiles() []string {
+	base := t.Root.JoinPath("trans", filepath.FromSlash(t.Path))
+	result := make([]string, 0, 2)
+	if t.Root.Tenant != "" {
+		result = append(result, base+"."+t.Root.Tenant+".yaml")
+	}
+	return append(result, base+".yaml")
+}
+
+// Override returns the path of the first existing override file or an empty
+// string
+func (t *Transformation) Override() (string, error) {
+	for _, f := range t.TransformationFiles() {
+		if _, err := os.Stat(f); err == nil {
+			return f, nil
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+	}
+	return "", nil
+}
+
+// Load returns the definition of the override file or the built-in definition
+func (t *Transformation) Load() (string, string, error) {
+	file, err := t.Override()
+	if err != nil || file == "" {
+		return t.Def, "", err
+	}
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return "", file, err
+	}
+	return string(data), file, nil
+}
+
+// TableDescriptor returns the table descriptor of the override file or of the
+// built-in definition
+func (t *Transformation) TableDescriptor() (*util.TableDescriptor, error) {
+	yaml, file, err := t.Load()
+	if err != nil {
+		return nil, err
+	}
+	return newTableDescriptor(yaml, file)
+}
+
+// newTableDescriptor parses the definition, errors of files contain the path
+func newTableDescriptor(yaml, file string) (*util.TableDescriptor, error) {
+	td, err := util.NewTableDescriptor(yaml)
+	if err != nil && file != "" {
+		return nil, fmt.Errorf("invalid transformation %s: %v", file, err)
+	}
+	return td, err
+}
+
+// TableDescriptor returns the table descriptor for the built-in definition.
+// The definition is replaced by the file of the table= or wide= output option
+// or by an override in the trans directory.
+func (o *OutputConf) TableDescriptor(def string) (*util.TableDescriptor, error) {
+	yaml, file := def, ""
+	if o.transPath != "" {
+		data, err := ioutil.ReadFile(o.transPath)
+		if err != nil {
+			return nil, err
+		}
+		yaml, file = string(data), o.transPath
+	} else if o.cmd != nil && o.Root != nil {
+		for key, value := range o.cmd.Annotations {
+			if strings.HasPrefix(key, transAnnotation) && value == def {
+				t := &Transformation{
+					Path: transformationPath(o.cmd, key[len(transAnnotation):]),
+					Def:  def,
+					Cmd:  o.cmd,
+					Root: o.Root,
+				}
+				var err error
+				if yaml, file, err = t.Load(); err != nil {
+					return nil, err
+				}
+				break
+			}
+		}
+	}
+	return newTableDescriptor(yaml, file)
+}
@@ -0,0 +1,157 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fuxs/aepctl/ui"
+	"github.com/fuxs/aepctl/util"
+)
+
+// SetDrillDown sets the command line of the get command for the details of a
+// row in the interactive table. Arguments in curly brackets are replaced by
+// the values of the named columns, e.g. SetDrillDown("get", "query", "{ID}").
+func (o *OutputConf) SetDrillDown(args ...string) {
+	o.drill = args
+}
+
+// collector returns a RowWriter appending all rows to the passed slice
+func (o *OutputConf) collector(rows *[][]string) (*util.RowWriter, error) {
+	p, err := o.processor()
+	if err != nil {
+		return nil, err
+	}
+	return util.NewFuncWriter(func(v []string) error {
+		*rows = append(*rows, v)
+		return nil
+	}).SetProcessor(p), nil
+}
+
+// PrintInteractive shows the paged responses in an interactive table. The
+// pages are loaded on demand unless the rows must be sorted.
+func (o *OutputConf) PrintInteractive(pager *Pager) error {
+	var rows [][]string
+	w, err := o.collector(&rows)
+	if err != nil {
+		return err
+	}
+	pager.SetObjectHandler(func(j util.JSONResponse) error {
+		c, err := j.Cursor().New()
+		if err != nil {
+			return err
+		}
+		defer func() { _ = c.End() }()
+		i, err := o.tf.Iterator(c)
+		if err != nil {
+			return err
+		}
+		return o.streamTableBody(i, w)
+	})
+	if err := o.streamTableHeader(w); err != nil {
+		return err
+	}
+	more := func() bool { return o.Paging && pager.Next() }
+	if o.SortBy != "" {
+		// sorting requires all rows
+		if o.Paging {
+			err = pager.Run()
+		} else {
+			err = pager.RunOnce()
+		}
+		if err != nil {
+			return err
+		}
+		if err = w.Close(); err != nil {
+			return err
+		}
+	} else if err = pager.RunOnce(); err != nil {
+		return err
+	}
+	v := ui.NewTableViewer(rows[0], rows[1:], more())
+	v.Load = func() ([][]string, bool, error) {
+		rows = nil
+		err := pager.RunOnce()
+		return rows, more(), err
+	}
+	v.Drill = o.drillFunc(rows[0])
+	return v.Run()
+}
+
+// printInteractive shows the documents of the cursor in an interactive table
+func (o *OutputConf) printInteractive(c *util.JSONCursor) error {
+	var rows [][]string
+	w, err := o.collector(&rows)
+	if err != nil {
+		return err
+	}
+	if err := o.streamTableHeader(w); err != nil {
+		return err
+	}
+	if err := o.eachDocument(c, func(i util.JSONResponse) error {
+		return o.streamTableBody(i, w)
+	}); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	v := ui.NewTableViewer(rows[0], rows[1:], false)
+	v.Drill = o.drillFunc(rows[0])
+	return v.Run()
+}
+
+// drillFunc returns a function executing the drill down command for a row
+// and returning the decoded JSON response
+func (o *OutputConf) drillFunc(header []string) func([]string) (interface{}, error) {
+	if len(o.drill) == 0 {
+		return nil
+	}
+	var aliases []string
+	if td, ok := o.tf.(*util.TableDescriptor); ok {
+		aliases = td.Names(o.wide())
+	}
+	index := func(name string) int {
+		for i, h := range header {
+			if strings.EqualFold(h, name) {
+				return i
+			}
+		}
+		for i, a := range aliases {
+			if i < len(header) && strings.EqualFold(a, name) {
+				return i
+			}
+		}
+		return -1
+	}
+	return func(row []string) (interface{}, error) {
+		args := make([]string, 0, len(o.drill))
+		for _, arg := range o.drill {
+			if strings.HasPrefix(arg, "{") && strings.HasSuffix(arg, "}") {
+				name := arg[1 : len(arg)-1]
+				i := index(name)
+				if i < 0 || i >= len(row) {
+					return nil, fmt.Errorf("column %s is not available, select it with --columns", name)
+				}
+				arg = row[i]
+			}
+			args = append(args, arg)
+		}
+		return ExecJSON(o.cmd, args...)
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Package helper consists of helping functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package helper
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// clearScreen moves the cursor to the top left corner and clears the screen
+const clearScreen = "\x1b[H\x1b[2J"
+
+// AddWatchFlags extends the passed command with the flags --watch and
+// --interval. AddOutputFlags must be called first.
+func (o *OutputConf) AddWatchFlags(cmd *cobra.Command) {
+	flags := pflag.NewFlagSet(cmd.Name(), pflag.ContinueOnError)
+	flags.BoolVar(&o.Watch, "watch", false, "Repeat the request until a terminal state is reached")
+	flags.DurationVar(&o.Interval, "interval", 5*time.Second, "Interval between requests in watch mode")
+	addOutputFlags(cmd, flags)
+	if cmd.Run != nil {
+		cmd.Run = o.watch(cmd.Run)
+	}
+}
+
+// watch wraps the run function of a command. With --watch the function is
+// executed repeatedly until the table descriptor reports a terminal state.
+// On a terminal the output is redrawn, otherwise only new or changed rows are
+// written.
+func (o *OutputConf) watch(run func(*cobra.Command, []string)) func(*cobra.Command, []string) {
+	return func(cmd *cobra.Command, args []string) {
+		if !o.Watch {
+			run(cmd, args)
+			return
+		}
+		if o.Interval <= 0 {
+			CheckErr(errors.New("the interval must be positive"))
+		}
+		tty := util.IsTerminal(os.Stdout)
+		if !tty {
+			o.seen = make(map[string]bool)
+		}
+		title := fmt.Sprintf("Every %v: %s %s", o.Interval, cmd.CommandPath(), strings.Join(args, " "))
+		var last []byte
+		for {
+			var buf bytes.Buffer
+			o.out = &buf
+			if td, ok := o.tf.(*util.TableDescriptor); ok {
+				td.ResetTerminal()
+			}
+			run(cmd, args)
+			o.out = nil
+			if tty {
+				fmt.Printf("%s%s\t%s\n\n", clearScreen, title, time.Now().Format(time.RFC1123))
+				_, err := os.Stdout.Write(buf.Bytes())
+				CheckErr(err)
+			} else if buf.Len() > 0 && !bytes.Equal(buf.Bytes(), last) {
+				// tables contain only new or changed rows, repeated output is
+				// skipped
+				_, err := os.Stdout.Write(buf.Bytes())
+				CheckErr(err)
+				last = buf.Bytes()
+			}
+			if td, ok := o.tf.(*util.TableDescriptor); ok {
+				if done, code := td.Terminal(); done {
+					if code != 0 {
+						exit(code)
+					}
+					return
+				}
+			}
+			time.Sleep(o.Interval)
+		}
+	}
+}
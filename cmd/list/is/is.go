@@ -29,7 +29,7 @@ import (
 var nsTransformation string
 
 func NewNamespacesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	var imsOrg string
 	cmd := &cobra.Command{
 		Use:                   "namespaces",
@@ -49,6 +49,9 @@ func NewNamespacesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlags(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", nsTransformation)
+	output.SetDrillDown("get", "namespace", "{ID}")
 	flags := cmd.Flags()
 	flags.StringVar(&imsOrg, "ims-org", "", "IMS organization ID")
 	return cmd
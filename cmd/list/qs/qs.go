@@ -28,7 +28,7 @@ import (
 var qsQueriesTransformation string
 
 func NewQueriesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	params := &api.QSListQueriesParams{}
 	cmd := &cobra.Command{
 		Use:                   "queries",
@@ -46,6 +46,9 @@ func NewQueriesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", qsQueriesTransformation)
+	output.SetDrillDown("get", "query", "{ID}")
 	flags := cmd.Flags()
 	helper.AddPagingFlags(&params.PageParams, flags)
 	flags.BoolVar(&params.ExcludeSoftDeleted, "exclude-deleted", true, "exclude queries that have been soft deleted")
@@ -57,7 +60,7 @@ func NewQueriesCommand(conf *helper.Configuration) *cobra.Command {
 var qsSchedulesTransformation string
 
 func NewSchedulesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	params := &api.PageParams{}
 	cmd := &cobra.Command{
 		Use:                   "schedules",
@@ -75,6 +78,9 @@ func NewSchedulesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", qsSchedulesTransformation)
+	output.SetDrillDown("get", "schedule", "{ID}")
 	helper.AddPagingFlags(params, cmd.Flags())
 	return cmd
 }
@@ -83,7 +89,7 @@ func NewSchedulesCommand(conf *helper.Configuration) *cobra.Command {
 var qsRunsTransformation string
 
 func NewRunsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	params := &api.PageParams{}
 	cmd := &cobra.Command{
 		Use:                   "runs scheduleId",
@@ -97,12 +103,15 @@ func NewRunsCommand(conf *helper.Configuration) *cobra.Command {
 			helper.CheckErr(output.SetTransformationDesc(qsRunsTransformation))
 			req := params.Request()
 			req.SetValue("id", args[0])
+			output.SetDrillDown("get", "run", args[0], "{ID}")
 			pager := helper.NewPager(api.QSListRunsP, conf.Authentication, req).
 				OF("runsSchedules").P("start", "orderby")
 			helper.CheckErr(output.PrintPaged(pager))
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", qsRunsTransformation)
 	helper.AddPagingFlags(params, cmd.Flags())
 	return cmd
 }
@@ -111,7 +120,7 @@ func NewRunsCommand(conf *helper.Configuration) *cobra.Command {
 var qsTemplatesTransformation string
 
 func NewTemplatesCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	params := &api.PageParams{}
 	cmd := &cobra.Command{
 		Use:                   "templates",
@@ -129,6 +138,9 @@ func NewTemplatesCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", qsTemplatesTransformation)
+	output.SetDrillDown("get", "template", "{ID}")
 	helper.AddPagingFlags(params, cmd.Flags())
 	return cmd
 }
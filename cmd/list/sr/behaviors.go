@@ -27,6 +27,7 @@ func NewBehaviorsCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"behaviors",
+		"behavior",
 		"Display behaviors (Schema Registry)",
 		"long",
 		"example",
@@ -27,6 +27,7 @@ func NewClassesCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"classes",
+		"class",
 		"Display classes (Schema Registry)",
 		"long",
 		"example",
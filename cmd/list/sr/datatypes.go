@@ -27,6 +27,7 @@ func NewDataTypesCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"datatypes",
+		"datatype",
 		"Display data types (Schema Registry)",
 		"long",
 		"example",
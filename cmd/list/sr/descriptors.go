@@ -29,7 +29,7 @@ var descriptorsTransformation string
 
 // NewDescriptorsCommand creates an initialized command object
 func NewDescriptorsCommand(conf *helper.Configuration) *cobra.Command {
-	output := &helper.OutputConf{}
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.SRListDescriptorsParams{}
 	cmd := &cobra.Command{
 		Use:                   "descriptors",
@@ -48,6 +48,8 @@ func NewDescriptorsCommand(conf *helper.Configuration) *cobra.Command {
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", descriptorsTransformation)
 	helper.AddPagingFlags(&p.PageParams, cmd.Flags())
 	return cmd
 }
@@ -27,6 +27,7 @@ func NewFieldGroupsCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"fieldgroups",
+		"fieldgroup",
 		"Display field groups (Schema Registry)",
 		"long",
 		"example",
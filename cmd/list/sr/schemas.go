@@ -27,6 +27,7 @@ func NewSchemasCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"schemas",
+		"schema",
 		"Display schemas (Schema Registry)",
 		"long",
 		"example",
@@ -37,8 +37,8 @@ const (
 )
 
 // NewStatsCommand creates an initialized command object
-func newListCommand(conf *helper.Configuration, use, short, long, example string, f api.Func, o listOption) *cobra.Command {
-	output := &helper.OutputConf{}
+func newListCommand(conf *helper.Configuration, use, get, short, long, example string, f api.Func, o listOption) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
 	p := &api.SRListParams{}
 	all := false
 	var (
@@ -74,6 +74,9 @@ func newListCommand(conf *helper.Configuration, use, short, long, example string
 		},
 	}
 	output.AddOutputFlagsPaging(cmd)
+	output.AddWatchFlags(cmd)
+	output.AddTransformation("", shortTransformation)
+	output.SetDrillDown("get", get, "{ID}")
 	flags := cmd.Flags()
 	helper.AddPagingFlags(&p.PageParams, flags)
 	flags.BoolVar(&show, "show", false, "Show resource definition")
@@ -27,6 +27,7 @@ func NewUnionsCommand(conf *helper.Configuration) *cobra.Command {
 	return newListCommand(
 		conf,
 		"unions",
+		"union",
 		"Display union schemas (Schema Registry)",
 		"long",
 		"example",
@@ -0,0 +1,150 @@
+/*
+Package render contains render command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package render
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Render a local JSON document like the response of a command.
+
+	The document is read from standard in or from the file of --file. It is
+	transformed with the table descriptor of --trans, with the descriptor of a
+	command including its override (see aepctl trans list) or with one of the
+	generic transformers nvp, tree or ref. All output formats are supported, thus
+	table layouts can be developed and tested offline with saved responses.
+	The responses of list commands are pages, --items selects the items of
+	the page, e.g. queries for aepctl ls queries.`)
+	example = util.Example(`
+	aepctl ls queries -o json > queries.json
+	aepctl render --builtin ls/queries --items queries < queries.json
+	aepctl render --trans my-queries.yaml -o wide < queries.json
+	aepctl render --transformer tree -f schema.json`)
+)
+
+// transformers contains the generic transformers
+var transformers = map[string]func(path []string) helper.Transformer{
+	"nvp":  func([]string) helper.Transformer { return &util.NVPTransformer{} },
+	"tree": func(path []string) helper.Transformer { return helper.NewTreeTransformer(path...) },
+	"ref":  func(path []string) helper.Transformer { return helper.NewRefTransformer(path...) },
+}
+
+// NewCommand creates an initialized command object
+func NewCommand(cfg *util.RootConfig) *cobra.Command {
+	var (
+		transFile   string
+		builtin     string
+		transformer string
+		path        []string
+		items       []string
+		file        string
+	)
+	output := &helper.OutputConf{Root: cfg}
+	cmd := &cobra.Command{
+		Use:                   "render",
+		Short:                 "Render a local JSON document",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErrs(cfg.Configure(cmd), output.ValidateFlags())
+			n := 0
+			for _, v := range []string{transFile, builtin, transformer} {
+				if v != "" {
+					n++
+				}
+			}
+			if n > 1 {
+				helper.CheckErr(errors.New("use only one of --trans, --builtin and --transformer"))
+			}
+			switch {
+			case transFile != "":
+				data, err := ioutil.ReadFile(transFile)
+				helper.CheckErr(err)
+				td, err := util.NewTableDescriptor(string(data))
+				if err != nil {
+					helper.CheckErr(fmt.Errorf("invalid transformation %s: %v", transFile, err))
+				}
+				output.SetTransformation(td)
+			case builtin != "":
+				t, err := helper.FindTransformation(cfg, cmd.Root(), strings.Join(strings.Fields(builtin), "/"))
+				helper.CheckErr(err)
+				td, err := t.TableDescriptor()
+				helper.CheckErr(err)
+				output.SetTransformation(td)
+			case transformer != "":
+				f, ok := transformers[transformer]
+				if !ok {
+					helper.CheckErr(fmt.Errorf("unknown transformer %s, use nvp, tree or ref", transformer))
+				}
+				output.SetTransformation(f(path))
+			}
+			var in io.ReadCloser = os.Stdin
+			if file != "" && file != "-" {
+				f, err := os.Open(file)
+				helper.CheckErr(err)
+				in = f
+			}
+			if len(items) == 0 {
+				helper.CheckErr(output.Print(in))
+				return
+			}
+			// the document is a page of a list command
+			pager := helper.NewPager(func(context.Context, *api.AuthenticationConfig, *api.Request) (*http.Response, error) {
+				return &http.Response{StatusCode: http.StatusOK, Body: in}, nil
+			}, nil).OF(items...)
+			helper.CheckErr(output.PrintPaged(pager))
+		},
+	}
+	output.AddOutputFlags(cmd)
+	flags := cmd.Flags()
+	flags.StringVar(&transFile, "trans", "", "Table descriptor file in YAML")
+	flags.StringVar(&builtin, "builtin", "", "Table descriptor of a command or its override, e.g. ls/queries")
+	flags.StringVar(&transformer, "transformer", "", "Generic transformer (nvp|tree|ref)")
+	flags.StringSliceVar(&path, "path", []string{"$"}, "Path of the tree and ref transformer, $ is the document")
+	flags.StringSliceVar(&items, "items", nil, "Path of the items in a page of a list command, e.g. queries")
+	flags.StringVarP(&file, "file", "f", "", "JSON file, standard in is the default")
+	helper.CheckErrs(
+		cmd.RegisterFlagCompletionFunc("builtin", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			ts := helper.Transformations(cfg, cmd.Root())
+			result := make([]string, len(ts))
+			for i, t := range ts {
+				result[i] = t.Path
+			}
+			return result, cobra.ShellCompDirectiveNoFileComp
+		}),
+		cmd.RegisterFlagCompletionFunc("transformer", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return []string{"nvp", "ref", "tree"}, cobra.ShellCompDirectiveNoFileComp
+		}),
+	)
+	return cmd
+}
@@ -0,0 +1,287 @@
+/*
+Package sr contains Schema Registry related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package sr
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/util"
+)
+
+// accept requests the complete resources with direct references
+const accept = "application/vnd.adobe.xed+json; version=1"
+
+// edge kinds, references have no kind
+const (
+	relationship = "relationship"
+	member       = "member"
+)
+
+// kinds are the resources of the Schema Registry with references
+var kinds = []string{"classes", "datatypes", "fieldgroups", "schemas"}
+
+// node is a Schema Registry resource
+type node struct {
+	ID     string
+	Title  string
+	Kind   string
+	Global bool
+}
+
+// graph contains the resources of the Schema Registry and their dependencies
+type graph struct {
+	auth  *api.AuthenticationConfig
+	nodes map[string]*node
+	// alt maps the meta:altId of the resources to the $id
+	alt map[string]string
+	// out maps the resources to their dependencies and the edge kind
+	out map[string]map[string]string
+	// in maps the resources to the dependent resources and the edge kind
+	in     map[string]map[string]string
+	reach  map[string]map[string]bool
+	global bool
+}
+
+// newGraph creates an initialized graph with the resources of the tenant
+func newGraph(auth *api.AuthenticationConfig) (*graph, error) {
+	g := &graph{
+		auth:  auth,
+		nodes: make(map[string]*node),
+		alt:   make(map[string]string),
+		out:   make(map[string]map[string]string),
+		in:    make(map[string]map[string]string),
+	}
+	if err := g.load(false); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// loadGlobal adds the resources defined by Adobe once
+func (g *graph) loadGlobal() error {
+	if g.global {
+		return nil
+	}
+	g.global = true
+	return g.load(true)
+}
+
+// load adds the resources of the tenant or the resources defined by Adobe
+func (g *graph) load(global bool) error {
+	g.reach = nil
+	if !global {
+		// the profile-enabled schemas are members of the unions
+		obj, err := cache.NewSRCall(g.auth, cache.SRResources["unions"], false).Call()
+		if err != nil {
+			return err
+		}
+		util.NewQuery(obj).Path("results").Range(func(q *util.Query) {
+			n := g.node(q.Str("$id"))
+			n.Title, n.Kind = q.StrOrEmpty("title"), "unions"
+			g.addAlt(q)
+		})
+	}
+	for _, kind := range kinds {
+		obj, err := cache.NewSRAcceptCall(g.auth, cache.SRResources[kind], global, accept).Call()
+		if err != nil {
+			return err
+		}
+		util.NewQuery(obj).Path("results").Range(func(q *util.Query) {
+			g.add(kind, global, q)
+		})
+	}
+	if global {
+		return nil
+	}
+	return g.loadDescriptors()
+}
+
+// loadDescriptors adds the relationships of the tenant
+func (g *graph) loadDescriptors() error {
+	items, err := cache.SRDescriptors(g.auth, "")
+	if err != nil {
+		return err
+	}
+	for _, d := range items {
+		source, dest := d.StrOrEmpty("xdm:sourceSchema"), d.StrOrEmpty("xdm:destinationSchema")
+		if source != "" && dest != "" && source != dest {
+			g.link(source, dest, relationship)
+		}
+	}
+	return nil
+}
+
+// node returns the resource with the passed id, unknown resources are added
+func (g *graph) node(id string) *node {
+	n, ok := g.nodes[id]
+	if !ok {
+		n = &node{ID: id}
+		g.nodes[id] = n
+	}
+	return n
+}
+
+// resolve returns the $id of the resource with the passed meta:altId, other
+// ids are returned unchanged
+func (g *graph) resolve(id string) string {
+	if result, ok := g.alt[id]; ok {
+		return result
+	}
+	return id
+}
+
+// addAlt maps the meta:altId of the resource to the $id
+func (g *graph) addAlt(q *util.Query) {
+	if alt := q.StrOrEmpty("meta:altId"); alt != "" {
+		g.alt[alt] = q.Str("$id")
+	}
+}
+
+// add adds the resource and its references
+func (g *graph) add(kind string, global bool, q *util.Query) {
+	id := q.Str("$id")
+	n := g.node(id)
+	n.Title, n.Kind, n.Global = q.StrOrEmpty("title"), kind, global
+	g.addAlt(q)
+	refs := make(map[string]bool)
+	collectRefs(q.Interface(), refs)
+	for _, ref := range q.Path("meta:extends").Strings() {
+		refs[ref] = true
+	}
+	delete(refs, id)
+	for ref := range refs {
+		g.link(id, ref, "")
+	}
+	if class := q.StrOrEmpty("meta:class"); kind == "schemas" && class != "" {
+		for _, tag := range q.Path("meta:immutableTags").Strings() {
+			if tag == "union" {
+				g.link(class+"__union", id, member)
+			}
+		}
+	}
+}
+
+// collectRefs adds the values of all $ref attributes, local references are
+// ignored
+func collectRefs(obj interface{}, refs map[string]bool) {
+	switch v := obj.(type) {
+	case map[string]interface{}:
+		for key, value := range v {
+			if ref, ok := value.(string); ok && key == "$ref" && !strings.HasPrefix(ref, "#") {
+				refs[ref] = true
+				continue
+			}
+			collectRefs(value, refs)
+		}
+	case []interface{}:
+		for _, value := range v {
+			collectRefs(value, refs)
+		}
+	}
+}
+
+// link adds a dependency of from to to
+func (g *graph) link(from, to, kind string) {
+	g.node(from)
+	g.node(to)
+	if g.out[from] == nil {
+		g.out[from] = make(map[string]string)
+	}
+	if g.in[to] == nil {
+		g.in[to] = make(map[string]string)
+	}
+	g.out[from][to] = kind
+	g.in[to][from] = kind
+}
+
+// reachable returns all resources reachable by references, e.g. meta:extends
+// lists the dependencies of the dependencies
+func (g *graph) reachable(id string) map[string]bool {
+	if g.reach == nil {
+		g.reach = make(map[string]map[string]bool)
+	}
+	if result, ok := g.reach[id]; ok {
+		return result
+	}
+	result := make(map[string]bool)
+	// guards against cycles
+	g.reach[id] = result
+	for to, kind := range g.out[id] {
+		if kind == "" {
+			result[to] = true
+			for r := range g.reachable(to) {
+				result[r] = true
+			}
+		}
+	}
+	return result
+}
+
+// deps returns the direct dependencies of the resource. References which are
+// dependencies of other references are omitted.
+func (g *graph) deps(id string) map[string]string {
+	result := make(map[string]string)
+	for to, kind := range g.out[id] {
+		if kind == "" {
+			indirect := false
+			for other, okind := range g.out[id] {
+				if other != to && okind == "" && g.reachable(other)[to] {
+					indirect = true
+					break
+				}
+			}
+			if indirect {
+				continue
+			}
+		}
+		result[to] = kind
+	}
+	return result
+}
+
+// rdeps returns the resources directly depending on the resource
+func (g *graph) rdeps(id string) map[string]string {
+	result := make(map[string]string)
+	for from, kind := range g.in[id] {
+		if _, ok := g.deps(from)[id]; ok {
+			result[from] = kind
+		}
+	}
+	return result
+}
+
+// sorted returns the ids ordered by kind and title
+func (g *graph) sorted(edges map[string]string) []string {
+	result := make([]string, 0, len(edges))
+	for id := range edges {
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		a, b := g.nodes[result[i]], g.nodes[result[j]]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.ID < b.ID
+	})
+	return result
+}
@@ -0,0 +1,93 @@
+/*
+Package sr contains Schema Registry related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package sr
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+)
+
+// datasetLimit is the page size of the Catalog Service
+const datasetLimit = 100
+
+// dataset is a dataset of an affected schema
+type dataset struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Schema   string `json:"schema"`
+	SchemaID string `json:"schemaId"`
+	Profile  string `json:"profile"`
+}
+
+// affected returns the datasets of the schemas in ids
+func affected(conf *helper.Configuration, g *graph, ids []string) ([]*dataset, error) {
+	schemas := make(map[string]bool)
+	for _, id := range ids {
+		if g.nodes[id].Kind == "schemas" {
+			schemas[id] = true
+		}
+	}
+	result := []*dataset{}
+	for start := 0; ; start += datasetLimit {
+		req := api.NewRequest(
+			"limit", strconv.Itoa(datasetLimit),
+			"start", strconv.Itoa(start),
+			"properties", "name,schemaRef,tags",
+		)
+		res, err := api.HandleStatusCode(api.CatalogGetDatasetsP(context.Background(), conf.Authentication, req))
+		if err != nil {
+			return nil, err
+		}
+		page := make(map[string]interface{})
+		err = json.NewDecoder(res.Body).Decode(&page)
+		res.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for id, obj := range page {
+			q := util.NewQuery(obj)
+			schemaID := q.StrOrEmpty("schemaRef", "id")
+			if !schemas[schemaID] {
+				continue
+			}
+			profile := "disabled"
+			for _, tag := range q.Path("tags", "unifiedProfile").Strings() {
+				if tag == "enabled:true" {
+					profile = "enabled"
+				}
+			}
+			result = append(result, &dataset{
+				ID:       id,
+				Name:     q.StrOrEmpty("name"),
+				Schema:   g.nodes[schemaID].Title,
+				SchemaID: schemaID,
+				Profile:  profile,
+			})
+		}
+		if len(page) < datasetLimit {
+			break
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
@@ -0,0 +1,187 @@
+/*
+Package sr contains Schema Registry related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package sr
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// next returns the neighbours of a resource, either deps or rdeps
+type next func(id string) map[string]string
+
+// kindNames maps the resource kinds to the displayed names
+var kindNames = map[string]string{
+	"classes":     "class",
+	"datatypes":   "datatype",
+	"fieldgroups": "fieldgroup",
+	"schemas":     "schema",
+	"unions":      "union",
+}
+
+// kindName returns the displayed name of the resource kind
+func kindName(kind string) string {
+	if name, ok := kindNames[kind]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// walk returns the resource and all resources reachable with next
+func (g *graph) walk(id string, n next) []string {
+	seen := map[string]bool{id: true}
+	result := []string{id}
+	for i := 0; i < len(result); i++ {
+		for _, other := range g.sorted(n(result[i])) {
+			if !seen[other] {
+				seen[other] = true
+				result = append(result, other)
+			}
+		}
+	}
+	return result
+}
+
+// label returns the title, kind and id of the resource
+func (g *graph) label(id string) string {
+	n := g.nodes[id]
+	if n.Title == "" {
+		return fmt.Sprintf("[%s] %s", kindName(n.Kind), id)
+	}
+	return fmt.Sprintf("%s [%s] %s", n.Title, kindName(n.Kind), id)
+}
+
+// writeTree writes the resources reachable with next as tree. Resources
+// are expanded only once.
+func (g *graph) writeTree(w io.Writer, id string, n next) error {
+	if _, err := fmt.Fprintln(w, g.label(id)); err != nil {
+		return err
+	}
+	return g.writeChildren(w, id, "", n, map[string]bool{id: true})
+}
+
+func (g *graph) writeChildren(w io.Writer, id, prefix string, n next, seen map[string]bool) error {
+	edges := n(id)
+	children := g.sorted(edges)
+	for i, child := range children {
+		branch, indent := "├── ", "│   "
+		if i == len(children)-1 {
+			branch, indent = "└── ", "    "
+		}
+		label := g.label(child)
+		if kind := edges[child]; kind != "" {
+			label = kind + ": " + label
+		}
+		if seen[child] {
+			label += " (see above)"
+		}
+		if _, err := fmt.Fprintln(w, prefix+branch+label); err != nil {
+			return err
+		}
+		if !seen[child] {
+			seen[child] = true
+			if err := g.writeChildren(w, child, prefix+indent, n, seen); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// edge is a dependency between two resources
+type edge struct {
+	from, to, kind string
+}
+
+// edges returns the dependencies between the resources
+func (g *graph) edges(ids []string) []edge {
+	in := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		in[id] = true
+	}
+	var result []edge
+	for _, from := range ids {
+		for to, kind := range g.deps(from) {
+			if in[to] {
+				result = append(result, edge{from: from, to: to, kind: kind})
+			}
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].from != result[j].from {
+			return result[i].from < result[j].from
+		}
+		return result[i].to < result[j].to
+	})
+	return result
+}
+
+// writeDOT writes the resources and their dependencies in the DOT language of
+// Graphviz
+func (g *graph) writeDOT(w io.Writer, ids []string) error {
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, id := range ids {
+		n := g.nodes[id]
+		title := n.Title
+		if title == "" {
+			title = id
+		}
+		fmt.Fprintf(&sb, "  %q [label=%q];\n", id, title+"\n"+kindName(n.Kind))
+	}
+	for _, e := range g.edges(ids) {
+		if e.kind == "" {
+			fmt.Fprintf(&sb, "  %q -> %q;\n", e.from, e.to)
+		} else {
+			fmt.Fprintf(&sb, "  %q -> %q [style=dashed, label=%q];\n", e.from, e.to, e.kind)
+		}
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaidEscape replaces the characters with a special meaning in labels
+var mermaidEscape = strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;")
+
+// writeMermaid writes the resources and their dependencies as Mermaid
+// flowchart
+func (g *graph) writeMermaid(w io.Writer, ids []string) error {
+	var sb strings.Builder
+	names := make(map[string]string, len(ids))
+	sb.WriteString("flowchart LR\n")
+	for i, id := range ids {
+		n := g.nodes[id]
+		names[id] = fmt.Sprintf("n%d", i)
+		title := n.Title
+		if title == "" {
+			title = id
+		}
+		fmt.Fprintf(&sb, "  %s[\"%s<br/>%s\"]\n", names[id], mermaidEscape.Replace(title), kindName(n.Kind))
+	}
+	for _, e := range g.edges(ids) {
+		if e.kind == "" {
+			fmt.Fprintf(&sb, "  %s --> %s\n", names[e.from], names[e.to])
+		} else {
+			fmt.Fprintf(&sb, "  %s -. %s .-> %s\n", names[e.from], e.kind, names[e.to])
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
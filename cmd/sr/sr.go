@@ -0,0 +1,152 @@
+/*
+Package sr contains Schema Registry related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package sr
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+//go:embed trans/impact.yaml
+var impactTransformation string
+
+var (
+	depsLong = util.LongDesc(`
+	Display the resources used by a Schema Registry resource.
+
+	The dependencies are the references in allOf, $ref and meta:extends and the
+	relationship descriptors. The output is a tree, a DOT graph for Graphviz or
+	a Mermaid flowchart.`)
+	depsExample = util.Example(`
+	aepctl sr deps "Loyalty Members"
+	aepctl sr deps "Loyalty Members" --format dot | dot -Tsvg > deps.svg`)
+	rdepsLong = util.LongDesc(`
+	Display the resources using a Schema Registry resource, e.g. the schemas and
+	profile-enabled unions depending on a field group.
+
+	With --impact the datasets of the affected schemas are listed.`)
+	rdepsExample = util.Example(`
+	aepctl sr rdeps "Loyalty Details"
+	aepctl sr rdeps "Loyalty Details" --format mermaid
+	aepctl sr rdeps "Loyalty Details" --impact`)
+)
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "sr",
+		Short:                 "Analyze the Schema Registry",
+		DisableFlagsInUseLine: true,
+	}
+	conf.AddAuthenticationFlags(cmd)
+	cmd.AddCommand(newDepsCommand(conf, false))
+	cmd.AddCommand(newDepsCommand(conf, true))
+	return cmd
+}
+
+// newDepsCommand creates the deps or with reverse the rdeps command
+func newDepsCommand(conf *helper.Configuration, reverse bool) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
+	var (
+		format string
+		global bool
+		impact bool
+	)
+	cmd := &cobra.Command{
+		Use:                   "deps ID",
+		Short:                 "Display the dependencies of a resource",
+		Long:                  depsLong,
+		Example:               depsExample,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgsFunction:     helper.ValidSRTitle(conf, &global),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
+			if format != "tree" && format != "dot" && format != "mermaid" {
+				helper.CheckErr(fmt.Errorf("unknown format %s, use tree, dot or mermaid", format))
+			}
+			id, err := helper.ResolveSRTitle(conf, global, args[0])
+			helper.CheckErr(err)
+			g, err := newGraph(conf.Authentication)
+			helper.CheckErr(err)
+			n := g.deps
+			if reverse {
+				n = g.rdeps
+			}
+			// the resources defined by Adobe are loaded only if required
+			if node, ok := g.nodes[g.resolve(id)]; !ok || node.Kind == "" || (reverse && node.Global) {
+				helper.CheckErr(g.loadGlobal())
+			}
+			id = g.resolve(id)
+			if node, ok := g.nodes[id]; !ok || node.Kind == "" {
+				helper.CheckErr(fmt.Errorf("unknown resource %s", id))
+			}
+			ids := g.walk(id, n)
+			if !reverse {
+				for _, other := range ids {
+					if g.nodes[other].Kind == "" {
+						helper.CheckErr(g.loadGlobal())
+						ids = g.walk(id, n)
+						break
+					}
+				}
+			}
+			if impact {
+				helper.CheckErr(output.SetTransformationDesc(impactTransformation))
+				datasets, err := affected(conf, g, ids)
+				helper.CheckErr(err)
+				data, err := json.Marshal(map[string]interface{}{"items": datasets})
+				helper.CheckErr(err)
+				helper.CheckErr(output.Print(ioutil.NopCloser(bytes.NewReader(data))))
+				return
+			}
+			switch format {
+			case "dot":
+				helper.CheckErr(g.writeDOT(os.Stdout, ids))
+			case "mermaid":
+				helper.CheckErr(g.writeMermaid(os.Stdout, ids))
+			default:
+				helper.CheckErr(g.writeTree(os.Stdout, id, n))
+			}
+		},
+	}
+	if reverse {
+		cmd.Use = "rdeps ID"
+		cmd.Short = "Display the resources depending on a resource"
+		cmd.Long = rdepsLong
+		cmd.Example = rdepsExample
+		// the output format applies only to the table of --impact
+		output.AddOutputFormatFlag(cmd)
+		output.AddTransformation("", impactTransformation)
+		cmd.Flags().BoolVar(&impact, "impact", false, "list the datasets of the affected schemas")
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&format, "format", "tree", "output format (tree|dot|mermaid)")
+	flags.BoolVar(&global, "predefined", false, "resolve the titles of resources defined by Adobe")
+	helper.CheckErr(cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{"tree", "dot", "mermaid"}, cobra.ShellCompDirectiveNoFileComp
+	}))
+	return cmd
+}
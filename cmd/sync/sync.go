@@ -0,0 +1,97 @@
+/*
+Package sync contains sync command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package sync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/store"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Pull the metadata of the current sandbox into the local store.
+
+	The store contains the resources of the Schema Registry (sr), datasets and
+	batches (catalog), identity namespaces, query templates and schedules (qs)
+	and the Offer Decisioning objects (od). Schema Registry resources are only
+	requested if their version has changed, batches only if they were created
+	since the last sync. The first sync of batches is limited with
+	--batches-since.
+
+	The global flag --offline answers all get and list requests with the local
+	store.`)
+	example = util.Example(`
+	aepctl sync
+	aepctl sync sr namespaces
+	aepctl ls schemas --offline`)
+)
+
+// NewCommand creates an initialized command object
+func NewCommand(conf *helper.Configuration) *cobra.Command {
+	ac := cache.NewAutoContainer(conf.Authentication, conf)
+	var since time.Duration
+	cmd := &cobra.Command{
+		Use:                   "sync [RESOURCE...]",
+		Short:                 "Pull metadata into the local store for the offline mode",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return util.Difference(store.Names(), args), cobra.ShellCompDirectiveNoFileComp
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(conf.Validate(cmd))
+			if conf.Authentication.Offline {
+				helper.CheckErr(errors.New("aepctl sync cannot be executed in offline mode"))
+			}
+			resources, ok := store.Select(args...)
+			if !ok {
+				helper.CheckErr(fmt.Errorf("unknown resource, use one of %s", strings.Join(store.Names(), ", ")))
+			}
+			s := store.New(conf.Sandboxed())
+			c := &store.Client{
+				Auth:         conf.Authentication,
+				Context:      context.Background(),
+				ContainerID:  ac.Get,
+				BatchesSince: since,
+			}
+			for _, r := range resources {
+				started := time.Now()
+				snapshot, err := c.Sync(s, r)
+				helper.CheckErr(err)
+				if r.Raw {
+					fmt.Printf("%-12s synced in %v\n", r.Name, time.Since(started).Round(time.Millisecond))
+				} else {
+					fmt.Printf("%-12s %d items in %v\n", r.Name, len(snapshot.Items), time.Since(started).Round(time.Millisecond))
+				}
+			}
+		},
+	}
+	conf.AddAuthenticationFlags(cmd)
+	helper.CheckErr(ac.AddContainerFlag(cmd))
+	cmd.Flags().DurationVar(&since, "batches-since", 30*24*time.Hour, "limits the first sync of batches to the passed duration")
+	return cmd
+}
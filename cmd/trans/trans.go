@@ -0,0 +1,207 @@
+/*
+Package trans contains trans command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package trans
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	longDesc = util.LongDesc(`
+	Manage the table descriptors of the table and wide output.
+
+	The built-in descriptors can be overridden by files in the directory
+	~/.aepctl/trans. The file name is the command path, e.g.
+	~/.aepctl/trans/get/query.yaml for aepctl get query. A file with the name
+	of the configuration, e.g. ~/.aepctl/trans/get/query.prod.yaml for
+	--config prod, has precedence.`)
+	example = util.Example(`
+	aepctl trans list
+	aepctl trans show get/query --builtin
+	aepctl trans edit get/query
+	aepctl trans reset get/query`)
+)
+
+// NewCommand creates an initialized command object
+func NewCommand(cfg *util.RootConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "trans",
+		Short:                 "Manage table descriptors",
+		Long:                  longDesc,
+		Example:               example,
+		DisableFlagsInUseLine: true,
+	}
+	cmd.AddCommand(NewListCommand(cfg))
+	cmd.AddCommand(NewShowCommand(cfg))
+	cmd.AddCommand(NewEditCommand(cfg))
+	cmd.AddCommand(NewResetCommand(cfg))
+	return cmd
+}
+
+// validPaths returns a completion function for the paths of all built-in
+// table descriptors
+func validPaths(cfg *util.RootConfig) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		ts := helper.Transformations(cfg, cmd.Root())
+		result := make([]string, len(ts))
+		for i, t := range ts {
+			result[i] = t.Path
+		}
+		return util.Difference(result, args), cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// NewListCommand creates an initialized command object
+func NewListCommand(cfg *util.RootConfig) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "list",
+		Aliases:               []string{"ls"},
+		Short:                 "List all table descriptors and their overrides",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(cfg.Configure(cmd))
+			w := util.NewTableWriter(os.Stdout)
+			helper.CheckErr(w.Write("PATH", "OVERRIDE"))
+			for _, t := range helper.Transformations(cfg, cmd.Root()) {
+				file, err := t.Override()
+				helper.CheckErr(err)
+				if file == "" {
+					file = "-"
+				}
+				helper.CheckErr(w.Write(t.Path, file))
+			}
+			helper.CheckErr(w.Flush())
+		},
+	}
+	return cmd
+}
+
+// NewShowCommand creates an initialized command object
+func NewShowCommand(cfg *util.RootConfig) *cobra.Command {
+	var builtin bool
+	cmd := &cobra.Command{
+		Use:                   "show PATH",
+		Short:                 "Display the active table descriptor",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgsFunction:     validPaths(cfg),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(cfg.Configure(cmd))
+			t, err := helper.FindTransformation(cfg, cmd.Root(), args[0])
+			helper.CheckErr(err)
+			def := t.Def
+			if !builtin {
+				def, _, err = t.Load()
+				helper.CheckErr(err)
+			}
+			fmt.Print(def)
+		},
+	}
+	cmd.Flags().BoolVar(&builtin, "builtin", false, "Display the built-in descriptor")
+	return cmd
+}
+
+// target returns the override file for the current configuration or the
+// shared override file
+func target(t *helper.Transformation, profile bool) string {
+	files := t.TransformationFiles()
+	if profile {
+		return files[0]
+	}
+	return files[len(files)-1]
+}
+
+// NewEditCommand creates an initialized command object
+func NewEditCommand(cfg *util.RootConfig) *cobra.Command {
+	var profile bool
+	cmd := &cobra.Command{
+		Use:                   "edit PATH",
+		Short:                 "Edit the override of a table descriptor with $EDITOR",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		ValidArgsFunction:     validPaths(cfg),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(cfg.Configure(cmd))
+			t, err := helper.FindTransformation(cfg, cmd.Root(), args[0])
+			helper.CheckErr(err)
+			file := target(t, profile)
+			data, err := ioutil.ReadFile(file)
+			if errors.Is(err, os.ErrNotExist) {
+				data, err = []byte(t.Def), nil
+			}
+			helper.CheckErr(err)
+			tmp, err := ioutil.TempFile("", "aepctl-trans-*.yaml")
+			helper.CheckErr(err)
+			_, err = tmp.Write(data)
+			helper.CheckErrs(err, tmp.Close())
+			helper.CheckErr(helper.EditFile(tmp.Name()))
+			edited, err := ioutil.ReadFile(tmp.Name())
+			helper.CheckErr(err)
+			if string(edited) == string(data) {
+				helper.CheckErr(os.Remove(tmp.Name()))
+				fmt.Println("Edit cancelled, no changes made.")
+				return
+			}
+			if _, err = util.NewTableDescriptor(string(edited)); err != nil {
+				helper.CheckErr(fmt.Errorf("invalid table descriptor: %v\nYour changes have been saved in %s", err, tmp.Name()))
+			}
+			helper.CheckErr(os.MkdirAll(filepath.Dir(file), 0755))
+			helper.CheckErr(ioutil.WriteFile(file, edited, 0600))
+			helper.CheckErr(os.Remove(tmp.Name()))
+			fmt.Printf("Saved %s\n", file)
+		},
+	}
+	cmd.Flags().BoolVar(&profile, "profile", false, "Edit the override of the current configuration (--config)")
+	return cmd
+}
+
+// NewResetCommand creates an initialized command object
+func NewResetCommand(cfg *util.RootConfig) *cobra.Command {
+	var profile bool
+	cmd := &cobra.Command{
+		Use:                   "reset PATH...",
+		Short:                 "Remove the override of table descriptors",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		ValidArgsFunction:     validPaths(cfg),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErr(cfg.Configure(cmd))
+			for _, path := range args {
+				t, err := helper.FindTransformation(cfg, cmd.Root(), path)
+				helper.CheckErr(err)
+				file := target(t, profile)
+				if err = os.Remove(file); errors.Is(err, os.ErrNotExist) {
+					fmt.Printf("%s has no override\n", t.Path)
+					continue
+				}
+				helper.CheckErr(err)
+				fmt.Printf("Removed %s\n", file)
+			}
+		},
+	}
+	cmd.Flags().BoolVar(&profile, "profile", false, "Remove the override of the current configuration (--config)")
+	return cmd
+}
@@ -26,10 +26,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
-func prepareUpdate(ac *cache.AutoContainer, update *od.Update, schema string) {
-	idc := cache.NewODNameToInstanceIDMem(ac, schema)
+func prepareUpdate(res *cache.ODResolver, update *od.Update, schema string) {
 	for i, name := range update.IDs {
-		update.IDs[i] = idc.Lookup(name)
+		update.IDs[i] = res.Resolve(schema, name)
 	}
 }
 
@@ -47,24 +46,30 @@ func NewUpdateCommand(conf *helper.Configuration, ac *cache.AutoContainer, use,
 			i, err := fc.Open()
 			helper.CheckErr(err)
 			if i != nil {
+				// resolve the names of all documents before patching anything
+				res := cache.NewODInstanceResolver(ac)
+				var updates []*od.Update
 				for {
 					update := &od.Update{}
 					if err := i.Load(update); err == nil {
 						if fc.IsYAML() {
-							prepareUpdate(ac, update, schema)
+							prepareUpdate(res, update, schema)
 						}
-						for _, name := range update.IDs {
-							for _, apply := range update.Apply {
-								_, err = od.Patch(context.Background(), conf.Authentication, cid, name, schema, apply)
-								helper.CheckErr(err)
-							}
-						}
-
+						updates = append(updates, update)
 					} else {
 						helper.CheckErrEOF(err)
 						break
 					}
 				}
+				helper.CheckErr(res.Err())
+				for _, update := range updates {
+					for _, name := range update.IDs {
+						for _, apply := range update.Apply {
+							_, err = od.Patch(context.Background(), conf.Authentication, cid, name, schema, apply)
+							helper.CheckErr(err)
+						}
+					}
+				}
 			}
 		},
 	}
@@ -0,0 +1,302 @@
+/*
+Package validate contains validate command related functions.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package validate
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/fuxs/aepctl/cache"
+	"github.com/fuxs/aepctl/cmd/helper"
+	"github.com/fuxs/aepctl/util"
+	"github.com/fuxs/aepctl/xdm"
+	"github.com/spf13/cobra"
+)
+
+//go:embed trans/records.yaml
+var recordsTransformation string
+
+var (
+	recordsLong = util.LongDesc(`
+	Validate JSON, NDJSON or CSV records against the fully resolved schema
+	before the ingestion.
+
+	The checks are the types, required fields, enums, formats, patterns and
+	ranges of the fields, the identity fields and the identityMap as well as
+	unknown fields outside of the tenant namespace. The schema is requested
+	once and cached for an hour, see aepctl cache ls.
+
+	The header of CSV files contains the paths of the fields separated by dots,
+	e.g. _tenant.loyalty.points. A mapping file with column names and paths
+	replaces the header. The command fails if at least one record is invalid.`)
+	recordsExample = util.Example(`
+	aepctl validate records --schema "Loyalty Members" members.json
+	aepctl validate records --schema "Loyalty Members" events.ndjson -o json
+	aepctl validate records --schema "Loyalty Members" --mapping mapping.yaml members.csv`)
+)
+
+// result is a failed check of a record
+type result struct {
+	File    string `json:"file"`
+	Record  int    `json:"record"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// NewRecordsCommand creates an initialized command object
+func NewRecordsCommand(conf *helper.Configuration) *cobra.Command {
+	output := &helper.OutputConf{Root: conf.Root}
+	var (
+		schema  string
+		global  bool
+		mapping string
+	)
+	cmd := &cobra.Command{
+		Use:                   "records --schema ID FILE...",
+		Short:                 "Validate records against a schema",
+		Long:                  recordsLong,
+		Example:               recordsExample,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			helper.CheckErrs(conf.Validate(cmd), output.ValidateFlags())
+			helper.CheckErr(output.SetTransformationDesc(recordsTransformation))
+			id, err := helper.ResolveSRTitle(conf, global, schema, "schemas")
+			helper.CheckErr(err)
+			s, err := loadSchema(conf, id, global)
+			helper.CheckErr(err)
+			var columns map[string]string
+			if mapping != "" {
+				columns, err = readMapping(mapping)
+				helper.CheckErr(err)
+			}
+			v := xdm.NewValidator(s)
+			items := []*result{}
+			records, invalid := 0, 0
+			for _, file := range args {
+				err := readRecords(file, s, columns, func(record int, doc interface{}) {
+					records++
+					violations := v.Validate(doc)
+					if len(violations) > 0 {
+						invalid++
+					}
+					for _, violation := range violations {
+						items = append(items, &result{
+							File:    file,
+							Record:  record,
+							Path:    violation.Path,
+							Message: violation.Message,
+						})
+					}
+				})
+				helper.CheckErr(err)
+			}
+			data, err := json.Marshal(map[string]interface{}{"items": items})
+			helper.CheckErr(err)
+			helper.CheckErr(output.Print(ioutil.NopCloser(bytes.NewReader(data))))
+			if invalid > 0 {
+				helper.CheckErr(fmt.Errorf("%d of %d records are invalid", invalid, records))
+			}
+			if records == 1 {
+				fmt.Fprintln(os.Stderr, "1 record is valid")
+			} else {
+				fmt.Fprintf(os.Stderr, "%d records are valid\n", records)
+			}
+		},
+	}
+	conf.AddAuthenticationFlags(cmd)
+	output.AddOutputFlags(cmd)
+	output.AddTransformation("", recordsTransformation)
+	flags := cmd.Flags()
+	flags.StringVar(&schema, "schema", "", "id or title of the schema")
+	flags.BoolVar(&global, "predefined", false, "validate against a schema defined by Adobe")
+	flags.StringVar(&mapping, "mapping", "", "JSON or YAML file mapping the CSV columns to field paths")
+	helper.CheckErr(cmd.MarkFlagRequired("schema"))
+	helper.CheckErr(cmd.RegisterFlagCompletionFunc("schema", helper.ValidSRTitles(conf, &global, "schemas")))
+	return cmd
+}
+
+// loadSchema returns the cached schema with its identities
+func loadSchema(conf *helper.Configuration, id string, global bool) (*xdm.Schema, error) {
+	doc, err := cache.NewSRSchema(conf.Authentication, id, global, conf.Sandboxed()).Doc()
+	if err != nil {
+		return nil, err
+	}
+	q := util.NewQuery(doc)
+	s, err := xdm.Parse(q.Value("schema"))
+	if err != nil {
+		return nil, err
+	}
+	s.AddIdentities(q.Path("identities").Array())
+	return s, nil
+}
+
+// readMapping reads the column to path mapping
+func readMapping(path string) (map[string]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := util.SplitDocuments(data, path)
+	if err != nil {
+		return nil, err
+	}
+	if len(docs) != 1 {
+		return nil, fmt.Errorf("%s must contain exactly one document", path)
+	}
+	var result map[string]string
+	if err = json.Unmarshal(docs[0], &result); err != nil {
+		return nil, fmt.Errorf("%s: the mapping must contain column names and paths: %v", path, err)
+	}
+	return result, nil
+}
+
+// readRecords calls f with the number and the content of each record. JSON
+// arrays contain several records.
+func readRecords(path string, s *xdm.Schema, columns map[string]string, f func(int, interface{})) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSV(path, data, s, columns, f)
+	}
+	// all other files are JSON or NDJSON
+	docs, err := util.SplitDocuments(data, path+".json")
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	record := 0
+	for _, raw := range docs {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		var doc interface{}
+		if err = dec.Decode(&doc); err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		if a, ok := doc.([]interface{}); ok {
+			for _, item := range a {
+				record++
+				f(record, item)
+			}
+			continue
+		}
+		record++
+		f(record, doc)
+	}
+	return nil
+}
+
+// readCSV converts the rows to records. The header or the mapping contains
+// the paths of the columns.
+func readCSV(path string, data []byte, s *xdm.Schema, columns map[string]string, f func(int, interface{})) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	paths := make([][]string, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if columns != nil {
+			p, ok := columns[name]
+			if !ok {
+				// unmapped columns are ignored
+				continue
+			}
+			name = p
+		}
+		paths[i] = strings.Split(name, ".")
+	}
+	for record := 1; ; record++ {
+		row, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		doc := make(map[string]interface{})
+		for i, cell := range row {
+			if i >= len(paths) || paths[i] == nil || cell == "" {
+				continue
+			}
+			set(doc, paths[i], convert(s.Find(strings.Join(paths[i], "/")), cell))
+		}
+		f(record, doc)
+	}
+}
+
+// set sets the value of the path, missing objects are created
+func set(doc map[string]interface{}, path []string, value interface{}) {
+	for _, name := range path[:len(path)-1] {
+		child, ok := doc[name].(map[string]interface{})
+		if !ok {
+			child = make(map[string]interface{})
+			doc[name] = child
+		}
+		doc = child
+	}
+	doc[path[len(path)-1]] = value
+}
+
+// convert returns the value of the CSV cell with the type of the field. Values
+// which cannot be converted are validated as strings.
+func convert(field *xdm.Field, cell string) interface{} {
+	if field == nil {
+		return cell
+	}
+	switch field.Type {
+	case xdm.Double, xdm.Long, xdm.Int, xdm.Short, xdm.Byte:
+		if _, err := strconv.ParseFloat(cell, 64); err == nil {
+			return json.Number(cell)
+		}
+	case xdm.Boolean:
+		if b, err := strconv.ParseBool(cell); err == nil {
+			return b
+		}
+	case xdm.Object, xdm.Array, xdm.Map:
+		dec := json.NewDecoder(strings.NewReader(cell))
+		dec.UseNumber()
+		var value interface{}
+		if dec.Decode(&value) == nil {
+			return value
+		}
+	}
+	return cell
+}
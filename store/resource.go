@@ -0,0 +1,226 @@
+/*
+Package store contains the local snapshot of metadata for the offline mode.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package store
+
+import (
+	"net/url"
+	"sort"
+	"strconv"
+
+	"github.com/fuxs/aepctl/api/od"
+	"github.com/fuxs/aepctl/util"
+)
+
+// Resource describes a resource of the store and the requests answered in
+// offline mode
+type Resource struct {
+	// Name is the name of the resource, e.g. schemas
+	Name string
+	// Group is the name of a group of resources, e.g. sr
+	Group string
+	// List is the URL path of list requests, {cid} matches any segment
+	List string
+	// Get is the URL path of get requests. The id follows the path or is
+	// passed with the query parameter IDParam.
+	Get     string
+	IDParam string
+	// Query contains the required query parameters, e.g. the schema
+	Query map[string]string
+	// Items is the path of the items in a list response
+	Items []string
+	// Map is true if the list response is an object with the ids as keys
+	Map bool
+	// IDs contains the paths of the ids of an item
+	IDs [][]string
+	// Single is true if get requests return a list response
+	Single bool
+	// Raw is true if the whole list response is stored
+	Raw bool
+	// Filter selects the items of list requests with query parameters
+	Filter func(q url.Values, item *util.Query) bool
+	// fetch retrieves the current snapshot, old is nil for the first sync
+	fetch func(c *Client, r *Resource, old *Snapshot) (*Snapshot, error)
+}
+
+// Resources contains all resources of the store
+var Resources = []*Resource{}
+
+// Groups maps the names of the groups to the names of their resources
+var Groups = map[string][]string{}
+
+// add registers the resources
+func add(resources ...*Resource) {
+	for _, r := range resources {
+		Resources = append(Resources, r)
+		if r.Group != "" {
+			Groups[r.Group] = append(Groups[r.Group], r.Name)
+		}
+	}
+}
+
+// Names returns the names of all resources and groups
+func Names() []string {
+	result := make([]string, 0, len(Resources)+len(Groups))
+	for _, r := range Resources {
+		result = append(result, r.Name)
+	}
+	for g := range Groups {
+		result = append(result, g)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Select returns the resources with the passed names of resources or groups.
+// Without names all resources are returned.
+func Select(names ...string) ([]*Resource, bool) {
+	if len(names) == 0 {
+		return Resources, true
+	}
+	selected := make(map[string]bool)
+	for _, name := range names {
+		if members, ok := Groups[name]; ok {
+			for _, m := range members {
+				selected[m] = true
+			}
+			continue
+		}
+		if !util.Contains(name, Names()) {
+			return nil, false
+		}
+		selected[name] = true
+	}
+	var result []*Resource
+	for _, r := range Resources {
+		if selected[r.Name] {
+			result = append(result, r)
+		}
+	}
+	return result, true
+}
+
+func init() {
+	for _, name := range []string{"classes", "datatypes", "fieldgroups", "schemas", "unions"} {
+		path := "/data/foundation/schemaregistry/tenant/" + name
+		add(&Resource{
+			Name:  name,
+			Group: "sr",
+			List:  path,
+			Get:   path,
+			Items: []string{"results"},
+			IDs:   [][]string{{"$id"}, {"meta:altId"}},
+			fetch: fetchSR,
+		})
+	}
+	add(&Resource{
+		Name:  "descriptors",
+		Group: "sr",
+		List:  "/data/foundation/schemaregistry/tenant/descriptors",
+		Get:   "/data/foundation/schemaregistry/tenant/descriptors",
+		Items: []string{"results"},
+		IDs:   [][]string{{"@id"}},
+		fetch: fetchDescriptors,
+	}, &Resource{
+		Name:   "datasets",
+		Group:  "catalog",
+		List:   "/data/foundation/catalog/datasets",
+		Map:    true,
+		IDs:    [][]string{{"id"}},
+		Filter: filterCatalog,
+		fetch:  fetchCatalog,
+	}, &Resource{
+		Name:   "batches",
+		Group:  "catalog",
+		List:   "/data/foundation/catalog/batches",
+		Map:    true,
+		IDs:    [][]string{{"id"}},
+		Filter: filterCatalog,
+		fetch:  fetchCatalog,
+	}, &Resource{
+		Name:  "namespaces",
+		List:  "/data/core/idnamespace/identities",
+		Get:   "/data/core/idnamespace/identities",
+		IDs:   [][]string{{"id"}, {"code"}},
+		fetch: fetchPages,
+	}, &Resource{
+		Name:  "templates",
+		Group: "qs",
+		List:  "/data/foundation/query/query-templates",
+		Get:   "/data/foundation/query/query-templates",
+		Items: []string{"templates"},
+		IDs:   [][]string{{"id"}},
+		fetch: fetchPages,
+	}, &Resource{
+		Name:  "schedules",
+		Group: "qs",
+		List:  "/data/foundation/query/schedules",
+		Get:   "/data/foundation/query/schedules",
+		Items: []string{"schedules"},
+		IDs:   [][]string{{"id"}},
+		fetch: fetchPages,
+	}, &Resource{
+		Name:  "containers",
+		Group: "od",
+		List:  "/data/core/xcore/",
+		Raw:   true,
+		fetch: fetchRaw,
+	})
+	for _, o := range []struct{ name, schema string }{
+		{"activities", od.ActivitySchema},
+		{"collections", od.CollectionSchema},
+		{"fallbacks", od.FallbackSchema},
+		{"offers", od.OfferSchema},
+		{"placements", od.PlacementSchema},
+		{"rules", od.RuleSchema},
+		{"tags", od.TagSchema},
+	} {
+		add(&Resource{
+			Name:    o.name,
+			Group:   "od",
+			List:    "/data/core/xcore/{cid}/queries/core/search",
+			Get:     "/data/core/xcore/{cid}/instances",
+			IDParam: "id",
+			Query:   map[string]string{"schema": o.schema},
+			Items:   []string{"_embedded", "results"},
+			IDs:     [][]string{{"_instance", "@id"}, {"instanceId"}},
+			Single:  true,
+			fetch:   fetchOD,
+		})
+	}
+}
+
+// filterCatalog supports the query parameters dataSet, createdAfter and
+// createdBefore of the Catalog Service
+func filterCatalog(q url.Values, item *util.Query) bool {
+	if ds := q.Get("dataSet"); ds != "" {
+		found := false
+		item.Path("relatedObjects").Range(func(o *util.Query) {
+			found = found || (o.Str("type") == "dataSet" && o.Str("id") == ds)
+		})
+		if !found {
+			return false
+		}
+	}
+	created, _ := strconv.ParseInt(item.Str("created"), 10, 64)
+	if after, err := strconv.ParseInt(q.Get("createdAfter"), 10, 64); err == nil && created <= after {
+		return false
+	}
+	if before, err := strconv.ParseInt(q.Get("createdBefore"), 10, 64); err == nil && created >= before {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,224 @@
+/*
+Package store contains the local snapshot of metadata for the offline mode.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/util"
+)
+
+// Snapshot is the stored state of a resource
+type Snapshot struct {
+	Synced time.Time
+	Items  []interface{} `json:",omitempty"`
+	Raw    interface{}   `json:",omitempty"`
+}
+
+// Store contains the snapshots of the resources of a sandbox
+type Store struct {
+	pp util.PathProvider
+}
+
+// New creates an initialized Store object. The snapshots are stored in the
+// directory store of the passed provider.
+func New(pp util.PathProvider) *Store {
+	return &Store{pp: pp}
+}
+
+// path is the path of a snapshot file, the store ignores the cache settings
+type path string
+
+// Path returns the path
+func (p path) Path() string {
+	return string(p)
+}
+
+// file returns the snapshot file of the resource
+func (s *Store) file(r *Resource) *util.JSONFile {
+	return util.NewJSONFile(path(s.pp.Path("store", r.Name+".json")))
+}
+
+// Load returns the snapshot of the resource or nil if it has not been
+// synchronized
+func (s *Store) Load(r *Resource) (*Snapshot, error) {
+	result := &Snapshot{}
+	if err := s.file(r).Load(result); err != nil {
+		// corrupt snapshots are removed, they have to be synchronized again
+		if os.IsNotExist(err) || errors.Is(err, util.ErrCorrupt) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return result, nil
+}
+
+// Save stores the snapshot of the resource
+func (s *Store) Save(r *Resource, snapshot *Snapshot) error {
+	return s.file(r).Save(snapshot)
+}
+
+// Handle answers the request with the matching snapshot. It is used as
+// api.AuthenticationConfig.OfflineRequest.
+func (s *Store) Handle(req *http.Request) (*http.Response, error) {
+	for _, r := range Resources {
+		list, id, ok := r.match(req.URL)
+		if !ok {
+			continue
+		}
+		snapshot, err := s.Load(r)
+		if err != nil {
+			return nil, err
+		}
+		if snapshot == nil {
+			return nil, fmt.Errorf("%s are not available offline, execute aepctl sync %s", r.Name, r.Name)
+		}
+		var body interface{}
+		switch {
+		case r.Raw:
+			body = snapshot.Raw
+		case list:
+			body = r.envelope(r.filter(req.URL.Query(), snapshot.Items))
+		default:
+			item := r.find(snapshot.Items, id)
+			switch {
+			case r.Single && item == nil:
+				body = r.envelope([]interface{}{})
+			case r.Single:
+				body = r.envelope([]interface{}{item})
+			case item == nil:
+				return nil, fmt.Errorf("%s %s is not available offline", r.Name, id)
+			default:
+				body = item
+			}
+		}
+		return response(req, body)
+	}
+	return nil, fmt.Errorf("%s is not available offline", req.URL.Path)
+}
+
+// response returns a successful http response with the JSON body
+func response(req *http.Request, body interface{}) (*http.Response, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": {"application/json"}},
+		Body:          ioutil.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+// match returns true if the URL refers to the resource. list is true for list
+// requests, otherwise id contains the requested id.
+func (r *Resource) match(u *url.URL) (list bool, id string, ok bool) {
+	q := u.Query()
+	for k, v := range r.Query {
+		if q.Get(k) != v {
+			return false, "", false
+		}
+	}
+	if matchPath(r.List, u.Path) {
+		return true, "", true
+	}
+	if r.Get == "" {
+		return false, "", false
+	}
+	if r.IDParam != "" {
+		if matchPath(r.Get, u.Path) && q.Get(r.IDParam) != "" {
+			return false, q.Get(r.IDParam), true
+		}
+		return false, "", false
+	}
+	if strings.HasPrefix(u.Path, r.Get+"/") && len(u.Path) > len(r.Get)+1 {
+		return false, u.Path[len(r.Get)+1:], true
+	}
+	return false, "", false
+}
+
+// matchPath compares the path with the pattern, {cid} matches any segment
+func matchPath(pattern, path string) bool {
+	ps, ss := strings.Split(pattern, "/"), strings.Split(path, "/")
+	if len(ps) != len(ss) {
+		return false
+	}
+	for i, p := range ps {
+		if p != ss[i] && !(p == "{cid}" && ss[i] != "") {
+			return false
+		}
+	}
+	return true
+}
+
+// find returns the item with the passed id
+func (r *Resource) find(items []interface{}, id string) interface{} {
+	for _, item := range items {
+		q := util.NewQuery(item)
+		for _, p := range r.IDs {
+			if q.Str(p...) == id {
+				return item
+			}
+		}
+	}
+	return nil
+}
+
+// filter returns the items matching the query parameters of the request
+func (r *Resource) filter(q url.Values, items []interface{}) []interface{} {
+	if r.Filter == nil {
+		return items
+	}
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if r.Filter(q, util.NewQuery(item)) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// envelope returns the list response with the passed items
+func (r *Resource) envelope(items []interface{}) interface{} {
+	var result interface{} = items
+	if r.Map {
+		m := make(map[string]interface{}, len(items))
+		for _, item := range items {
+			m[util.NewQuery(item).Str(r.IDs[0]...)] = item
+		}
+		result = m
+	}
+	for i := len(r.Items) - 1; i >= 0; i-- {
+		result = map[string]interface{}{r.Items[i]: result}
+	}
+	return result
+}
@@ -0,0 +1,292 @@
+/*
+Package store contains the local snapshot of metadata for the offline mode.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package store
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fuxs/aepctl/api/od"
+	"github.com/fuxs/aepctl/util"
+)
+
+// testDir provides the paths of a temporary directory
+type testDir string
+
+func (d testDir) Path(path ...string) string {
+	return filepath.Join(append([]string{string(d)}, path...)...)
+}
+
+// resource returns the registered resource with the passed name
+func resource(t *testing.T, name string) *Resource {
+	t.Helper()
+	for _, r := range Resources {
+		if r.Name == name {
+			return r
+		}
+	}
+	t.Fatalf("unknown resource %s", name)
+	return nil
+}
+
+// decode returns the decoded JSON document
+func decode(t *testing.T, doc string) interface{} {
+	t.Helper()
+	var result interface{}
+	if err := json.Unmarshal([]byte(doc), &result); err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// encode returns the JSON document of the value
+func encode(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern, path string
+		want          bool
+	}{
+		{"/data/foundation/catalog/datasets", "/data/foundation/catalog/datasets", true},
+		{"/data/foundation/catalog/datasets", "/data/foundation/catalog/batches", false},
+		{"/data/foundation/catalog/datasets", "/data/foundation/catalog/datasets/1", false},
+		{"/data/core/xcore/{cid}/instances", "/data/core/xcore/abc/instances", true},
+		{"/data/core/xcore/{cid}/instances", "/data/core/xcore//instances", false},
+		{"/data/core/xcore/{cid}/instances", "/data/core/xcore/abc/def/instances", false},
+		{"/data/core/xcore/{cid}/instances", "/data/core/xcore/abc/queries", false},
+		{"/data/core/xcore/", "/data/core/xcore/", true},
+		{"/data/core/xcore/", "/data/core/xcore", false},
+	}
+	for _, tt := range tests {
+		if got := matchPath(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("matchPath(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestResourceMatch(t *testing.T) {
+	offers := "?schema=" + url.QueryEscape(od.OfferSchema)
+	tests := []struct {
+		name, url string
+		list      bool
+		id        string
+		ok        bool
+	}{
+		{"schemas", "/data/foundation/schemaregistry/tenant/schemas", true, "", true},
+		{"schemas", "/data/foundation/schemaregistry/tenant/schemas?limit=10", true, "", true},
+		{"schemas", "/data/foundation/schemaregistry/tenant/schemas/_tenant.schemas.abc", false, "_tenant.schemas.abc", true},
+		{"schemas", "/data/foundation/schemaregistry/tenant/schemas/", false, "", false},
+		{"schemas", "/data/foundation/schemaregistry/tenant/classes", false, "", false},
+		{"datasets", "/data/foundation/catalog/datasets?limit=5", true, "", true},
+		// catalog resources are list only
+		{"datasets", "/data/foundation/catalog/datasets/123", false, "", false},
+		{"namespaces", "/data/core/idnamespace/identities/ECID", false, "ECID", true},
+		{"offers", "/data/core/xcore/cid/queries/core/search" + offers, true, "", true},
+		{"offers", "/data/core/xcore/cid/instances" + offers + "&id=xcore:offer:1", false, "xcore:offer:1", true},
+		// the id parameter is required
+		{"offers", "/data/core/xcore/cid/instances" + offers, false, "", false},
+		// the schema selects the resource
+		{"offers", "/data/core/xcore/cid/queries/core/search?schema=" + url.QueryEscape(od.TagSchema), false, "", false},
+		{"offers", "/data/core/xcore/cid/queries/core/search", false, "", false},
+		{"containers", "/data/core/xcore/", true, "", true},
+		{"containers", "/data/core/xcore/cid", false, "", false},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, id, ok := resource(t, tt.name).match(u)
+		if list != tt.list || id != tt.id || ok != tt.ok {
+			t.Errorf("%s match(%q) = %v, %q, %v, want %v, %q, %v", tt.name, tt.url, list, id, ok, tt.list, tt.id, tt.ok)
+		}
+	}
+}
+
+func TestEnvelope(t *testing.T) {
+	items := []interface{}{
+		decode(t, `{"id":"1","instanceId":"a"}`),
+		decode(t, `{"id":"2","instanceId":"b"}`),
+	}
+	tests := []struct {
+		name, want string
+	}{
+		{"schemas", `{"results":[{"id":"1","instanceId":"a"},{"id":"2","instanceId":"b"}]}`},
+		{"namespaces", `[{"id":"1","instanceId":"a"},{"id":"2","instanceId":"b"}]`},
+		{"datasets", `{"1":{"id":"1","instanceId":"a"},"2":{"id":"2","instanceId":"b"}}`},
+		{"offers", `{"_embedded":{"results":[{"id":"1","instanceId":"a"},{"id":"2","instanceId":"b"}]}}`},
+	}
+	for _, tt := range tests {
+		if got := encode(t, resource(t, tt.name).envelope(items)); got != tt.want {
+			t.Errorf("%s envelope() = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+	if got := encode(t, resource(t, "datasets").envelope([]interface{}{})); got != "{}" {
+		t.Errorf("datasets envelope() of no items = %s, want {}", got)
+	}
+}
+
+func TestFilterCatalog(t *testing.T) {
+	item := util.NewQuery(decode(t, `{
+		"id": "b1",
+		"created": 1000,
+		"relatedObjects": [{"type": "dataSet", "id": "ds1"}, {"type": "batch", "id": "ds2"}]
+	}`))
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"", true},
+		{"limit=10", true},
+		{"dataSet=ds1", true},
+		{"dataSet=ds2", false},
+		{"createdAfter=999", true},
+		{"createdAfter=1000", false},
+		{"createdBefore=1001", true},
+		{"createdBefore=1000", false},
+		{"dataSet=ds1&createdAfter=500&createdBefore=1500", true},
+		{"dataSet=ds1&createdAfter=1500", false},
+		{"createdAfter=yesterday", true},
+	}
+	for _, tt := range tests {
+		q, err := url.ParseQuery(tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := filterCatalog(q, item); got != tt.want {
+			t.Errorf("filterCatalog(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestStoreHandle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+	s := New(testDir(dir))
+	synced := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+	for name, items := range map[string]string{
+		"schemas":    `[{"$id":"https://ns.adobe.com/t/schemas/s1","meta:altId":"_t.schemas.s1"},{"$id":"https://ns.adobe.com/t/schemas/s2","meta:altId":"_t.schemas.s2"}]`,
+		"datasets":   `[{"id":"d1","created":1000},{"id":"d2","created":2000}]`,
+		"namespaces": `[{"id":4,"code":"ECID"},{"id":6,"code":"Email"}]`,
+		"offers":     `[{"instanceId":"i1","_instance":{"@id":"xcore:offer:1"}}]`,
+	} {
+		if err := s.Save(resource(t, name), &Snapshot{Synced: synced, Items: decode(t, items).([]interface{})}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	offers := "?schema=" + url.QueryEscape(od.OfferSchema)
+	tests := []struct {
+		url, want, err string
+	}{
+		{
+			url:  "/data/foundation/schemaregistry/tenant/schemas",
+			want: `{"results":[{"$id":"https://ns.adobe.com/t/schemas/s1","meta:altId":"_t.schemas.s1"},{"$id":"https://ns.adobe.com/t/schemas/s2","meta:altId":"_t.schemas.s2"}]}`,
+		},
+		{
+			url:  "/data/foundation/schemaregistry/tenant/schemas/" + url.PathEscape("https://ns.adobe.com/t/schemas/s2"),
+			want: `{"$id":"https://ns.adobe.com/t/schemas/s2","meta:altId":"_t.schemas.s2"}`,
+		},
+		{
+			url:  "/data/foundation/schemaregistry/tenant/schemas/_t.schemas.s1",
+			want: `{"$id":"https://ns.adobe.com/t/schemas/s1","meta:altId":"_t.schemas.s1"}`,
+		},
+		{
+			url: "/data/foundation/schemaregistry/tenant/schemas/_t.schemas.s3",
+			err: "schemas _t.schemas.s3 is not available offline",
+		},
+		{
+			url:  "/data/foundation/catalog/datasets",
+			want: `{"d1":{"created":1000,"id":"d1"},"d2":{"created":2000,"id":"d2"}}`,
+		},
+		{
+			url:  "/data/foundation/catalog/datasets?createdAfter=1500",
+			want: `{"d2":{"created":2000,"id":"d2"}}`,
+		},
+		{
+			url:  "/data/core/idnamespace/identities/Email",
+			want: `{"code":"Email","id":6}`,
+		},
+		{
+			url:  "/data/core/idnamespace/identities/4",
+			want: `{"code":"ECID","id":4}`,
+		},
+		{
+			url:  "/data/core/xcore/cid/instances" + offers + "&id=xcore:offer:1",
+			want: `{"_embedded":{"results":[{"_instance":{"@id":"xcore:offer:1"},"instanceId":"i1"}]}}`,
+		},
+		{
+			url:  "/data/core/xcore/cid/instances" + offers + "&id=i1",
+			want: `{"_embedded":{"results":[{"_instance":{"@id":"xcore:offer:1"},"instanceId":"i1"}]}}`,
+		},
+		{
+			url:  "/data/core/xcore/cid/instances" + offers + "&id=xcore:offer:2",
+			want: `{"_embedded":{"results":[]}}`,
+		},
+		{
+			url: "/data/foundation/schemaregistry/tenant/classes",
+			err: "classes are not available offline, execute aepctl sync classes",
+		},
+		{
+			url: "/data/foundation/query/queries",
+			err: "/data/foundation/query/queries is not available offline",
+		},
+	}
+	for _, tt := range tests {
+		req, err := http.NewRequest(http.MethodGet, "https://platform.adobe.io"+tt.url, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res, err := s.Handle(req)
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Handle(%q) returned error %v, want %q", tt.url, err, tt.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Handle(%q) returned unexpected error %v", tt.url, err)
+			continue
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("Handle(%q) returned status %v", tt.url, res.StatusCode)
+		}
+		data, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(data)); got != tt.want {
+			t.Errorf("Handle(%q) = %s, want %s", tt.url, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+/*
+Package store contains the local snapshot of metadata for the offline mode.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fuxs/aepctl/api"
+	"github.com/fuxs/aepctl/util"
+)
+
+// baseURL is the URL of the platform APIs
+const baseURL = "https://platform.adobe.io"
+
+// catalogLimit is the page size of the Catalog Service
+const catalogLimit = 100
+
+// Client synchronizes the resources of the store
+type Client struct {
+	Auth    *api.AuthenticationConfig
+	Context context.Context
+	// ContainerID returns the container id of the Offer Decisioning objects
+	ContainerID func() (string, error)
+	// BatchesSince limits the first sync of batches to the passed duration
+	BatchesSince time.Duration
+}
+
+// Sync retrieves the current snapshot of the resource and stores it
+func (c *Client) Sync(s *Store, r *Resource) (*Snapshot, error) {
+	old, err := s.Load(r)
+	if err != nil {
+		// broken snapshots are replaced
+		old = nil
+	}
+	started := time.Now()
+	snapshot, err := r.fetch(c, r, old)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.Synced = started
+	return snapshot, s.Save(r, snapshot)
+}
+
+// get requests the URL and returns the decoded JSON response
+func (c *Client) get(u string, header map[string]string) (interface{}, error) {
+	res, err := api.HandleStatusCode(c.Auth.FullRequestRaw(c.context(), http.MethodGet, header, nil, "%s", u))
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	var result interface{}
+	if err := json.NewDecoder(res.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (c *Client) context() context.Context {
+	if c.Context == nil {
+		return context.Background()
+	}
+	return c.Context
+}
+
+// pages requests all pages following the links _links.next.href and returns
+// the items
+func (c *Client) pages(u string, header map[string]string, items []string) ([]interface{}, error) {
+	result := []interface{}{}
+	for u != "" {
+		obj, err := c.get(u, header)
+		if err != nil {
+			return nil, err
+		}
+		q := util.NewQuery(obj)
+		q.Path(items...).Range(func(item *util.Query) {
+			result = append(result, item.Interface())
+		})
+		next := q.Path("_links", "next", "href")
+		u = ""
+		if !next.Nil() && next.String() != "" {
+			// the links of some services are relative
+			ref, err := url.Parse(next.String())
+			if err != nil {
+				return nil, err
+			}
+			base, _ := url.Parse(baseURL)
+			u = base.ResolveReference(ref).String()
+		}
+	}
+	return result, nil
+}
+
+// fetchPages requests all items of the resource
+func fetchPages(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	items, err := c.pages(baseURL+r.List, nil, r.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Items: items}, nil
+}
+
+// fetchRaw stores the whole response of the resource
+func fetchRaw(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	obj, err := c.get(baseURL+r.List+"?product=acp&property=_instance.containerType==decisioning", nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Raw: obj}, nil
+}
+
+// fetchSR lists the ids and versions of the Schema Registry resources and
+// requests only new or modified resources
+func fetchSR(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	ids, err := c.pages(baseURL+r.List, map[string]string{"Accept": "application/vnd.adobe.xed-id+json"}, r.Items)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]interface{})
+	if old != nil {
+		for _, item := range old.Items {
+			q := util.NewQuery(item)
+			known[q.Str("$id")+"@"+q.Str("version")] = item
+		}
+	}
+	header := map[string]string{"Accept": "application/vnd.adobe.xed+json; version=1"}
+	items := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		q := util.NewQuery(id)
+		if item, ok := known[q.Str("$id")+"@"+q.Str("version")]; ok {
+			items = append(items, item)
+			continue
+		}
+		item, err := c.get(baseURL+r.Get+"/"+url.PathEscape(q.Str("$id")), header)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return &Snapshot{Items: items}, nil
+}
+
+// fetchDescriptors requests all descriptors
+func fetchDescriptors(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	items, err := c.pages(baseURL+r.List, map[string]string{"Accept": "application/vnd.adobe.xdm-v2+json"}, r.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Items: items}, nil
+}
+
+// fetchCatalog requests the objects of the Catalog Service page by page. Only
+// objects created since the last sync are requested, the first sync of
+// batches is limited to BatchesSince.
+func fetchCatalog(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	byID := make(map[string]interface{})
+	var since time.Time
+	if old != nil && r.Name == "batches" {
+		for _, item := range old.Items {
+			byID[util.NewQuery(item).Str("id")] = item
+		}
+		// batches change their state after the creation
+		since = old.Synced.Add(-24 * time.Hour)
+	} else if r.Name == "batches" && c.BatchesSince > 0 {
+		since = time.Now().Add(-c.BatchesSince)
+	}
+	for start := 0; ; start += catalogLimit {
+		query := url.Values{}
+		query.Set("limit", strconv.Itoa(catalogLimit))
+		query.Set("start", strconv.Itoa(start))
+		if !since.IsZero() {
+			query.Set("createdAfter", strconv.FormatInt(since.Unix()*1000, 10))
+		}
+		obj, err := c.get(baseURL+r.List+"?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		page, _ := obj.(map[string]interface{})
+		for id, item := range page {
+			if m, ok := item.(map[string]interface{}); ok {
+				m["id"] = id
+			}
+			byID[id] = item
+		}
+		if len(page) < catalogLimit {
+			break
+		}
+	}
+	items := make([]interface{}, 0, len(byID))
+	for _, item := range byID {
+		items = append(items, item)
+	}
+	// newest first
+	sort.Slice(items, func(i, j int) bool {
+		a, _ := strconv.ParseInt(util.NewQuery(items[i]).Str("created"), 10, 64)
+		b, _ := strconv.ParseInt(util.NewQuery(items[j]).Str("created"), 10, 64)
+		return a > b
+	})
+	return &Snapshot{Items: items}, nil
+}
+
+// fetchOD requests all Offer Decisioning objects of the schema
+func fetchOD(c *Client, r *Resource, old *Snapshot) (*Snapshot, error) {
+	cid, err := c.ContainerID()
+	if err != nil {
+		return nil, err
+	}
+	path := strings.Replace(r.List, "{cid}", url.PathEscape(cid), 1)
+	items, err := c.pages(baseURL+path+"?schema="+url.QueryEscape(r.Query["schema"]), nil, r.Items)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Items: items}, nil
+}
@@ -0,0 +1,351 @@
+/*
+Package ui consists of console ui components
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// tableViewerHelp is displayed in the status line
+const tableViewerHelp = "[yellow]/[white] search  [yellow]n/N[white] next/previous  [yellow]TAB[white] column  [yellow]</>[white] width  [yellow]ENTER[white] details  [yellow]q[white] quit"
+
+// loadThreshold is the number of remaining rows which triggers the loading
+// of further rows
+const loadThreshold = 10
+
+// TableViewer shows rows in a scrollable and searchable table. Further rows
+// are loaded on demand with the Load function and the Drill function provides
+// the details of the selected row.
+type TableViewer struct {
+	// Load returns the next rows and true if more rows are available
+	Load func() ([][]string, bool, error)
+	// Drill returns the details of a row as decoded JSON
+	Drill   func(row []string) (interface{}, error)
+	app     *tview.Application
+	pages   *FocusPages
+	table   *tview.Table
+	status  *tview.TextView
+	search  *tview.InputField
+	header  []string
+	widths  []int
+	column  int
+	query   string
+	more    bool
+	loading bool
+}
+
+// NewTableViewer creates an initialized TableViewer with the header and the
+// first rows. more signals further rows.
+func NewTableViewer(header []string, rows [][]string, more bool) *TableViewer {
+	v := &TableViewer{
+		app:    tview.NewApplication(),
+		pages:  NewFocusPages(),
+		table:  tview.NewTable(),
+		status: tview.NewTextView().SetDynamicColors(true),
+		search: tview.NewInputField().SetLabel("/"),
+		header: header,
+		widths: make([]int, len(header)),
+		more:   more,
+	}
+	v.table.SetFixed(1, 0).SetSelectable(true, false).SetSeparator(' ')
+	for i, h := range header {
+		v.table.SetCell(0, i, tview.NewTableCell(tview.Escape(h)).
+			SetTextColor(tcell.ColorYellow).
+			SetAttributes(tcell.AttrBold).
+			SetSelectable(false))
+	}
+	v.addRows(rows)
+	v.table.SetSelectionChangedFunc(func(row, column int) {
+		if row >= v.table.GetRowCount()-loadThreshold {
+			v.load()
+		}
+		v.updateStatus("")
+	})
+	v.table.SetSelectedFunc(func(row, column int) {
+		v.drill(row)
+	})
+	v.table.SetInputCapture(v.handleKey)
+	v.search.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			v.query = strings.ToLower(v.search.GetText())
+			v.find(1)
+		}
+		v.pages.RemovePage("search")
+		v.pages.SwitchToPage("table")
+		v.app.SetFocus(v.table)
+	})
+	return v
+}
+
+// Run starts the application and blocks until it is stopped
+func (v *TableViewer) Run() error {
+	main := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.table, 0, 1, true).
+		AddItem(v.status, 1, 1, false)
+	v.pages.AddAndSwitchToPage("table", main, true)
+	if v.table.GetRowCount() > 1 {
+		v.table.Select(1, 0)
+	}
+	v.updateStatus("")
+	return v.app.SetRoot(v.pages, true).EnableMouse(true).Run()
+}
+
+func (v *TableViewer) addRows(rows [][]string) {
+	for _, row := range rows {
+		r := v.table.GetRowCount()
+		for i, value := range row {
+			if i >= len(v.header) {
+				break
+			}
+			v.table.SetCell(r, i, tview.NewTableCell(tview.Escape(value)).
+				SetMaxWidth(v.widths[i]).
+				SetReference(value))
+		}
+	}
+}
+
+// row returns the values of the passed row
+func (v *TableViewer) row(r int) []string {
+	result := make([]string, len(v.header))
+	for i := range result {
+		if c := v.table.GetCell(r, i); c != nil {
+			if s, ok := c.GetReference().(string); ok {
+				result[i] = s
+			}
+		}
+	}
+	return result
+}
+
+func (v *TableViewer) updateStatus(msg string) {
+	row, _ := v.table.GetSelection()
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[green]%d/%d", row, v.table.GetRowCount()-1)
+	switch {
+	case v.loading:
+		sb.WriteString(" loading…")
+	case v.more:
+		sb.WriteString("+")
+	}
+	if v.column < len(v.header) {
+		fmt.Fprintf(&sb, "[white] column [yellow]%s[white]  ", tview.Escape(v.header[v.column]))
+	}
+	if msg != "" {
+		sb.WriteString(msg)
+	} else {
+		sb.WriteString(tableViewerHelp)
+	}
+	v.status.SetText(sb.String())
+}
+
+// load fetches further rows in the background
+func (v *TableViewer) load() {
+	if !v.more || v.loading || v.Load == nil {
+		return
+	}
+	v.loading = true
+	go func() {
+		rows, more, err := v.Load()
+		v.app.QueueUpdateDraw(func() {
+			v.loading = false
+			v.more = more && err == nil
+			v.addRows(rows)
+			if err != nil {
+				ErrorDialog(v.pages, err)
+				return
+			}
+			v.updateStatus("")
+		})
+	}()
+}
+
+// resize changes the maximum width of the selected column, 0 is unlimited
+func (v *TableViewer) resize(delta int) {
+	w := v.widths[v.column]
+	if w == 0 {
+		// start with the current width of the column
+		for r := 0; r < v.table.GetRowCount(); r++ {
+			if c := v.table.GetCell(r, v.column); c != nil {
+				if l := tview.TaggedStringWidth(c.Text); l > w {
+					w = l
+				}
+			}
+		}
+	}
+	w += delta
+	if w < 1 {
+		w = 1
+	}
+	if delta == 0 {
+		w = 0
+	}
+	v.widths[v.column] = w
+	for r := 1; r < v.table.GetRowCount(); r++ {
+		if c := v.table.GetCell(r, v.column); c != nil {
+			c.SetMaxWidth(w)
+		}
+	}
+}
+
+// selectColumn moves the column selection and highlights the header
+func (v *TableViewer) selectColumn(delta int) {
+	if len(v.header) == 0 {
+		return
+	}
+	v.table.GetCell(0, v.column).SetAttributes(tcell.AttrBold)
+	v.column = (v.column + delta + len(v.header)) % len(v.header)
+	v.table.GetCell(0, v.column).SetAttributes(tcell.AttrBold | tcell.AttrUnderline)
+	v.updateStatus("")
+}
+
+// find selects the next (dir 1) or previous (dir -1) row containing the
+// search query
+func (v *TableViewer) find(dir int) {
+	if v.query == "" {
+		return
+	}
+	count := v.table.GetRowCount() - 1
+	if count < 1 {
+		return
+	}
+	start, _ := v.table.GetSelection()
+	for i := 1; i <= count; i++ {
+		r := ((start-1+dir*i)%count+count)%count + 1
+		for _, value := range v.row(r) {
+			if strings.Contains(strings.ToLower(value), v.query) {
+				v.table.Select(r, 0)
+				return
+			}
+		}
+	}
+	v.updateStatus("[red]not found: " + tview.Escape(v.query) + "[white]")
+}
+
+func (v *TableViewer) handleKey(event *tcell.EventKey) *tcell.EventKey {
+	switch event.Key() {
+	case tcell.KeyEsc:
+		v.app.Stop()
+		return nil
+	case tcell.KeyTab:
+		v.selectColumn(1)
+		return nil
+	case tcell.KeyBacktab:
+		v.selectColumn(-1)
+		return nil
+	case tcell.KeyRune:
+		switch event.Rune() {
+		case 'q':
+			v.app.Stop()
+		case '/':
+			v.search.SetText("")
+			v.pages.AddAndSwitchToPage("search", tview.NewFlex().SetDirection(tview.FlexRow).
+				AddItem(v.table, 0, 1, false).
+				AddItem(v.search, 1, 1, true), true)
+		case 'n':
+			v.find(1)
+		case 'N':
+			v.find(-1)
+		case '<':
+			v.resize(-1)
+		case '>':
+			v.resize(1)
+		case '=':
+			v.resize(0)
+		default:
+			return event
+		}
+		return nil
+	}
+	return event
+}
+
+// drill shows the details of the row in a tree
+func (v *TableViewer) drill(r int) {
+	if v.Drill == nil || r < 1 {
+		return
+	}
+	v.updateStatus("[yellow]loading details…[white]")
+	row := v.row(r)
+	go func() {
+		doc, err := v.Drill(row)
+		v.app.QueueUpdateDraw(func() {
+			v.updateStatus("")
+			if err != nil {
+				ErrorDialog(v.pages, err)
+				return
+			}
+			v.showTree(strings.Join(row, " "), doc)
+		})
+	}()
+}
+
+func (v *TableViewer) showTree(title string, doc interface{}) {
+	root := tview.NewTreeNode(tview.Escape(title)).SetColor(tcell.ColorYellow)
+	addNodes(root, doc)
+	tree := tview.NewTreeView().SetRoot(root).SetCurrentNode(root)
+	tree.SetSelectedFunc(func(node *tview.TreeNode) {
+		node.SetExpanded(!node.IsExpanded())
+	})
+	tree.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEsc || (event.Key() == tcell.KeyRune && event.Rune() == 'q') {
+			v.pages.RemovePage("tree")
+			v.pages.SwitchToPage("table")
+			v.app.SetFocus(v.table)
+			return nil
+		}
+		return event
+	})
+	help := tview.NewTextView().SetDynamicColors(true).
+		SetText("[yellow]ENTER[white] expand/collapse  [yellow]q[white] back")
+	v.pages.AddAndSwitchToPage("tree", tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(tree, 0, 1, true).
+		AddItem(help, 1, 1, false), true)
+}
+
+// addNodes adds the JSON value as children to the node
+func addNodes(node *tview.TreeNode, value interface{}) {
+	switch obj := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(obj))
+		for k := range obj {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			node.AddChild(newNode(k, obj[k]))
+		}
+	case []interface{}:
+		for i, e := range obj {
+			node.AddChild(newNode(fmt.Sprintf("[%d]", i), e))
+		}
+	}
+}
+
+func newNode(name string, value interface{}) *tview.TreeNode {
+	switch value.(type) {
+	case map[string]interface{}, []interface{}:
+		result := tview.NewTreeNode(tview.Escape(name)).SetColor(tcell.ColorGreen)
+		addNodes(result, value)
+		return result
+	}
+	return tview.NewTreeNode(tview.Escape(fmt.Sprintf("%s: %v", name, value)))
+}
@@ -0,0 +1,82 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// AtomicFile is a temporary file in the directory of the target file. Commit
+// replaces the target with the temporary file, thus the target is either
+// unchanged or completely written.
+type AtomicFile struct {
+	*os.File
+	path string
+	done bool
+}
+
+// CreateAtomic creates the temporary file for the target path with the
+// passed permissions
+func CreateAtomic(path string, perm os.FileMode) (*AtomicFile, error) {
+	dir, name := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+	f, err := ioutil.TempFile(dir, "."+name+".*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	if err = f.Chmod(perm); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	return &AtomicFile{File: f, path: path}, nil
+}
+
+// Commit syncs and closes the temporary file and renames it to the target
+// path
+func (f *AtomicFile) Commit() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	err := f.Sync()
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), f.path)
+	}
+	if err != nil {
+		_ = os.Remove(f.Name())
+	}
+	return err
+}
+
+// Abort closes and removes the temporary file, the target is not changed. It
+// does nothing after a Commit.
+func (f *AtomicFile) Abort() error {
+	if f.done {
+		return nil
+	}
+	f.done = true
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
@@ -0,0 +1,186 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+)
+
+// splitTopLevel splits the string by the separator but ignores separators in
+// parentheses
+func splitTopLevel(s string, sep rune) []string {
+	var (
+		result []string
+		depth  int
+		start  int
+	)
+	for i, r := range s {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case sep:
+			if depth == 0 {
+				result = append(result, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(result, s[start:])
+}
+
+// parseColumnPath converts a path like .request.name or $.request.name to a
+// slice of attribute names
+func parseColumnPath(p string) []string {
+	p = strings.TrimPrefix(strings.TrimSpace(p), "$")
+	p = strings.Trim(p, ".")
+	if p == "" {
+		return nil
+	}
+	return strings.Split(p, ".")
+}
+
+// parseColumnFormat sets the type, format and parameters of the column for
+// a format like localTime, localTime(2006-01-02) or map(states)
+func parseColumnFormat(c *TableColumnDescriptor, f string) error {
+	f = strings.TrimSpace(f)
+	name, params := f, ""
+	if i := strings.IndexByte(f, '('); i >= 0 {
+		if !strings.HasSuffix(f, ")") {
+			return fmt.Errorf("missing ) in format %s", f)
+		}
+		name, params = f[:i], f[i+1:len(f)-1]
+	}
+	if params != "" {
+		c.Parameters = []string{params}
+	}
+	switch name {
+	case "localTime", "relativeTime", "map", "regex":
+		c.Type = "str"
+		c.Format = name
+	case "utime", "duration", "bytes", "grouped":
+		c.Type = "num"
+		c.Format = name
+	case "count", "contains":
+		c.Type = "list"
+		c.Format = name
+	case "list", "json", "bool", "str", "num":
+		c.Type = name
+	default:
+		return fmt.Errorf("unknown format %s in column %s", name, c.Name)
+	}
+	if name == "map" || name == "contains" || name == "regex" {
+		if params == "" {
+			return fmt.Errorf("format %s requires a parameter in column %s", name, c.Name)
+		}
+	}
+	return nil
+}
+
+// newCustomColumn creates a column for the name and a specification in the
+// format path.to.field[|format]
+func newCustomColumn(name, spec string) (*TableColumnDescriptor, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, fmt.Errorf("column name is missing in %q", spec)
+	}
+	c := &TableColumnDescriptor{Name: name}
+	parts := splitTopLevel(spec, '|')
+	c.Path = parseColumnPath(parts[0])
+	for _, f := range parts[1:] {
+		if err := parseColumnFormat(c, f); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// ParseCustomColumns parses a specification in the format
+// NAME:path.to.field[|format],OTHER:other.path. Only the first colon separates
+// the name from the path, thus paths like _instance.xdm:name are supported.
+func ParseCustomColumns(spec string) ([]*TableColumnDescriptor, error) {
+	result := make([]*TableColumnDescriptor, 0, 8)
+	for _, def := range splitTopLevel(spec, ',') {
+		if strings.TrimSpace(def) == "" {
+			continue
+		}
+		i := strings.IndexByte(def, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("invalid column %q, expecting NAME:path", def)
+		}
+		c, err := newCustomColumn(def[:i], def[i+1:])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no columns defined in %q", spec)
+	}
+	return result, nil
+}
+
+// ParseCustomColumnsFile parses a template with two lines. The first line
+// contains the column names and the second line the paths, both separated by
+// whitespaces:
+//
+//	NAME         STATE  UPDATED
+//	request.name state  updated|localTime
+func ParseCustomColumnsFile(data string) ([]*TableColumnDescriptor, error) {
+	lines := make([]string, 0, 2)
+	s := bufio.NewScanner(strings.NewReader(data))
+	for s.Scan() {
+		if line := strings.TrimSpace(s.Text()); line != "" && line[0] != '#' {
+			lines = append(lines, line)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	if len(lines) != 2 {
+		return nil, fmt.Errorf("expecting two lines with names and paths but found %v", len(lines))
+	}
+	names := strings.Fields(lines[0])
+	specs := strings.Fields(lines[1])
+	if len(names) != len(specs) {
+		return nil, fmt.Errorf("found %v names but %v paths", len(names), len(specs))
+	}
+	result := make([]*TableColumnDescriptor, len(names))
+	for i, name := range names {
+		c, err := newCustomColumn(name, specs[i])
+		if err != nil {
+			return nil, err
+		}
+		result[i] = c
+	}
+	return result, nil
+}
+
+// NewColumnsDescriptor creates an initialized TableDescriptor with the passed
+// path and columns
+func NewColumnsDescriptor(path []string, cols []*TableColumnDescriptor) (*TableDescriptor, error) {
+	result := &TableDescriptor{Path: path, Columns: cols}
+	if err := result.init(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,101 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+// columns returns the columns in a compact form NAME:path|type|format|params
+func columns(cols []*TableColumnDescriptor) string {
+	result := make([]string, len(cols))
+	for i, c := range cols {
+		result[i] = c.Name + ":" + strings.Join(c.Path, ".") + "|" + c.Type + "|" + c.Format + "|" + strings.Join(c.Parameters, ",")
+	}
+	return strings.Join(result, " ")
+}
+
+func TestParseCustomColumns(t *testing.T) {
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"NAME:.name", "NAME:name|||"},
+		{"NAME:$.request.name,STATE:state", "NAME:request.name||| STATE:state|||"},
+		{"NAME:_instance.xdm:name", "NAME:_instance.xdm:name|||"},
+		{"UPDATED:updated|localTime", "UPDATED:updated|str|localTime|"},
+		{"UPDATED:updated|localTime(2006-01-02, 15:04)", "UPDATED:updated|str|localTime|2006-01-02, 15:04"},
+		{"SIZE:size|bytes, TAGS:tags|count", "SIZE:size|num|bytes| TAGS:tags|list|count|"},
+		{"STATE:state|map(states),", "STATE:state|str|map|states"},
+		{"ROOT:.", "ROOT:|||"},
+	}
+	for _, test := range tests {
+		cols, err := ParseCustomColumns(test.spec)
+		if err != nil {
+			t.Errorf("ParseCustomColumns(%q) = %v", test.spec, err)
+			continue
+		}
+		if result := columns(cols); result != test.want {
+			t.Errorf("ParseCustomColumns(%q) = %q, want %q", test.spec, result, test.want)
+		}
+	}
+}
+
+func TestParseCustomColumnsErrors(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		" , ",
+		"NAME",
+		":name",
+		"NAME:name|unknown",
+		"NAME:name|map",
+		"NAME:name|localTime(2006",
+		"NAME:name|contains()",
+	} {
+		if cols, err := ParseCustomColumns(spec); err == nil {
+			t.Errorf("ParseCustomColumns(%q) = %q, expected error", spec, columns(cols))
+		}
+	}
+}
+
+func TestParseCustomColumnsFile(t *testing.T) {
+	data := `
+# columns of the batches
+NAME         STATE  UPDATED
+request.name state  updated|localTime
+`
+	cols, err := ParseCustomColumnsFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME:request.name||| STATE:state||| UPDATED:updated|str|localTime|"
+	if result := columns(cols); result != want {
+		t.Errorf("ParseCustomColumnsFile() = %q, want %q", result, want)
+	}
+	for _, data := range []string{
+		"",
+		"NAME STATE\n",
+		"NAME STATE\nname\n",
+		"NAME\nname\nstate\n",
+		"NAME\nname|unknown\n",
+	} {
+		if cols, err := ParseCustomColumnsFile(data); err == nil {
+			t.Errorf("ParseCustomColumnsFile(%q) = %q, expected error", data, columns(cols))
+		}
+	}
+}
@@ -0,0 +1,851 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Expression is a compiled expression of a column descriptor. Expressions
+// are evaluated for each row and have no side effects, e.g.
+//
+//	coalesce(name, id)
+//	datediff(updated, created)
+//	state == 'FAILED' ? concat('! ', name) : name
+//
+// Identifiers reference attributes of the current object, $name references a
+// variable and @ the current object. Nested values are selected with a.b or
+// a['x-y'] and array elements with a[0]. Invalid operations, e.g. a division
+// by zero, result in null.
+type Expression struct {
+	src  string
+	root exprNode
+}
+
+// ParseExpression compiles the expression
+func ParseExpression(src string) (*Expression, error) {
+	p := &exprParser{src: src}
+	if err := p.tokenize(); err != nil {
+		return nil, err
+	}
+	root, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.unexpected(t)
+	}
+	return &Expression{src: src, root: root}, nil
+}
+
+// String returns the source of the expression
+func (e *Expression) String() string {
+	return e.src
+}
+
+// Eval evaluates the expression for the passed object. The result is nil, a
+// bool, a float64, a string or a JSON array or object.
+func (e *Expression) Eval(scope *Scope, q *Query) interface{} {
+	return e.root.eval(&exprContext{scope: scope, obj: q.Interface()})
+}
+
+// exprContext is the environment of an evaluation
+type exprContext struct {
+	scope *Scope
+	obj   interface{}
+}
+
+type exprNode interface {
+	eval(*exprContext) interface{}
+}
+
+type (
+	// literal value
+	exprLiteral struct{ value interface{} }
+	// attribute of the current object or @
+	exprAttr struct{ name string }
+	// variable
+	exprVar struct{ name string }
+	// member or index access
+	exprIndex struct{ obj, key exprNode }
+	// unary operator
+	exprUnary struct {
+		op string
+		x  exprNode
+	}
+	// binary operator
+	exprBinary struct {
+		op   string
+		x, y exprNode
+	}
+	// conditional operator
+	exprCond struct{ c, x, y exprNode }
+	// function call
+	exprCall struct {
+		name string
+		f    exprFunc
+		args []exprNode
+	}
+)
+
+func (n *exprLiteral) eval(*exprContext) interface{} {
+	return n.value
+}
+
+func (n *exprAttr) eval(ctx *exprContext) interface{} {
+	if n.name == "@" {
+		return ctx.obj
+	}
+	return member(ctx.obj, n.name)
+}
+
+func (n *exprVar) eval(ctx *exprContext) interface{} {
+	if ctx.scope == nil {
+		return nil
+	}
+	return ctx.scope.Get(n.name).Interface()
+}
+
+func (n *exprIndex) eval(ctx *exprContext) interface{} {
+	obj := n.obj.eval(ctx)
+	switch key := n.key.eval(ctx).(type) {
+	case string:
+		return member(obj, key)
+	case float64:
+		if a, ok := obj.([]interface{}); ok {
+			i := int(key)
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				return a[i]
+			}
+		}
+	}
+	return nil
+}
+
+// member returns the attribute of an object or nil
+func member(obj interface{}, name string) interface{} {
+	if m, ok := obj.(map[string]interface{}); ok {
+		return m[name]
+	}
+	return nil
+}
+
+func (n *exprUnary) eval(ctx *exprContext) interface{} {
+	x := n.x.eval(ctx)
+	if n.op == "!" {
+		return !truthy(x)
+	}
+	if f, ok := toNumber(x); ok {
+		return -f
+	}
+	return nil
+}
+
+func (n *exprBinary) eval(ctx *exprContext) interface{} {
+	// short circuit evaluation
+	switch n.op {
+	case "&&":
+		return truthy(n.x.eval(ctx)) && truthy(n.y.eval(ctx))
+	case "||":
+		return truthy(n.x.eval(ctx)) || truthy(n.y.eval(ctx))
+	}
+	x, y := n.x.eval(ctx), n.y.eval(ctx)
+	switch n.op {
+	case "==":
+		return compare(x, y) == 0
+	case "!=":
+		return compare(x, y) != 0
+	case "<":
+		return x != nil && y != nil && compare(x, y) < 0
+	case "<=":
+		return x != nil && y != nil && compare(x, y) <= 0
+	case ">":
+		return x != nil && y != nil && compare(x, y) > 0
+	case ">=":
+		return x != nil && y != nil && compare(x, y) >= 0
+	}
+	// + concatenates strings
+	if n.op == "+" {
+		_, xs := x.(string)
+		_, ys := y.(string)
+		if xs || ys {
+			return toString(x) + toString(y)
+		}
+	}
+	a, ok := toNumber(x)
+	if !ok {
+		return nil
+	}
+	b, ok := toNumber(y)
+	if !ok {
+		return nil
+	}
+	switch n.op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			return nil
+		}
+		return a / b
+	case "%":
+		if b == 0 {
+			return nil
+		}
+		return math.Mod(a, b)
+	}
+	return nil
+}
+
+func (n *exprCond) eval(ctx *exprContext) interface{} {
+	if truthy(n.c.eval(ctx)) {
+		return n.x.eval(ctx)
+	}
+	return n.y.eval(ctx)
+}
+
+func (n *exprCall) eval(ctx *exprContext) interface{} {
+	// if and coalesce evaluate their arguments lazily
+	switch n.name {
+	case "if":
+		if truthy(n.args[0].eval(ctx)) {
+			return n.args[1].eval(ctx)
+		}
+		if len(n.args) > 2 {
+			return n.args[2].eval(ctx)
+		}
+		return nil
+	case "coalesce":
+		for _, a := range n.args {
+			if v := a.eval(ctx); v != nil && v != "" {
+				return v
+			}
+		}
+		return nil
+	}
+	args := make([]interface{}, len(n.args))
+	for i, a := range n.args {
+		args[i] = a.eval(ctx)
+	}
+	return n.f.f(args)
+}
+
+// truthy converts the value to a boolean: null, false, 0, empty strings and
+// empty arrays are false
+func truthy(v interface{}) bool {
+	switch t := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return t
+	case float64:
+		return t != 0
+	case string:
+		return t != ""
+	case []interface{}:
+		return len(t) > 0
+	}
+	return true
+}
+
+// toNumber converts numbers, numeric strings and booleans to float64
+func toNumber(v interface{}) (float64, bool) {
+	switch t := v.(type) {
+	case float64:
+		return t, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(t), 64)
+		return f, err == nil
+	case bool:
+		if t {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// toString converts the value to a string, null is an empty string
+func toString(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case []interface{}, map[string]interface{}:
+		return NewQuery(t).Pretty()
+	}
+	return GetString(v)
+}
+
+// toTime converts RFC 3339 strings and milliseconds since epoch to time
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case float64:
+		return time.Unix(0, int64(t)*int64(time.Millisecond)), true
+	case string:
+		if r, err := time.Parse(time.RFC3339, t); err == nil {
+			return r, true
+		}
+		if f, err := strconv.ParseFloat(t, 64); err == nil {
+			return time.Unix(0, int64(f)*int64(time.Millisecond)), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// compare returns -1, 0 or 1. Numbers are compared numerically, all other
+// values as strings.
+func compare(x, y interface{}) int {
+	if x == nil || y == nil {
+		switch {
+		case x == nil && y == nil:
+			return 0
+		case x == nil:
+			return -1
+		}
+		return 1
+	}
+	if a, ok := toNumber(x); ok {
+		if b, ok := toNumber(y); ok {
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(toString(x), toString(y))
+}
+
+// exprFunc is a built-in function with the allowed number of arguments, max
+// -1 is unlimited
+type exprFunc struct {
+	min, max int
+	f        func([]interface{}) interface{}
+}
+
+// unaryString returns a function with one string argument
+func unaryString(f func(string) interface{}) exprFunc {
+	return exprFunc{1, 1, func(a []interface{}) interface{} {
+		if a[0] == nil {
+			return nil
+		}
+		return f(toString(a[0]))
+	}}
+}
+
+// unaryNumber returns a function with one numeric argument
+func unaryNumber(f func(float64) float64) exprFunc {
+	return exprFunc{1, 1, func(a []interface{}) interface{} {
+		if x, ok := toNumber(a[0]); ok {
+			return f(x)
+		}
+		return nil
+	}}
+}
+
+// exprFuncs contains all built-in functions
+var exprFuncs = map[string]exprFunc{
+	"if":       {2, 3, nil},
+	"coalesce": {1, -1, nil},
+	"concat": {1, -1, func(a []interface{}) interface{} {
+		var sb strings.Builder
+		for _, v := range a {
+			sb.WriteString(toString(v))
+		}
+		return sb.String()
+	}},
+	"upper": unaryString(func(s string) interface{} { return strings.ToUpper(s) }),
+	"lower": unaryString(func(s string) interface{} { return strings.ToLower(s) }),
+	"trim":  unaryString(func(s string) interface{} { return strings.TrimSpace(s) }),
+	"len": {1, 1, func(a []interface{}) interface{} {
+		switch t := a[0].(type) {
+		case nil:
+			return float64(0)
+		case []interface{}:
+			return float64(len(t))
+		case map[string]interface{}:
+			return float64(len(t))
+		}
+		return float64(len([]rune(toString(a[0]))))
+	}},
+	"substr": {2, 3, func(a []interface{}) interface{} {
+		r := []rune(toString(a[0]))
+		start, _ := toNumber(a[1])
+		s := clamp(int(start), len(r))
+		e := len(r)
+		if len(a) > 2 {
+			l, _ := toNumber(a[2])
+			e = clamp(s+int(l), len(r))
+		}
+		return string(r[s:e])
+	}},
+	"replace": {3, 3, func(a []interface{}) interface{} {
+		return strings.ReplaceAll(toString(a[0]), toString(a[1]), toString(a[2]))
+	}},
+	"contains": {2, 2, func(a []interface{}) interface{} {
+		if arr, ok := a[0].([]interface{}); ok {
+			for _, v := range arr {
+				if compare(v, a[1]) == 0 {
+					return true
+				}
+			}
+			return false
+		}
+		return strings.Contains(toString(a[0]), toString(a[1]))
+	}},
+	"startsWith": {2, 2, func(a []interface{}) interface{} {
+		return strings.HasPrefix(toString(a[0]), toString(a[1]))
+	}},
+	"endsWith": {2, 2, func(a []interface{}) interface{} {
+		return strings.HasSuffix(toString(a[0]), toString(a[1]))
+	}},
+	"join": {2, 2, func(a []interface{}) interface{} {
+		arr, ok := a[0].([]interface{})
+		if !ok {
+			return toString(a[0])
+		}
+		s := make([]string, len(arr))
+		for i, v := range arr {
+			s[i] = toString(v)
+		}
+		return strings.Join(s, toString(a[1]))
+	}},
+	"split": {2, 2, func(a []interface{}) interface{} {
+		if a[0] == nil {
+			return nil
+		}
+		parts := strings.Split(toString(a[0]), toString(a[1]))
+		result := make([]interface{}, len(parts))
+		for i, p := range parts {
+			result[i] = p
+		}
+		return result
+	}},
+	"number": {1, 1, func(a []interface{}) interface{} {
+		if f, ok := toNumber(a[0]); ok {
+			return f
+		}
+		return nil
+	}},
+	"string": {1, 1, func(a []interface{}) interface{} {
+		return toString(a[0])
+	}},
+	"abs":   unaryNumber(math.Abs),
+	"floor": unaryNumber(math.Floor),
+	"ceil":  unaryNumber(math.Ceil),
+	"round": {1, 2, func(a []interface{}) interface{} {
+		x, ok := toNumber(a[0])
+		if !ok {
+			return nil
+		}
+		p := 1.0
+		if len(a) > 1 {
+			d, _ := toNumber(a[1])
+			p = math.Pow(10, d)
+		}
+		return math.Round(x*p) / p
+	}},
+	"min": {1, -1, func(a []interface{}) interface{} {
+		return extreme(a, -1)
+	}},
+	"max": {1, -1, func(a []interface{}) interface{} {
+		return extreme(a, 1)
+	}},
+	"now": {0, 0, func(a []interface{}) interface{} {
+		return float64(time.Now().UnixNano() / int64(time.Millisecond))
+	}},
+	"time": {1, 1, func(a []interface{}) interface{} {
+		if t, ok := toTime(a[0]); ok {
+			return float64(t.UnixNano() / int64(time.Millisecond))
+		}
+		return nil
+	}},
+	"datediff": {2, 2, func(a []interface{}) interface{} {
+		end, ok := toTime(a[0])
+		if !ok {
+			return nil
+		}
+		start, ok := toTime(a[1])
+		if !ok {
+			return nil
+		}
+		return float64(end.Sub(start) / time.Millisecond)
+	}},
+	"duration": {1, 1, func(a []interface{}) interface{} {
+		if ms, ok := toNumber(a[0]); ok {
+			return (time.Duration(ms) * time.Millisecond).Round(time.Second).String()
+		}
+		return nil
+	}},
+	"formatTime": {2, 2, func(a []interface{}) interface{} {
+		if t, ok := toTime(a[0]); ok {
+			return t.Local().Format(toString(a[1]))
+		}
+		return nil
+	}},
+}
+
+// clamp limits i to the range 0 to max
+func clamp(i, max int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > max:
+		return max
+	}
+	return i
+}
+
+// extreme returns the minimum (dir -1) or maximum (dir 1) of the non-null
+// values
+func extreme(a []interface{}, dir int) interface{} {
+	var result interface{}
+	for _, v := range a {
+		if v != nil && (result == nil || compare(v, result) == dir) {
+			result = v
+		}
+	}
+	return result
+}
+
+// token kinds
+const (
+	tokEOF = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokVar
+	tokOp
+)
+
+type exprToken struct {
+	kind  int
+	value string
+	pos   int
+}
+
+type exprParser struct {
+	src    string
+	tokens []exprToken
+	i      int
+}
+
+// operators sorted by length, thus the longest operator matches first
+var exprOps = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "+", "-", "*", "/", "%", "!", "?", ":", "(", ")", "[", "]", ",", ".", "@"}
+
+func isIdentRune(r rune, first bool) bool {
+	return r == '_' || unicode.IsLetter(r) || (!first && unicode.IsDigit(r))
+}
+
+// tokenize splits the source into tokens
+func (p *exprParser) tokenize() error {
+	src := p.src
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c >= '0' && c <= '9':
+			start := i
+			for i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{tokNumber, src[start:i], start})
+		case c == '\'' || c == '"':
+			start := i
+			var sb strings.Builder
+			i++
+			for {
+				if i >= len(src) {
+					return fmt.Errorf("unterminated string at position %d", start)
+				}
+				if src[i] == byte(c) {
+					i++
+					break
+				}
+				if src[i] == '\\' && i+1 < len(src) {
+					i++
+				}
+				sb.WriteByte(src[i])
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{tokString, sb.String(), start})
+		case c == '$' || isIdentRune(c, true):
+			start := i
+			kind := tokIdent
+			if c == '$' {
+				kind = tokVar
+				i++
+			}
+			for i < len(src) {
+				r := []rune(src[i:])[0]
+				if !isIdentRune(r, false) {
+					break
+				}
+				i += len(string(r))
+			}
+			name := src[start:i]
+			if kind == tokVar {
+				name = name[1:]
+				if name == "" {
+					return fmt.Errorf("variable name expected at position %d", start)
+				}
+			}
+			p.tokens = append(p.tokens, exprToken{kind, name, start})
+		default:
+			found := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(src[i:], op) {
+					p.tokens = append(p.tokens, exprToken{tokOp, op, i})
+					i += len(op)
+					found = true
+					break
+				}
+			}
+			if !found {
+				return fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+		}
+	}
+	p.tokens = append(p.tokens, exprToken{tokEOF, "", len(src)})
+	return nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.i]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// accept consumes the operator if it is the next token
+func (p *exprParser) accept(op string) bool {
+	if t := p.peek(); t.kind == tokOp && t.value == op {
+		p.i++
+		return true
+	}
+	return false
+}
+
+func (p *exprParser) expect(op string) error {
+	if !p.accept(op) {
+		return p.unexpected(p.peek())
+	}
+	return nil
+}
+
+func (p *exprParser) unexpected(t exprToken) error {
+	if t.kind == tokEOF {
+		return errors.New("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at position %d", t.value, t.pos)
+}
+
+// expr parses the conditional operator c ? x : y
+func (p *exprParser) expr() (exprNode, error) {
+	c, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return c, err
+	}
+	x, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	if err = p.expect(":"); err != nil {
+		return nil, err
+	}
+	y, err := p.expr()
+	if err != nil {
+		return nil, err
+	}
+	return &exprCond{c, x, y}, nil
+}
+
+// exprLevels contains the binary operators by precedence
+var exprLevels = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!=", "<", "<=", ">", ">="},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// binary parses left associative binary operators of the level and above
+func (p *exprParser) binary(level int) (exprNode, error) {
+	if level == len(exprLevels) {
+		return p.unary()
+	}
+	x, err := p.binary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t := p.peek()
+		if t.kind != tokOp || !hasOp(exprLevels[level], t.value) {
+			return x, nil
+		}
+		p.i++
+		y, err := p.binary(level + 1)
+		if err != nil {
+			return nil, err
+		}
+		x = &exprBinary{t.value, x, y}
+	}
+}
+
+// hasOp returns true if op is one of the operators
+func hasOp(ops []string, op string) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if t := p.peek(); t.kind == tokOp && (t.value == "!" || t.value == "-") {
+		p.i++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{t.value, x}, nil
+	}
+	return p.postfix()
+}
+
+// postfix parses member access with a.b and index access with a[0]
+func (p *exprParser) postfix() (exprNode, error) {
+	x, err := p.primary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		switch {
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokIdent {
+				return nil, p.unexpected(t)
+			}
+			x = &exprIndex{x, &exprLiteral{t.value}}
+		case p.accept("["):
+			key, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			if err = p.expect("]"); err != nil {
+				return nil, err
+			}
+			x = &exprIndex{x, key}
+		default:
+			return x, nil
+		}
+	}
+}
+
+func (p *exprParser) primary() (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		f, err := strconv.ParseFloat(t.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", t.value, t.pos)
+		}
+		return &exprLiteral{f}, nil
+	case tokString:
+		return &exprLiteral{t.value}, nil
+	case tokVar:
+		return &exprVar{t.value}, nil
+	case tokIdent:
+		switch t.value {
+		case "true":
+			return &exprLiteral{true}, nil
+		case "false":
+			return &exprLiteral{false}, nil
+		case "null":
+			return &exprLiteral{nil}, nil
+		}
+		if !p.accept("(") {
+			return &exprAttr{t.value}, nil
+		}
+		return p.call(t)
+	case tokOp:
+		switch t.value {
+		case "@":
+			return &exprAttr{"@"}, nil
+		case "(":
+			x, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			return x, p.expect(")")
+		}
+	}
+	return nil, p.unexpected(t)
+}
+
+// call parses the arguments of a function call
+func (p *exprParser) call(name exprToken) (exprNode, error) {
+	f, ok := exprFuncs[name.value]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at position %d", name.value, name.pos)
+	}
+	var args []exprNode
+	if !p.accept(")") {
+		for {
+			a, err := p.expr()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, a)
+			if p.accept(")") {
+				break
+			}
+			if err = p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if len(args) < f.min || (f.max >= 0 && len(args) > f.max) {
+		return nil, fmt.Errorf("wrong number of arguments for %s at position %d", name.value, name.pos)
+	}
+	return &exprCall{name.value, f, args}, nil
+}
@@ -0,0 +1,95 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExpression(t *testing.T) {
+	var obj interface{}
+	if err := json.Unmarshal([]byte(`{
+		"name": "run",
+		"state": "FAILED",
+		"created": "2021-05-01T10:00:00Z",
+		"updated": "2021-05-01T10:01:30Z",
+		"start": 1000,
+		"end": 4500,
+		"tags": ["a", "b"],
+		"x-y": {"z": 1}
+	}`), &obj); err != nil {
+		t.Fatal(err)
+	}
+	q := NewQuery(obj)
+	scope := NewScope(nil, nil, nil, q)
+	scope.Set("prefix", "p-")
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`concat(name, '-', state)`, "run-FAILED"},
+		{`coalesce(missing, "", name)`, "run"},
+		{`if(state == 'FAILED', 'x', 'y')`, "x"},
+		{`state != 'FAILED' ? 'ok' : 'nok'`, "nok"},
+		{`end - start`, "3500"},
+		{`(end - start) / 1000 * 2`, "7"},
+		{`1 + 2 * 3 % 4`, "3"},
+		{`datediff(updated, created)`, "90000"},
+		{`duration(datediff(updated, created))`, "1m30s"},
+		{`upper(substr(name, 1))`, "UN"},
+		{`replace(state, 'AI', '')`, "FLED"},
+		{`len(tags) + len(name)`, "5"},
+		{`tags[1]`, "b"},
+		{`tags[-1]`, "b"},
+		{`@['x-y'].z`, "1"},
+		{`$prefix + name`, "p-run"},
+		{`join(tags, '|')`, "a|b"},
+		{`contains(tags, 'a') && !contains(name, 'x')`, "true"},
+		{`start / 0`, "-"},
+		{`missing.a.b`, "-"},
+		{`round(10 / 3, 2)`, "3.33"},
+		{`max(start, end, missing)`, "4500"},
+	}
+	for _, test := range tests {
+		e, err := ParseExpression(test.src)
+		if err != nil {
+			t.Errorf("ParseExpression(%q) = %v", test.src, err)
+			continue
+		}
+		if result := GetString(e.Eval(scope, q)); result != test.want {
+			t.Errorf("Eval(%q) = %q, want %q", test.src, result, test.want)
+		}
+	}
+}
+
+func TestExpressionErrors(t *testing.T) {
+	for _, src := range []string{
+		`concat(`,
+		`a +`,
+		`unknown(1)`,
+		`substr('a')`,
+		`'open`,
+		`a ? b`,
+		`a b`,
+		`#`,
+	} {
+		if _, err := ParseExpression(src); err == nil {
+			t.Errorf("ParseExpression(%q) succeeded, want error", src)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned by LockFile if the lock is not acquired in time
+var ErrLocked = errors.New("file is locked by another process")
+
+// lockPoll is the interval between two attempts to acquire a lock
+const lockPoll = 50 * time.Millisecond
+
+// FileLock is an advisory lock on a file, it coordinates concurrent aepctl
+// processes
+type FileLock struct {
+	f *os.File
+}
+
+// LockFile acquires an exclusive lock on the passed file. It creates the file
+// and its directory if necessary and waits up to timeout for the lock.
+func LockFile(path string, timeout time.Duration) (*FileLock, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+		if ok {
+			return &FileLock{f: f}, nil
+		}
+		if time.Now().After(deadline) {
+			_ = f.Close()
+			return nil, ErrLocked
+		}
+		time.Sleep(lockPoll)
+	}
+}
+
+// Unlock releases the lock
+func (l *FileLock) Unlock() error {
+	err := unlock(l.f)
+	if cerr := l.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -17,23 +17,53 @@ specific language governing permissions and limitations under the License.
 package util
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
 	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
+// MultiFileReader reads the JSON documents of multiple files or standard in.
+// A file may contain several documents, see SplitDocuments.
 type MultiFileReader struct {
 	Files   []string
 	Current int
+	docs    [][]byte
 }
 
+// Read returns the next document or nil if all files have been read
 func (m *MultiFileReader) Read() ([]byte, error) {
+	for len(m.docs) == 0 {
+		data, path, err := m.readFile()
+		if err != nil || data == nil {
+			return nil, err
+		}
+		if m.docs, err = SplitDocuments(data, path); err != nil {
+			return nil, err
+		}
+	}
+	result := m.docs[0]
+	m.docs = m.docs[1:]
+	return result, nil
+}
+
+// readFile returns the content and the path of the next file
+func (m *MultiFileReader) readFile() ([]byte, string, error) {
 	l := len(m.Files)
 	if m.Current == 0 && l == 0 {
 		m.Current++
-		return ioutil.ReadAll(os.Stdin)
+		data, err := ioutil.ReadAll(os.Stdin)
+		return data, "-", err
 	}
 	if m.Current >= l {
-		return nil, nil
+		return nil, "", nil
 	}
 	path := m.Files[m.Current]
 	m.Current++
@@ -41,10 +71,16 @@ func (m *MultiFileReader) Read() ([]byte, error) {
 	if path != "-" {
 		var err error
 		if file, err = os.Open(path); err != nil {
-			return nil, err
+			return nil, path, err
 		}
+		defer file.Close()
+	}
+	data, err := ioutil.ReadAll(file)
+	if data == nil && err == nil {
+		// an empty file is not the end
+		data = []byte{}
 	}
-	return ioutil.ReadAll(file)
+	return data, path, err
 }
 
 func (m *MultiFileReader) ReadAll(f func(data []byte) error) error {
@@ -60,3 +96,78 @@ func (m *MultiFileReader) ReadAll(f func(data []byte) error) error {
 	}
 	return err
 }
+
+// IsJSON checks if the first non-whitespace character of the reader starts a
+// JSON object or array. The reader is not advanced.
+func IsJSON(r *bufio.Reader) bool {
+	for n := 1; ; n++ {
+		b, err := r.Peek(n)
+		if len(b) < n {
+			return false
+		}
+		switch c := b[n-1]; c {
+		case ' ', '\t', '\r', '\n':
+			if err != nil {
+				return false
+			}
+		default:
+			return c == '{' || c == '['
+		}
+	}
+}
+
+// isJSONPath checks the extension of the path for JSON formats
+func isJSONPath(path string) (isJSON bool, known bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", ".ndjson", ".jsonl":
+		return true, true
+	case ".yaml", ".yml":
+		return false, true
+	}
+	return false, false
+}
+
+// SplitDocuments splits the content of a file into JSON documents. JSON files
+// may contain concatenated documents, e.g. newline delimited JSON (NDJSON),
+// YAML files may contain several documents separated by ---. YAML documents
+// are converted to JSON. The format is selected by the extension of the path
+// or detected by the first character.
+func SplitDocuments(data []byte, path string) ([][]byte, error) {
+	isJSON, known := isJSONPath(path)
+	if !known {
+		isJSON = IsJSON(bufio.NewReader(bytes.NewReader(data)))
+	}
+	var result [][]byte
+	if isJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for {
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				if err == io.EOF {
+					return result, nil
+				}
+				return nil, err
+			}
+			result = append(result, raw)
+		}
+	}
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var obj interface{}
+		if err := dec.Decode(&obj); err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+			return nil, err
+		}
+		if obj == nil {
+			// empty document
+			continue
+		}
+		doc, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, doc)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	tests := []struct {
+		data string
+		path string
+		want []string
+	}{
+		{"{\"a\":1}\n{\"a\":2}\n", "a.ndjson", []string{`{"a":1}`, `{"a":2}`}},
+		{"{\"a\":1} [2]", "-", []string{`{"a":1}`, `[2]`}},
+		{"a: 1\n---\nb: [x]\n", "a.yaml", []string{`{"a":1}`, `{"b":["x"]}`}},
+		{"a: 1\n", "-", []string{`{"a":1}`}},
+		{"  \n", "-", nil},
+	}
+	for _, test := range tests {
+		docs, err := SplitDocuments([]byte(test.data), test.path)
+		if err != nil {
+			t.Errorf("SplitDocuments(%q) = %v", test.data, err)
+			continue
+		}
+		if len(docs) != len(test.want) {
+			t.Errorf("SplitDocuments(%q) returned %d documents, want %d", test.data, len(docs), len(test.want))
+			continue
+		}
+		for i, doc := range docs {
+			if string(doc) != test.want[i] {
+				t.Errorf("SplitDocuments(%q)[%d] = %s, want %s", test.data, i, doc, test.want[i])
+			}
+		}
+	}
+}
@@ -227,6 +227,28 @@ func (j *JSONCursor) New() (*JSONCursor, error) {
 	return &JSONCursor{dec: j.dec, stream: j.stream, jss: jss, jp: jp}, nil
 }
 
+// NextDocument prepares the cursor for the next document of a stream with
+// concatenated JSON documents, e.g. newline delimited JSON (NDJSON). The rest
+// of the current document is skipped. It returns false if the stream has no
+// further document.
+func (j *JSONCursor) NextDocument() (bool, error) {
+	switch j.jss.Peek() {
+	case JSONS_OPEN:
+		// the current document has not been started
+		return j.dec.More(), nil
+	case JSONS_DONE, JSONS_UNDEFINED:
+		// the current document is complete or has been decoded
+	default:
+		if err := j.End(); err != nil {
+			return false, err
+		}
+	}
+	j.jss = j.jss[:0]
+	j.jss.Push(JSONS_OPEN)
+	j.jp = j.jp[:0]
+	return j.dec.More(), nil
+}
+
 func (j *JSONCursor) PathInfo() (string, string) {
 	l := len(j.jp)
 	if l == 0 {
@@ -239,7 +261,13 @@ func (j *JSONCursor) PathInfo() (string, string) {
 	return name, j.jp.Path()
 }
 
+// More checks if there is another element in the current object or array.
+// It is false after the end of a document, see NextDocument.
 func (j *JSONCursor) More() bool {
+	switch j.jss.Peek() {
+	case JSONS_DONE, JSONS_UNDEFINED:
+		return false
+	}
 	return j.dec.More()
 }
 
@@ -471,7 +499,12 @@ func (j *JSONCursor) Decode(v interface{}) error {
 
 // PrintRaw copies the raw data to standard out
 func (j *JSONCursor) PrintRaw() error {
-	bout := bufio.NewWriter(os.Stdout)
+	return j.FprintRaw(os.Stdout)
+}
+
+// FprintRaw copies the raw data to the passed writer
+func (j *JSONCursor) FprintRaw(w io.Writer) error {
+	bout := bufio.NewWriter(w)
 	defer bout.Flush()
 	_, err := io.Copy(bout, j.stream)
 	return err
@@ -479,7 +512,46 @@ func (j *JSONCursor) PrintRaw() error {
 
 // PrintPretty prints the raw data with indention to standard out
 func (j *JSONCursor) PrintPretty() error {
-	bout := bufio.NewWriter(os.Stdout)
+	return j.FprintPretty(os.Stdout)
+}
+
+// FprintPretty prints the raw data with indention to the passed writer.
+// Concatenated documents are printed one after another.
+func (j *JSONCursor) FprintPretty(w io.Writer) error {
+	bout := bufio.NewWriter(w)
 	defer bout.Flush()
-	return JSONPrintPretty(j.dec, bout)
+	if err := JSONPrintPretty(j.dec, bout); err != nil {
+		return err
+	}
+	for j.dec.More() {
+		if _, err := bout.WriteString("\n"); err != nil {
+			return err
+		}
+		if err := JSONPrintPretty(j.dec, bout); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FprintYAML converts the JSON document to YAML and writes it to the passed
+// writer. Concatenated documents are separated by ---.
+func (j *JSONCursor) FprintYAML(w io.Writer) error {
+	bout := bufio.NewWriter(w)
+	defer bout.Flush()
+	for first := true; first || j.dec.More(); first = false {
+		var raw json.RawMessage
+		if err := j.dec.Decode(&raw); err != nil {
+			return err
+		}
+		if !first {
+			if _, err := bout.WriteString("---\n"); err != nil {
+				return err
+			}
+		}
+		if err := JSONToYAML(raw, bout); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -0,0 +1,54 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestNextDocument(t *testing.T) {
+	c := NewJSONCursor(ioutil.NopCloser(strings.NewReader("{\"items\":[1,2]}\n{\"items\":[3]}\n")))
+	var result []string
+	for {
+		more, err := c.NextDocument()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !more {
+			break
+		}
+		i := NewJSONIterator(c)
+		if err := i.Path("items"); err != nil {
+			t.Fatal(err)
+		}
+		if err := i.Enter(); err != nil {
+			t.Fatal(err)
+		}
+		for i.More() {
+			q, err := i.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, q.String())
+		}
+	}
+	if len(result) != 3 || result[0] != "1" || result[2] != "3" {
+		t.Errorf("result = %v, want [1 2 3]", result)
+	}
+}
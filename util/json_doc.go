@@ -0,0 +1,373 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// JSONPointer is a parsed JSON Pointer (RFC 6901), the empty pointer refers
+// to the whole document
+type JSONPointer []string
+
+// ParseJSONPointer parses the string representation of a JSON Pointer, e.g.
+// /meta~1status/0
+func ParseJSONPointer(s string) (JSONPointer, error) {
+	if s == "" {
+		return JSONPointer{}, nil
+	}
+	if s[0] != '/' {
+		return nil, fmt.Errorf("invalid JSON pointer %q, it must start with /", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return JSONPointer(tokens), nil
+}
+
+// String returns the escaped string representation of the pointer
+func (p JSONPointer) String() string {
+	var sb strings.Builder
+	for _, t := range p {
+		sb.WriteByte('/')
+		sb.WriteString(pointerEscaper.Replace(t))
+	}
+	return sb.String()
+}
+
+// Append returns a new pointer with the additional tokens
+func (p JSONPointer) Append(tokens ...string) JSONPointer {
+	result := make(JSONPointer, len(p), len(p)+len(tokens))
+	copy(result, p)
+	return append(result, tokens...)
+}
+
+// Parent returns the pointer of the parent and the last token
+func (p JSONPointer) Parent() (JSONPointer, string) {
+	if len(p) == 0 {
+		return p, ""
+	}
+	return p[:len(p)-1], p[len(p)-1]
+}
+
+// JSONDoc is a mutable JSON document consisting of map[string]interface{},
+// []interface{} and scalar values. The values are addressed by JSON
+// Pointers.
+type JSONDoc struct {
+	root interface{}
+}
+
+// NewJSONDoc creates a document with the passed decoded JSON value
+func NewJSONDoc(root interface{}) *JSONDoc {
+	return &JSONDoc{root: root}
+}
+
+// ParseJSONDoc decodes JSON data and returns a document
+func ParseJSONDoc(data []byte) (*JSONDoc, error) {
+	var root interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	return NewJSONDoc(root), nil
+}
+
+// ReadJSONDoc decodes the first JSON document of the reader
+func ReadJSONDoc(r io.Reader) (*JSONDoc, error) {
+	var root interface{}
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+	return NewJSONDoc(root), nil
+}
+
+// Root returns the decoded JSON value of the document
+func (d *JSONDoc) Root() interface{} {
+	return d.root
+}
+
+// Query returns a query object for the document
+func (d *JSONDoc) Query() *Query {
+	return NewQuery(d.root)
+}
+
+// Clone returns a deep copy of the document
+func (d *JSONDoc) Clone() *JSONDoc {
+	return NewJSONDoc(CloneJSON(d.root))
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (d *JSONDoc) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.root)
+}
+
+// Has returns true if the pointer refers to an existing value
+func (d *JSONDoc) Has(ptr string) bool {
+	_, err := d.Get(ptr)
+	return err == nil
+}
+
+// Get returns the value referred by the pointer
+func (d *JSONDoc) Get(ptr string) (interface{}, error) {
+	p, err := ParseJSONPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return d.get(p)
+}
+
+func (d *JSONDoc) get(p JSONPointer) (interface{}, error) {
+	value := d.root
+	for i, t := range p {
+		switch obj := value.(type) {
+		case map[string]interface{}:
+			v, ok := obj[t]
+			if !ok {
+				return nil, fmt.Errorf("path %s does not exist", p[:i+1])
+			}
+			value = v
+		case []interface{}:
+			index, err := arrayIndex(t, len(obj), false)
+			if err != nil {
+				return nil, fmt.Errorf("path %s: %v", p[:i+1], err)
+			}
+			value = obj[index]
+		default:
+			return nil, fmt.Errorf("path %s does not exist, %s is not a container", p[:i+1], p[:i])
+		}
+	}
+	return value, nil
+}
+
+// Set replaces the value referred by the pointer. Missing object members are
+// added, the array index - appends the value.
+func (d *JSONDoc) Set(ptr string, value interface{}) error {
+	p, err := ParseJSONPointer(ptr)
+	if err != nil {
+		return err
+	}
+	return d.set(p, value, false)
+}
+
+// Insert adds the value according to the add operation of JSON Patch. Values
+// are inserted into arrays before the referred index and the index - appends
+// the value. Existing object members are replaced.
+func (d *JSONDoc) Insert(ptr string, value interface{}) error {
+	p, err := ParseJSONPointer(ptr)
+	if err != nil {
+		return err
+	}
+	return d.set(p, value, true)
+}
+
+// Delete removes the value referred by the pointer
+func (d *JSONDoc) Delete(ptr string) error {
+	p, err := ParseJSONPointer(ptr)
+	if err != nil {
+		return err
+	}
+	_, err = d.remove(p)
+	return err
+}
+
+func (d *JSONDoc) set(p JSONPointer, value interface{}, insert bool) error {
+	if len(p) == 0 {
+		d.root = value
+		return nil
+	}
+	pp, last := p.Parent()
+	parent, err := d.get(pp)
+	if err != nil {
+		return err
+	}
+	switch obj := parent.(type) {
+	case map[string]interface{}:
+		obj[last] = value
+	case []interface{}:
+		index, err := arrayIndex(last, len(obj), true)
+		if err != nil {
+			return fmt.Errorf("path %s: %v", p, err)
+		}
+		switch {
+		case index == len(obj):
+			obj = append(obj, value)
+		case insert:
+			obj = append(obj, nil)
+			copy(obj[index+1:], obj[index:])
+			obj[index] = value
+		default:
+			obj[index] = value
+			return nil
+		}
+		// the slice header has changed, store it in the parent
+		return d.set(pp, obj, false)
+	default:
+		return fmt.Errorf("path %s does not exist, %s is not a container", p, pp)
+	}
+	return nil
+}
+
+func (d *JSONDoc) remove(p JSONPointer) (interface{}, error) {
+	if len(p) == 0 {
+		value := d.root
+		d.root = nil
+		return value, nil
+	}
+	pp, last := p.Parent()
+	parent, err := d.get(pp)
+	if err != nil {
+		return nil, err
+	}
+	switch obj := parent.(type) {
+	case map[string]interface{}:
+		value, ok := obj[last]
+		if !ok {
+			return nil, fmt.Errorf("path %s does not exist", p)
+		}
+		delete(obj, last)
+		return value, nil
+	case []interface{}:
+		index, err := arrayIndex(last, len(obj), false)
+		if err != nil {
+			return nil, fmt.Errorf("path %s: %v", p, err)
+		}
+		value := obj[index]
+		result := make([]interface{}, 0, len(obj)-1)
+		result = append(append(result, obj[:index]...), obj[index+1:]...)
+		return value, d.set(pp, result, false)
+	}
+	return nil, fmt.Errorf("path %s does not exist, %s is not a container", p, pp)
+}
+
+// arrayIndex converts the token to an index of an array with the passed
+// length. The token - and the length are only valid for appending values.
+func arrayIndex(token string, length int, appending bool) (int, error) {
+	if token == "-" {
+		if appending {
+			return length, nil
+		}
+		return 0, fmt.Errorf("index - is only valid for adding values")
+	}
+	if token == "" || (len(token) > 1 && token[0] == '0') || strings.TrimLeft(token, "0123456789") != "" {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index > length || (index == length && !appending) {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// CloneJSON returns a deep copy of a decoded JSON value
+func CloneJSON(value interface{}) interface{} {
+	switch obj := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(obj))
+		for k, v := range obj {
+			result[k] = CloneJSON(v)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(obj))
+		for i, v := range obj {
+			result[i] = CloneJSON(v)
+		}
+		return result
+	}
+	return value
+}
+
+// StripJSON removes all object members of a decoded JSON value for which
+// drop returns true. The passed value is modified.
+func StripJSON(value interface{}, drop func(key string, value interface{}) bool) interface{} {
+	switch obj := value.(type) {
+	case map[string]interface{}:
+		for k, v := range obj {
+			if drop(k, v) {
+				delete(obj, k)
+				continue
+			}
+			obj[k] = StripJSON(v, drop)
+		}
+	case []interface{}:
+		for i, v := range obj {
+			obj[i] = StripJSON(v, drop)
+		}
+	}
+	return value
+}
+
+// EqualJSON returns true if both decoded JSON values are equal. Numbers are
+// compared by value, independent of their type.
+func EqualJSON(a, b interface{}) bool {
+	switch x := a.(type) {
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			w, ok := y[k]
+			if !ok || !EqualJSON(v, w) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !EqualJSON(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if n, ok := jsonNumber(a); ok {
+		m, ok := jsonNumber(b)
+		return ok && n == m
+	}
+	return a == b
+}
+
+// jsonNumber converts the numeric types of decoded JSON to float64
+func jsonNumber(value interface{}) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -18,11 +18,34 @@ package util
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"time"
 )
 
+// ErrCacheDisabled is returned by Load if reading the cache is disabled
+var ErrCacheDisabled = errors.New("reading the cache is disabled")
+
+// ErrCacheReadOnly is returned by Lock if writing the cache is disabled
+var ErrCacheReadOnly = errors.New("writing the cache is disabled")
+
+// ErrCorrupt is returned by Load if the file is not valid json, the file has
+// been removed
+var ErrCorrupt = errors.New("corrupt file has been removed")
+
+// LockTimeout is the maximum waiting time for the lock of a JSON file
+var LockTimeout = 30 * time.Second
+
+// CacheSettings controls reading and writing of cache files, it is
+// implemented by paths of JSON files
+type CacheSettings interface {
+	ReadCache() bool
+	WriteCache() bool
+}
+
 // JSONFile stores json objects in a file
 type JSONFile struct {
 	p Path
@@ -38,19 +61,48 @@ func (jc *JSONFile) Delete() error {
 	return os.Remove(jc.p.Path())
 }
 
-// Save stores the passed object in json format to a file
+// Path returns the path of the related file
+func (jc *JSONFile) Path() string {
+	return jc.p.Path()
+}
+
+// Save stores the passed object in json format to a file. The file is
+// replaced atomically, concurrent readers never see a partially written file.
 func (jc *JSONFile) Save(obj interface{}) error {
-	p := filepath.Dir(jc.p.Path())
-	if _, err := os.Stat(p); os.IsNotExist(err) {
-		if err = os.MkdirAll(p, 0700); err != nil {
-			return err
-		}
+	if s, ok := jc.p.(CacheSettings); ok && !s.WriteCache() {
+		return nil
+	}
+	path := jc.p.Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
 	}
 	data, err := json.Marshal(obj)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(jc.p.Path(), data, 0700)
+	f, err := CreateAtomic(path, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		_ = f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+// Lock acquires the advisory lock of the related file. It is used to
+// coordinate refreshes of the same file by concurrent processes. Without
+// write access to the cache, e.g. with --no-cache, no lock file is created.
+func (jc *JSONFile) Lock() (func(), error) {
+	if s, ok := jc.p.(CacheSettings); ok && !s.WriteCache() {
+		return nil, ErrCacheReadOnly
+	}
+	l, err := LockFile(jc.p.Path()+".lock", LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return func() { _ = l.Unlock() }, nil
 }
 
 // Exists returns true if the related file exists
@@ -61,10 +113,23 @@ func (jc *JSONFile) Exists() bool {
 
 // Load loads the json file into the passed object
 func (jc *JSONFile) Load(obj interface{}) error {
+	if s, ok := jc.p.(CacheSettings); ok && !s.ReadCache() {
+		return ErrCacheDisabled
+	}
 	var data []byte
 	data, err := ioutil.ReadFile(jc.p.Path())
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(data, &obj)
+	if err = json.Unmarshal(data, &obj); err != nil {
+		var se *json.SyntaxError
+		var te *json.UnmarshalTypeError
+		if errors.As(err, &se) || errors.As(err, &te) {
+			// self-healing, the next Save creates a valid file
+			_ = jc.Delete()
+			return fmt.Errorf("%s: %w (%v)", jc.p.Path(), ErrCorrupt, err)
+		}
+		return err
+	}
+	return nil
 }
@@ -0,0 +1,111 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testPath string
+
+func (p testPath) Path() string {
+	return string(p)
+}
+
+// readOnlyPath disables writing of the cache
+type readOnlyPath string
+
+func (p readOnlyPath) Path() string {
+	return string(p)
+}
+
+func (p readOnlyPath) ReadCache() bool {
+	return true
+}
+
+func (p readOnlyPath) WriteCache() bool {
+	return false
+}
+
+func TestJSONFileSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	jf := NewJSONFile(testPath(filepath.Join(dir, "sub", "test.json")))
+	if err := jf.Save(map[string]string{"a": "b"}); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := jf.Load(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["a"] != "b" {
+		t.Errorf("expected b, got %v", got)
+	}
+	// no temporary files are left
+	files, _ := ioutil.ReadDir(filepath.Join(dir, "sub"))
+	if len(files) != 1 {
+		t.Errorf("expected 1 file, got %d", len(files))
+	}
+}
+
+func TestJSONFileCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.json")
+	if err := ioutil.WriteFile(path, []byte(`{"a": "b`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]string
+	if err := NewJSONFile(testPath(path)).Load(&got); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("expected ErrCorrupt, got %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected removed file, got %v", err)
+	}
+}
+
+func TestLockFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.lock")
+	l, err := LockFile(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = LockFile(path, 100*time.Millisecond); err != ErrLocked {
+		t.Errorf("expected ErrLocked, got %v", err)
+	}
+	if err = l.Unlock(); err != nil {
+		t.Fatal(err)
+	}
+	l, err = LockFile(path, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Unlock()
+}
+
+func TestJSONFileLockReadOnly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "test.json")
+	jf := NewJSONFile(readOnlyPath(path))
+	if _, err := jf.Lock(); err != ErrCacheReadOnly {
+		t.Errorf("expected ErrCacheReadOnly, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(path)); !os.IsNotExist(err) {
+		t.Errorf("expected no directory, got %v", err)
+	}
+}
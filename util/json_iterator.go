@@ -64,6 +64,12 @@ func (j *JSONIterator) Cursor() *JSONCursor {
 	return j.c
 }
 
+// NextDocument prepares the iterator for the next document of a stream with
+// concatenated JSON documents, see JSONCursor.NextDocument
+func (j *JSONIterator) NextDocument() (bool, error) {
+	return j.c.NextDocument()
+}
+
 // More checks if there is another element in the current object or array
 func (j *JSONIterator) More() bool {
 	return j.c.More()
@@ -0,0 +1,259 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSON Patch operations (RFC 6902)
+const (
+	PatchAdd     = "add"
+	PatchRemove  = "remove"
+	PatchReplace = "replace"
+	PatchMove    = "move"
+	PatchCopy    = "copy"
+	PatchTest    = "test"
+)
+
+// PatchOperation is a single operation of a JSON Patch
+type PatchOperation struct {
+	Op    string      `json:"op" yaml:"op"`
+	Path  string      `json:"path" yaml:"path"`
+	From  string      `json:"from,omitempty" yaml:"from,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// MarshalJSON implements the json.Marshaler interface. The value of add,
+// replace and test is always written, even if it is null.
+func (op *PatchOperation) MarshalJSON() ([]byte, error) {
+	switch op.Op {
+	case PatchAdd, PatchReplace, PatchTest:
+		return json.Marshal(&struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}{op.Op, op.Path, op.Value})
+	case PatchMove, PatchCopy:
+		return json.Marshal(&struct {
+			Op   string `json:"op"`
+			From string `json:"from"`
+			Path string `json:"path"`
+		}{op.Op, op.From, op.Path})
+	}
+	return json.Marshal(&struct {
+		Op   string `json:"op"`
+		Path string `json:"path"`
+	}{op.Op, op.Path})
+}
+
+// String returns a short description of the operation
+func (op *PatchOperation) String() string {
+	switch op.Op {
+	case PatchMove, PatchCopy:
+		return fmt.Sprintf("%s %s to %s", op.Op, op.From, op.Path)
+	case PatchRemove:
+		return fmt.Sprintf("%s %s", op.Op, op.Path)
+	}
+	value, _ := json.Marshal(op.Value)
+	return fmt.Sprintf("%s %s %s", op.Op, op.Path, value)
+}
+
+// JSONPatch is a sequence of operations (RFC 6902)
+type JSONPatch []*PatchOperation
+
+// ParseJSONPatch decodes a JSON Patch document
+func ParseJSONPatch(data []byte) (JSONPatch, error) {
+	var result JSONPatch
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+	for i, op := range result {
+		if op == nil {
+			return nil, fmt.Errorf("operation %d is null", i)
+		}
+		switch op.Op {
+		case PatchAdd, PatchRemove, PatchReplace, PatchMove, PatchCopy, PatchTest:
+		default:
+			return nil, fmt.Errorf("operation %d has the unknown type %q", i, op.Op)
+		}
+	}
+	return result, nil
+}
+
+// Apply applies all operations to the document. The document is not changed
+// if one of the operations fails.
+func (d *JSONDoc) Apply(patch JSONPatch) error {
+	tmp := d.Clone()
+	for i, op := range patch {
+		if err := tmp.apply(op); err != nil {
+			return fmt.Errorf("operation %d (%s) failed: %v", i, op.Op, err)
+		}
+	}
+	d.root = tmp.root
+	return nil
+}
+
+func (d *JSONDoc) apply(op *PatchOperation) error {
+	p, err := ParseJSONPointer(op.Path)
+	if err != nil {
+		return err
+	}
+	switch op.Op {
+	case PatchAdd:
+		return d.set(p, CloneJSON(op.Value), true)
+	case PatchRemove:
+		_, err = d.remove(p)
+		return err
+	case PatchReplace:
+		if _, err = d.get(p); err != nil {
+			return err
+		}
+		return d.set(p, CloneJSON(op.Value), false)
+	case PatchMove, PatchCopy:
+		from, err := ParseJSONPointer(op.From)
+		if err != nil {
+			return err
+		}
+		var value interface{}
+		if op.Op == PatchMove {
+			if len(from) < len(p) && from.String() == p[:len(from)].String() {
+				return fmt.Errorf("path %s cannot be moved into its child %s", from, p)
+			}
+			value, err = d.remove(from)
+		} else {
+			value, err = d.get(from)
+			value = CloneJSON(value)
+		}
+		if err != nil {
+			return err
+		}
+		return d.set(p, value, true)
+	case PatchTest:
+		value, err := d.get(p)
+		if err != nil {
+			return err
+		}
+		if !EqualJSON(value, op.Value) {
+			return fmt.Errorf("value of path %s differs", p)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown operation %q", op.Op)
+}
+
+// ApplyPatch applies the patch to a copy of the decoded JSON value and
+// returns the result
+func ApplyPatch(value interface{}, patch JSONPatch) (interface{}, error) {
+	d := NewJSONDoc(value)
+	if err := d.Apply(patch); err != nil {
+		return nil, err
+	}
+	return d.root, nil
+}
+
+// Diff returns the JSON Patch transforming the document from into the
+// document to. Object members are compared recursively in sorted order,
+// arrays element by element after skipping the common prefix and suffix.
+func Diff(from, to interface{}) JSONPatch {
+	var result JSONPatch
+	diff(JSONPointer{}, from, to, &result)
+	return result
+}
+
+func diff(p JSONPointer, from, to interface{}, result *JSONPatch) {
+	switch a := from.(type) {
+	case map[string]interface{}:
+		if b, ok := to.(map[string]interface{}); ok {
+			diffObjects(p, a, b, result)
+			return
+		}
+	case []interface{}:
+		if b, ok := to.([]interface{}); ok {
+			diffArrays(p, a, b, result)
+			return
+		}
+	}
+	if !EqualJSON(from, to) {
+		*result = append(*result, &PatchOperation{Op: PatchReplace, Path: p.String(), Value: to})
+	}
+}
+
+func diffObjects(p JSONPointer, a, b map[string]interface{}, result *JSONPatch) {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		va, inA := a[k]
+		vb, inB := b[k]
+		switch {
+		case !inB:
+			*result = append(*result, &PatchOperation{Op: PatchRemove, Path: p.Append(k).String()})
+		case !inA:
+			*result = append(*result, &PatchOperation{Op: PatchAdd, Path: p.Append(k).String(), Value: vb})
+		default:
+			diff(p.Append(k), va, vb, result)
+		}
+	}
+}
+
+func diffArrays(p JSONPointer, a, b []interface{}, result *JSONPatch) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && EqualJSON(a[prefix], b[prefix]) {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix &&
+		EqualJSON(a[len(a)-1-suffix], b[len(b)-1-suffix]) {
+		suffix++
+	}
+	ma, mb := a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]
+	common := len(ma)
+	if len(mb) < common {
+		common = len(mb)
+	}
+	for i := 0; i < common; i++ {
+		diff(p.Append(strconv.Itoa(prefix+i)), ma[i], mb[i], result)
+	}
+	for i := common; i < len(mb); i++ {
+		*result = append(*result, &PatchOperation{Op: PatchAdd, Path: p.Append(strconv.Itoa(prefix + i)).String(), Value: mb[i]})
+	}
+	// the index of the removed elements does not change
+	for i := common; i < len(ma); i++ {
+		*result = append(*result, &PatchOperation{Op: PatchRemove, Path: p.Append(strconv.Itoa(prefix + common)).String()})
+	}
+}
+
+// String returns the operations line by line
+func (patch JSONPatch) String() string {
+	lines := make([]string, len(patch))
+	for i, op := range patch {
+		lines[i] = op.String()
+	}
+	return strings.Join(lines, "\n")
+}
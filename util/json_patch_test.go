@@ -0,0 +1,157 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func decode(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestJSONPointer(t *testing.T) {
+	p, err := ParseJSONPointer("/a~1b/m~0n/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p) != 3 || p[0] != "a/b" || p[1] != "m~n" || p[2] != "0" {
+		t.Errorf("unexpected tokens %q", p)
+	}
+	if p.String() != "/a~1b/m~0n/0" {
+		t.Errorf("unexpected string %s", p)
+	}
+	if _, err := ParseJSONPointer("a"); err == nil {
+		t.Error("expected error for pointer without leading /")
+	}
+}
+
+func TestJSONDoc(t *testing.T) {
+	d := NewJSONDoc(decode(t, `{"a":{"b":[1,2,3]}}`))
+	steps := []struct {
+		f    func() error
+		want string
+	}{
+		{func() error { return d.Set("/a/c", "x") }, `{"a":{"b":[1,2,3],"c":"x"}}`},
+		{func() error { return d.Set("/a/b/0", 0) }, `{"a":{"b":[0,2,3],"c":"x"}}`},
+		{func() error { return d.Insert("/a/b/1", 1) }, `{"a":{"b":[0,1,2,3],"c":"x"}}`},
+		{func() error { return d.Insert("/a/b/-", 4) }, `{"a":{"b":[0,1,2,3,4],"c":"x"}}`},
+		{func() error { return d.Delete("/a/b/2") }, `{"a":{"b":[0,1,3,4],"c":"x"}}`},
+		{func() error { return d.Delete("/a/c") }, `{"a":{"b":[0,1,3,4]}}`},
+	}
+	for i, s := range steps {
+		if err := s.f(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+		if !EqualJSON(d.Root(), decode(t, s.want)) {
+			data, _ := json.Marshal(d)
+			t.Errorf("step %d: expected %s, got %s", i, s.want, data)
+		}
+	}
+	for _, ptr := range []string{"/x/y", "/a/b/9", "/a/b/-", "/a/b/01"} {
+		if err := d.Delete(ptr); err == nil {
+			t.Errorf("expected error for %s", ptr)
+		}
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		doc, patch, want string
+	}{
+		{`{"a":1}`, `[{"op":"add","path":"/b","value":[1]}]`, `{"a":1,"b":[1]}`},
+		{`{"a":[1,2]}`, `[{"op":"add","path":"/a/0","value":0}]`, `{"a":[0,1,2]}`},
+		{`{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{`{"a":1}`, `[{"op":"replace","path":"/a","value":null}]`, `{"a":null}`},
+		{`{"a":{"x":1},"b":{}}`, `[{"op":"move","from":"/a/x","path":"/b/y"}]`, `{"a":{},"b":{"y":1}}`},
+		{`{"a":[1]}`, `[{"op":"copy","from":"/a","path":"/b"}]`, `{"a":[1],"b":[1]}`},
+		{`{"a":"x"}`, `[{"op":"test","path":"/a","value":"x"},{"op":"remove","path":"/a"}]`, `{}`},
+	}
+	for _, test := range tests {
+		patch, err := ParseJSONPatch([]byte(test.patch))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ApplyPatch(decode(t, test.doc), patch)
+		if err != nil {
+			t.Errorf("%s: %v", test.patch, err)
+			continue
+		}
+		if !EqualJSON(got, decode(t, test.want)) {
+			data, _ := json.Marshal(got)
+			t.Errorf("%s: expected %s, got %s", test.patch, test.want, data)
+		}
+	}
+	// failing patches leave the document untouched
+	d := NewJSONDoc(decode(t, `{"a":1}`))
+	patch, _ := ParseJSONPatch([]byte(`[{"op":"remove","path":"/a"},{"op":"test","path":"/a","value":1}]`))
+	if err := d.Apply(patch); err == nil {
+		t.Error("expected failing test operation")
+	}
+	if !EqualJSON(d.Root(), decode(t, `{"a":1}`)) {
+		t.Error("document has been changed by a failing patch")
+	}
+	if _, err := ParseJSONPatch([]byte(`[{"op":"merge","path":"/a"}]`)); err == nil {
+		t.Error("expected error for unknown operation")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		from, to string
+		ops      int
+	}{
+		{`{"a":1}`, `{"a":1}`, 0},
+		{`{"a":1,"b":2}`, `{"a":2,"c":3}`, 3},
+		{`{"a":[1,2,3]}`, `{"a":[1,4,2,3]}`, 1},
+		{`{"a":[1,2,3,4]}`, `{"a":[1,4]}`, 2},
+		{`{"a":[{"x":1},{"x":2}]}`, `{"a":[{"x":1},{"x":3}]}`, 1},
+		{`{"a":{"x":1}}`, `{"a":[1]}`, 1},
+		{`{"a/b":{"c~d":1}}`, `{"a/b":{"c~d":2}}`, 1},
+		{`[1,2]`, `{"a":1}`, 1},
+	}
+	for _, test := range tests {
+		from, to := decode(t, test.from), decode(t, test.to)
+		patch := Diff(from, to)
+		if len(patch) != test.ops {
+			t.Errorf("%s -> %s: expected %d operations, got %d:\n%s", test.from, test.to, test.ops, len(patch), patch)
+		}
+		// the patch is applied to a copy
+		data, err := json.Marshal(patch)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := ParseJSONPatch(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ApplyPatch(from, parsed)
+		if err != nil {
+			t.Errorf("%s -> %s: %v\n%s", test.from, test.to, err, data)
+			continue
+		}
+		if !EqualJSON(got, to) {
+			result, _ := json.Marshal(got)
+			t.Errorf("%s -> %s: got %s", test.from, test.to, result)
+		}
+	}
+}
@@ -49,3 +49,19 @@ func (l *LazyPath) Path() string {
 	}
 	return l.result
 }
+
+// ReadCache returns the setting of the path provider, default is true
+func (l *LazyPath) ReadCache() bool {
+	if s, ok := l.pp.(CacheSettings); ok {
+		return s.ReadCache()
+	}
+	return true
+}
+
+// WriteCache returns the setting of the path provider, default is true
+func (l *LazyPath) WriteCache() bool {
+	if s, ok := l.pp.(CacheSettings); ok {
+		return s.WriteCache()
+	}
+	return true
+}
@@ -0,0 +1,218 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// DiffLine is a line of a line based diff. Op is ' ' for unchanged lines, '-'
+// for removed lines and '+' for added lines.
+type DiffLine struct {
+	Op   byte
+	Text string
+}
+
+// DiffLines returns the shortest edit script transforming the lines a into
+// the lines b (Myers' algorithm)
+func DiffLines(a, b []string) []DiffLine {
+	// the common prefix and suffix reduce the effort
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+	result := make([]DiffLine, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		result = append(result, DiffLine{' ', line})
+	}
+	result = append(result, myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		result = append(result, DiffLine{' ', line})
+	}
+	return result
+}
+
+func myers(a, b []string) []DiffLine {
+	n, m := len(a), len(b)
+	max := n + m
+	off := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] contains the furthest reaching x of the diagonals -d to d
+	// before step d
+	var trace [][]int
+	down := func(v []int, k, d int) bool {
+		return k == -d || (k != d && v[off+k-1] < v[off+k+1])
+	}
+loop:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v[off-d:off+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if down(v, k, d) {
+				x = v[off+k+1]
+			} else {
+				x = v[off+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[off+k] = x
+			if x >= n && y >= m {
+				break loop
+			}
+		}
+	}
+	// backtrack the path from the end to the start
+	result := make([]DiffLine, 0, max)
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		t := trace[d]
+		get := func(k int) int { return t[k+d] }
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && get(k-1) < get(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = get(prevK)
+		}
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			result = append(result, DiffLine{' ', a[x]})
+		}
+		if d > 0 {
+			if x == prevX {
+				y--
+				result = append(result, DiffLine{'+', b[y]})
+			} else {
+				x--
+				result = append(result, DiffLine{'-', a[x]})
+			}
+		}
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return result
+}
+
+// Changed returns true if at least one line has been added or removed
+func Changed(lines []DiffLine) bool {
+	for _, l := range lines {
+		if l.Op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteUnifiedDiff writes the lines in the unified diff format with the
+// passed number of context lines. Nothing is written if there are no
+// changes.
+func WriteUnifiedDiff(w io.Writer, from, to string, lines []DiffLine, context int, colored bool) error {
+	if !Changed(lines) {
+		return nil
+	}
+	bw := bufio.NewWriter(w)
+	paint := func(color, text string) string {
+		if !colored {
+			return text
+		}
+		return Paint(color, text)
+	}
+	fmt.Fprintln(bw, paint("bright-white", "--- "+from))
+	fmt.Fprintln(bw, paint("bright-white", "+++ "+to))
+	for start := 0; start < len(lines); {
+		// find the next change
+		first := start
+		for first < len(lines) && lines[first].Op == ' ' {
+			first++
+		}
+		if first == len(lines) {
+			break
+		}
+		begin := first - context
+		if begin < start {
+			begin = start
+		}
+		// extend the hunk while the gap between changes is small enough
+		end, last := first, first
+		for end < len(lines) {
+			if lines[end].Op != ' ' {
+				last = end
+			} else if end-last > 2*context {
+				break
+			}
+			end++
+		}
+		end = last + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+		// line numbers of the hunk
+		aStart, bStart := 1, 1
+		for _, l := range lines[:begin] {
+			if l.Op != '+' {
+				aStart++
+			}
+			if l.Op != '-' {
+				bStart++
+			}
+		}
+		aLen, bLen := 0, 0
+		for _, l := range lines[begin:end] {
+			if l.Op != '+' {
+				aLen++
+			}
+			if l.Op != '-' {
+				bLen++
+			}
+		}
+		if aLen == 0 {
+			aStart--
+		}
+		if bLen == 0 {
+			bStart--
+		}
+		fmt.Fprintln(bw, paint("cyan", fmt.Sprintf("@@ -%d,%d +%d,%d @@", aStart, aLen, bStart, bLen)))
+		for _, l := range lines[begin:end] {
+			switch l.Op {
+			case '-':
+				fmt.Fprintln(bw, paint("red", "-"+l.Text))
+			case '+':
+				fmt.Fprintln(bw, paint("green", "+"+l.Text))
+			default:
+				fmt.Fprintln(bw, " "+l.Text)
+			}
+		}
+		start = end
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,75 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiffLines(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want string
+	}{
+		{"a b c", "a b c", "  a|  b|  c"},
+		{"a b c", "a x c", "  a|- b|+ x|  c"},
+		{"a b c d", "b d e", "- a|  b|- c|  d|+ e"},
+		{"", "a", "+ a"},
+		{"a", "", "- a"},
+	}
+	for _, test := range tests {
+		lines := DiffLines(strings.Fields(test.a), strings.Fields(test.b))
+		got := make([]string, len(lines))
+		for i, l := range lines {
+			got[i] = string(l.Op) + " " + l.Text
+		}
+		if strings.Join(got, "|") != test.want {
+			t.Errorf("%q -> %q: expected %s, got %s", test.a, test.b, test.want, strings.Join(got, "|"))
+		}
+	}
+}
+
+func TestWriteUnifiedDiff(t *testing.T) {
+	a := strings.Fields("1 2 3 4 5 6 7 8 9 10 11 12")
+	b := strings.Fields("1 2 3 4 5 x 7 8 9 10 11 12 13")
+	var sb strings.Builder
+	if err := WriteUnifiedDiff(&sb, "a", "b", DiffLines(a, b), 2, false); err != nil {
+		t.Fatal(err)
+	}
+	want := `--- a
++++ b
+@@ -4,5 +4,5 @@
+ 4
+ 5
+-6
++x
+ 7
+ 8
+@@ -11,2 +11,3 @@
+ 11
+ 12
++13
+`
+	if sb.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, sb.String())
+	}
+	sb.Reset()
+	if err := WriteUnifiedDiff(&sb, "a", "b", DiffLines(a, a), 2, false); err != nil || sb.Len() > 0 {
+		t.Errorf("expected no output for equal lines, got %q", sb.String())
+	}
+}
@@ -0,0 +1,204 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"encoding/csv"
+	"html"
+	"io"
+	"strings"
+)
+
+// markup renders rows in a markup language like Markdown or HTML. The first
+// row is always the header.
+type markup interface {
+	row(w io.Writer, v []string) error
+	close(w io.Writer) error
+}
+
+// NewMarkdownWriter creates an initialized RowWriter writing a Markdown table.
+// A non-empty note will be printed below the table.
+func NewMarkdownWriter(out io.Writer, note string) *RowWriter {
+	return &RowWriter{w: out, m: &markdownMarkup{note: note}}
+}
+
+// NewHTMLWriter creates an initialized RowWriter writing a HTML table. A
+// non-empty note will be printed below the table.
+func NewHTMLWriter(out io.Writer, note string) *RowWriter {
+	return &RowWriter{w: out, m: &htmlMarkup{note: note}}
+}
+
+// NewFuncWriter creates an initialized RowWriter passing each row including
+// the header to the function, e.g. for collecting the rows of an interactive
+// table
+func NewFuncWriter(f func(v []string) error) *RowWriter {
+	return &RowWriter{m: funcMarkup(f)}
+}
+
+// funcMarkup passes the rows to a function
+type funcMarkup func(v []string) error
+
+func (f funcMarkup) row(_ io.Writer, v []string) error {
+	return f(v)
+}
+
+func (f funcMarkup) close(_ io.Writer) error {
+	return nil
+}
+
+// isTreeRune returns true for all runes used by the tree transformers for
+// the indention, e.g. │   ├── or └─>
+func isTreeRune(r rune) bool {
+	switch r {
+	case ' ', '│', '├', '└', '─', '>':
+		return true
+	}
+	return false
+}
+
+// splitIndent splits the passed string into the leading indention and the rest
+func splitIndent(s string) (string, string) {
+	for i, r := range s {
+		if !isTreeRune(r) {
+			return s[:i], s[i:]
+		}
+	}
+	return s, ""
+}
+
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"|", `\|`,
+	"*", `\*`,
+	"_", `\_`,
+	"`", "\\`",
+	"[", `\[`,
+	"]", `\]`,
+	"<", "&lt;",
+	">", "&gt;",
+	"\r\n", "<br>",
+	"\n", "<br>",
+)
+
+// markdownCell escapes the cell content and keeps the indention of tree
+// structures
+func markdownCell(s string) string {
+	indent, rest := splitIndent(s)
+	indent = strings.ReplaceAll(markdownEscaper.Replace(indent), " ", "&nbsp;")
+	return indent + markdownEscaper.Replace(strings.TrimRight(rest, " \t"))
+}
+
+type markdownMarkup struct {
+	note   string
+	header bool
+}
+
+func (m *markdownMarkup) row(w io.Writer, v []string) error {
+	var sb strings.Builder
+	sb.WriteString("|")
+	for _, c := range v {
+		sb.WriteByte(' ')
+		sb.WriteString(markdownCell(c))
+		sb.WriteString(" |")
+	}
+	sb.WriteByte('\n')
+	if !m.header {
+		m.header = true
+		sb.WriteString("|")
+		for range v {
+			sb.WriteString(" --- |")
+		}
+		sb.WriteByte('\n')
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (m *markdownMarkup) close(w io.Writer) error {
+	if m.note == "" {
+		return nil
+	}
+	_, err := io.WriteString(w, "\n_"+markdownEscaper.Replace(m.note)+"_\n")
+	return err
+}
+
+// htmlCell escapes the cell content and keeps the indention of tree
+// structures
+func htmlCell(s string) string {
+	indent, rest := splitIndent(s)
+	indent = strings.ReplaceAll(html.EscapeString(indent), " ", "&nbsp;")
+	rest = strings.ReplaceAll(html.EscapeString(strings.TrimRight(rest, " \t")), "\n", "<br>")
+	return indent + strings.ReplaceAll(rest, "\r", "")
+}
+
+type htmlMarkup struct {
+	note   string
+	header bool
+}
+
+func (m *htmlMarkup) row(w io.Writer, v []string) error {
+	var sb strings.Builder
+	tag := "td"
+	if !m.header {
+		tag = "th"
+		sb.WriteString("<table>\n<thead>\n")
+	}
+	sb.WriteString("<tr>")
+	for _, c := range v {
+		sb.WriteString("<" + tag + ">")
+		sb.WriteString(htmlCell(c))
+		sb.WriteString("</" + tag + ">")
+	}
+	sb.WriteString("</tr>\n")
+	if !m.header {
+		m.header = true
+		sb.WriteString("</thead>\n<tbody>\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (m *htmlMarkup) close(w io.Writer) error {
+	var sb strings.Builder
+	if m.header {
+		sb.WriteString("</tbody>\n</table>\n")
+	}
+	if m.note != "" {
+		sb.WriteString("<p><em>")
+		sb.WriteString(html.EscapeString(m.note))
+		sb.WriteString("</em></p>\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// csvMarkup writes the rows as comma separated values, quotes and line breaks
+// are escaped by encoding/csv
+type csvMarkup struct{}
+
+func (m *csvMarkup) row(w io.Writer, v []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(v); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func (m *csvMarkup) close(w io.Writer) error {
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMarkdownWriter(t *testing.T) {
+	var sb strings.Builder
+	w := NewMarkdownWriter(&sb, "")
+	if err := w.Write("STRUCTURE", "TYPE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("│   └── a|b", "x_y"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "| STRUCTURE | TYPE |\n| --- | --- |\n| │&nbsp;&nbsp;&nbsp;└──&nbsp;a\\|b | x\\_y |\n"
+	if result := sb.String(); result != want {
+		t.Errorf(`sb.String() = %q, want %q`, result, want)
+	}
+}
+
+func TestHTMLWriter(t *testing.T) {
+	var sb strings.Builder
+	w := NewHTMLWriter(&sb, "note")
+	if err := w.Write("NAME"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("<a>\nb"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "<table>\n<thead>\n<tr><th>NAME</th></tr>\n</thead>\n<tbody>\n<tr><td>&lt;a&gt;<br>b</td></tr>\n</tbody>\n</table>\n<p><em>note</em></p>\n"
+	if result := sb.String(); result != want {
+		t.Errorf(`sb.String() = %q, want %q`, result, want)
+	}
+}
+
+func TestCSVWriter(t *testing.T) {
+	var sb strings.Builder
+	w := NewCSVWriter(&sb)
+	if err := w.Write("NAME", "VALUE"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Write("a,b", "say \"hi\"\nbye"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := "NAME,VALUE\n\"a,b\",\"say \"\"hi\"\"\nbye\"\n"
+	if result := sb.String(); result != want {
+		t.Errorf(`sb.String() = %q, want %q`, result, want)
+	}
+}
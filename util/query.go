@@ -131,6 +131,15 @@ func (q *Query) Str(path ...string) string {
 	return q.Path(path...).String()
 }
 
+// StrOrEmpty returns the string of the referenced path or an empty string if
+// the value is not a string. Str returns - for missing values.
+func (q *Query) StrOrEmpty(path ...string) string {
+	if s, ok := q.Path(path...).obj.(string); ok {
+		return s
+	}
+	return ""
+}
+
 // String returns the current object as string
 func (q *Query) String() string {
 	return GetString(q.obj)
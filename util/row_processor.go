@@ -0,0 +1,320 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RowCondition is a simple expression in the form COLUMN op VALUE
+type RowCondition struct {
+	Column string
+	Op     string
+	Value  string
+	index  int
+	re     *regexp.Regexp
+}
+
+var conditionRE = regexp.MustCompile(`^\s*(.+?)\s*(==|!=|<=|>=|=~|!~|=|<|>)\s*(.*?)\s*$`)
+
+// NewRowCondition parses an expression like 'STATE = SUCCESS'. Supported
+// operators are =, ==, !=, <, <=, >, >=, =~ (regular expression) and !~ (not
+// matching regular expression).
+func NewRowCondition(expr string) (*RowCondition, error) {
+	m := conditionRE.FindStringSubmatch(expr)
+	if m == nil {
+		return nil, fmt.Errorf("invalid expression %q, expecting COLUMN op VALUE", expr)
+	}
+	result := &RowCondition{
+		Column: m[1],
+		Op:     m[2],
+		Value:  RemoveQuotes(m[3]),
+	}
+	if result.Op == "==" {
+		result.Op = "="
+	}
+	if result.Op == "=~" || result.Op == "!~" {
+		re, err := regexp.Compile(result.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.re = re
+	}
+	return result, nil
+}
+
+// Matches evaluates the condition for the passed value
+func (c *RowCondition) Matches(value string) bool {
+	switch c.Op {
+	case "=~":
+		return c.re.MatchString(value)
+	case "!~":
+		return !c.re.MatchString(value)
+	}
+	r := CompareValues(value, c.Value)
+	switch c.Op {
+	case "=":
+		return r == 0
+	case "!=":
+		return r != 0
+	case "<":
+		return r < 0
+	case "<=":
+		return r <= 0
+	case ">":
+		return r > 0
+	case ">=":
+		return r >= 0
+	}
+	return false
+}
+
+// CompareValues compares two values numerically if both are numbers,
+// otherwise lexically. The result is -1, 0 or 1.
+func CompareValues(a, b string) int {
+	fa, erra := strconv.ParseFloat(strings.TrimSpace(a), 64)
+	fb, errb := strconv.ParseFloat(strings.TrimSpace(b), 64)
+	if erra == nil && errb == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// RowProcessor sorts, filters and selects the columns of rendered rows. The
+// first processed row must be the header.
+type RowProcessor struct {
+	SortBy     string
+	Descending bool
+	Where      []*RowCondition
+	Columns    []string
+	aliases    []string
+	header     []string
+	sortIndex  int
+	selected   []int
+	rows       [][]string
+	seen       map[string]bool
+	summary    *Summary
+}
+
+// NewRowProcessor creates an initialized RowProcessor. sortBy has the format
+// COLUMN[:asc|:desc], where contains expressions in the format COLUMN op VALUE
+// and columns is a list of column names.
+func NewRowProcessor(sortBy string, where []string, columns []string) (*RowProcessor, error) {
+	result := &RowProcessor{sortIndex: -1}
+	if sortBy != "" {
+		name := sortBy
+		if i := strings.LastIndexByte(sortBy, ':'); i >= 0 {
+			switch strings.ToLower(sortBy[i+1:]) {
+			case "desc":
+				result.Descending = true
+				name = sortBy[:i]
+			case "asc":
+				name = sortBy[:i]
+			}
+		}
+		result.SortBy = strings.TrimSpace(name)
+	}
+	for _, expr := range where {
+		c, err := NewRowCondition(expr)
+		if err != nil {
+			return nil, err
+		}
+		result.Where = append(result.Where, c)
+	}
+	for _, c := range columns {
+		if c = strings.TrimSpace(c); c != "" {
+			result.Columns = append(result.Columns, c)
+		}
+	}
+	return result, nil
+}
+
+// Active returns true if at least one option is set
+func (p *RowProcessor) Active() bool {
+	return p != nil && (p.SortBy != "" || len(p.Where) > 0 || len(p.Columns) > 0 || p.seen != nil || p.summary != nil)
+}
+
+// SetSeen enables the suppression of rows which have already been written,
+// e.g. by a previous run in watch mode. The set is updated with all written
+// rows including the header.
+func (p *RowProcessor) SetSeen(seen map[string]bool) {
+	p.seen = seen
+}
+
+// SetSummary enables the aggregation of all matching rows. The columns of the
+// summary refer to all columns, not only the selected ones.
+func (p *RowProcessor) SetSummary(s *Summary) {
+	p.summary = s
+}
+
+// Buffered returns true if all rows must be collected before writing, e.g.
+// for sorting
+func (p *RowProcessor) Buffered() bool {
+	return p.SortBy != ""
+}
+
+// SetAliases sets alternative column names, e.g. the short names of a
+// TableDescriptor if the header shows the long names
+func (p *RowProcessor) SetAliases(aliases []string) {
+	p.aliases = aliases
+}
+
+// index returns the index of the column with the passed name
+func (p *RowProcessor) index(name string) (int, error) {
+	for i, h := range p.header {
+		if strings.EqualFold(h, name) {
+			return i, nil
+		}
+	}
+	for i, a := range p.aliases {
+		if i < len(p.header) && strings.EqualFold(a, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("unknown column %q, available columns are %s", name, strings.Join(p.header, ", "))
+}
+
+func (p *RowProcessor) prepare(header []string) error {
+	p.header = header
+	if p.SortBy != "" {
+		i, err := p.index(p.SortBy)
+		if err != nil {
+			return err
+		}
+		p.sortIndex = i
+	}
+	for _, c := range p.Where {
+		i, err := p.index(c.Column)
+		if err != nil {
+			return err
+		}
+		c.index = i
+	}
+	if len(p.Columns) > 0 {
+		p.selected = make([]int, len(p.Columns))
+		for j, name := range p.Columns {
+			i, err := p.index(name)
+			if err != nil {
+				return err
+			}
+			p.selected[j] = i
+		}
+	}
+	if p.summary != nil {
+		return p.summary.prepare(p.index)
+	}
+	return nil
+}
+
+func (p *RowProcessor) selectColumns(v []string) []string {
+	if p.selected == nil {
+		return v
+	}
+	result := make([]string, len(p.selected))
+	for j, i := range p.selected {
+		if i < len(v) {
+			result[j] = v[i]
+		}
+	}
+	return result
+}
+
+// emit selects the columns and writes the row unless it has been seen before
+func (p *RowProcessor) emit(v []string, write func(...string) error) error {
+	v = p.selectColumns(v)
+	if p.seen != nil {
+		key := strings.Join(v, "\x00")
+		if p.seen[key] {
+			return nil
+		}
+		p.seen[key] = true
+	}
+	return write(v...)
+}
+
+func (p *RowProcessor) matches(v []string) bool {
+	for _, c := range p.Where {
+		value := ""
+		if c.index < len(v) {
+			value = v[c.index]
+		}
+		if !c.Matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Process handles one row and passes it to the write function unless the
+// row is filtered out or buffered for sorting
+func (p *RowProcessor) Process(v []string, write func(...string) error) error {
+	if p.header == nil {
+		if err := p.prepare(v); err != nil {
+			return err
+		}
+		return p.emit(v, write)
+	}
+	if !p.matches(v) {
+		return nil
+	}
+	if p.summary != nil {
+		p.summary.add(v)
+	}
+	if p.Buffered() {
+		p.rows = append(p.rows, v)
+		return nil
+	}
+	return p.emit(v, write)
+}
+
+// Finish sorts and writes all buffered rows
+func (p *RowProcessor) Finish(write func(...string) error) error {
+	if len(p.rows) == 0 {
+		return nil
+	}
+	i := p.sortIndex
+	sort.SliceStable(p.rows, func(a, b int) bool {
+		va, vb := "", ""
+		if i < len(p.rows[a]) {
+			va = p.rows[a][i]
+		}
+		if i < len(p.rows[b]) {
+			vb = p.rows[b][i]
+		}
+		if p.Descending {
+			return CompareValues(va, vb) > 0
+		}
+		return CompareValues(va, vb) < 0
+	})
+	for _, v := range p.rows {
+		if err := p.emit(v, write); err != nil {
+			return err
+		}
+	}
+	p.rows = nil
+	return nil
+}
@@ -0,0 +1,211 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewRowCondition(t *testing.T) {
+	tests := []struct {
+		expr   string
+		column string
+		op     string
+		value  string
+	}{
+		{"STATE = SUCCESS", "STATE", "=", "SUCCESS"},
+		{"STATE==SUCCESS", "STATE", "=", "SUCCESS"},
+		{"SIZE >= 10", "SIZE", ">=", "10"},
+		{"NAME =~ ^a.*", "NAME", "=~", "^a.*"},
+		{"NAME != 'a b'", "NAME", "!=", "a b"},
+		{"CREATED AT < 5", "CREATED AT", "<", "5"},
+	}
+	for _, test := range tests {
+		c, err := NewRowCondition(test.expr)
+		if err != nil {
+			t.Errorf("NewRowCondition(%q) = %v", test.expr, err)
+			continue
+		}
+		if c.Column != test.column || c.Op != test.op || c.Value != test.value {
+			t.Errorf("NewRowCondition(%q) = %q %q %q, want %q %q %q", test.expr, c.Column, c.Op, c.Value, test.column, test.op, test.value)
+		}
+	}
+	for _, expr := range []string{"STATE", "", "NAME =~ ("} {
+		if _, err := NewRowCondition(expr); err == nil {
+			t.Errorf("NewRowCondition(%q) expected error", expr)
+		}
+	}
+}
+
+func TestRowConditionMatches(t *testing.T) {
+	tests := []struct {
+		expr  string
+		value string
+		want  bool
+	}{
+		{"A = x", "x", true},
+		{"A = x", "y", false},
+		{"A != x", "y", true},
+		{"A < 10", "9", true},
+		{"A < 10", "10", false},
+		{"A <= 10", "10", true},
+		{"A > 10", "9.5", false},
+		{"A >= 10", "100", true},
+		{"A > b", "c", true},
+		{"A =~ ^fa", "failed", true},
+		{"A !~ ^fa", "failed", false},
+	}
+	for _, test := range tests {
+		c, err := NewRowCondition(test.expr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := c.Matches(test.value); result != test.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", test.expr, test.value, result, test.want)
+		}
+	}
+}
+
+func TestCompareValues(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2", "10", -1},
+		{"10", "2", 1},
+		{" 1.0", "1", 0},
+		{"b", "a", 1},
+		{"10", "a", -1},
+		{"", "", 0},
+	}
+	for _, test := range tests {
+		if result := CompareValues(test.a, test.b); result != test.want {
+			t.Errorf("CompareValues(%q, %q) = %d, want %d", test.a, test.b, result, test.want)
+		}
+	}
+}
+
+// process writes the rows with the processor and returns the CSV lines
+func process(t *testing.T, p *RowProcessor, rows [][]string) (string, error) {
+	t.Helper()
+	var result []string
+	write := func(v ...string) error {
+		result = append(result, strings.Join(v, ","))
+		return nil
+	}
+	for _, row := range rows {
+		if err := p.Process(row, write); err != nil {
+			return "", err
+		}
+	}
+	err := p.Finish(write)
+	return strings.Join(result, "\n"), err
+}
+
+func TestRowProcessor(t *testing.T) {
+	rows := [][]string{
+		{"ID", "STATE", "SIZE"},
+		{"1", "success", "20"},
+		{"2", "failed", "5"},
+		{"3", "success", "100"},
+	}
+	tests := []struct {
+		sortBy  string
+		where   []string
+		columns []string
+		want    string
+	}{
+		{"", nil, nil, "ID,STATE,SIZE\n1,success,20\n2,failed,5\n3,success,100"},
+		{"SIZE", nil, nil, "ID,STATE,SIZE\n2,failed,5\n1,success,20\n3,success,100"},
+		{"size:desc", nil, nil, "ID,STATE,SIZE\n3,success,100\n1,success,20\n2,failed,5"},
+		{"", []string{"STATE = success", "SIZE > 50"}, nil, "ID,STATE,SIZE\n3,success,100"},
+		{"", nil, []string{"SIZE", " ID"}, "SIZE,ID\n20,1\n5,2\n100,3"},
+		{"STATE", []string{"SIZE < 50"}, []string{"ID"}, "ID\n2\n1"},
+	}
+	for _, test := range tests {
+		p, err := NewRowProcessor(test.sortBy, test.where, test.columns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := process(t, p, rows)
+		if err != nil {
+			t.Errorf("Process(%q, %q, %q) = %v", test.sortBy, test.where, test.columns, err)
+			continue
+		}
+		if result != test.want {
+			t.Errorf("Process(%q, %q, %q) = %q, want %q", test.sortBy, test.where, test.columns, result, test.want)
+		}
+	}
+}
+
+func TestRowProcessorUnknownColumn(t *testing.T) {
+	for _, p := range []func() (*RowProcessor, error){
+		func() (*RowProcessor, error) { return NewRowProcessor("NAME", nil, nil) },
+		func() (*RowProcessor, error) { return NewRowProcessor("", []string{"NAME = x"}, nil) },
+		func() (*RowProcessor, error) { return NewRowProcessor("", nil, []string{"NAME"}) },
+	} {
+		rp, err := p()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = process(t, rp, [][]string{{"ID"}, {"1"}}); err == nil {
+			t.Errorf("expected unknown column error")
+		}
+	}
+}
+
+func TestRowProcessorAliases(t *testing.T) {
+	p, err := NewRowProcessor("", []string{"state = failed"}, []string{"id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetAliases([]string{"id", "state"})
+	result, err := process(t, p, [][]string{{"DATASET ID", "CURRENT STATE"}, {"1", "success"}, {"2", "failed"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "DATASET ID\n2"; result != want {
+		t.Errorf("Process() = %q, want %q", result, want)
+	}
+}
+
+func TestRowProcessorSeen(t *testing.T) {
+	seen := make(map[string]bool)
+	rows := [][]string{{"ID", "STATE"}, {"1", "running"}, {"2", "running"}}
+	wants := []string{"ID,STATE\n1,running\n2,running", "3,success"}
+	for i, want := range wants {
+		p, err := NewRowProcessor("", nil, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SetSeen(seen)
+		if !p.Active() {
+			t.Errorf("Active() = false with seen rows")
+		}
+		if i == 1 {
+			rows = [][]string{{"ID", "STATE"}, {"1", "running"}, {"3", "success"}}
+		}
+		result, err := process(t, p, rows)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result != want {
+			t.Errorf("run %d: Process() = %q, want %q", i, result, want)
+		}
+	}
+}
@@ -295,3 +295,88 @@ func Truncate(value string, max int) string {
 	sb.WriteString("...")
 	return sb.String()
 }
+
+// Ellipsis shortens the value to max runes and marks the cut with …
+func Ellipsis(value string, max int) string {
+	r := []rune(value)
+	if len(r) <= max {
+		return value
+	}
+	if max <= 1 {
+		return "…"
+	}
+	return string(r[:max-1]) + "…"
+}
+
+// GroupDigits inserts the separator between groups of thousands in the
+// integer part of a number, e.g. 1234567.5 becomes 1,234,567.5
+func GroupDigits(value, sep string) string {
+	start := 0
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		start = 1
+	}
+	end := strings.IndexByte(value, '.')
+	if end < 0 {
+		end = len(value)
+	}
+	digits := value[start:end]
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return value
+		}
+	}
+	l := len(digits)
+	if l <= 3 {
+		return value
+	}
+	var sb strings.Builder
+	sb.WriteString(value[:start])
+	first := l % 3
+	if first > 0 {
+		sb.WriteString(digits[:first])
+	}
+	for i := first; i < l; i += 3 {
+		if i > 0 {
+			sb.WriteString(sep)
+		}
+		sb.WriteString(digits[i : i+3])
+	}
+	sb.WriteString(value[end:])
+	return sb.String()
+}
+
+// RelativeTime returns the duration between now and the passed time in a
+// short form, e.g. 3h ago or in 5m
+func RelativeTime(t time.Time) string {
+	d := time.Since(t)
+	format := "%v ago"
+	if d < 0 {
+		d = -d
+		format = "in %v"
+	}
+	var v string
+	switch {
+	case d < time.Minute:
+		v = strconv.Itoa(int(d/time.Second)) + "s"
+	case d < time.Hour:
+		v = strconv.Itoa(int(d/time.Minute)) + "m"
+	case d < 24*time.Hour:
+		v = strconv.Itoa(int(d/time.Hour)) + "h"
+	case d < 30*24*time.Hour:
+		v = strconv.Itoa(int(d/(24*time.Hour))) + "d"
+	case d < 365*24*time.Hour:
+		v = strconv.Itoa(int(d/(30*24*time.Hour))) + "mo"
+	default:
+		v = strconv.Itoa(int(d/(365*24*time.Hour))) + "y"
+	}
+	return fmt.Sprintf(format, v)
+}
+
+// RelativeTimeStr converts a time string in RFC3339 to a relative time
+func RelativeTimeStr(str string) string {
+	t, err := time.Parse(time.RFC3339, str)
+	if err != nil {
+		return str
+	}
+	return RelativeTime(t)
+}
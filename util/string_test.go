@@ -0,0 +1,105 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGroupDigits(t *testing.T) {
+	tests := []struct {
+		value, sep, want string
+	}{
+		{"", ",", ""},
+		{"0", ",", "0"},
+		{"123", ",", "123"},
+		{"1234", ",", "1,234"},
+		{"123456", ",", "123,456"},
+		{"1234567", ",", "1,234,567"},
+		{"1234567.5", ",", "1,234,567.5"},
+		{"1234.5678", ",", "1,234.5678"},
+		{"-1234567", ",", "-1,234,567"},
+		{"+12345", ",", "+12,345"},
+		{"-123", ",", "-123"},
+		{"1234567", ".", "1.234.567"},
+		{"12a4567", ",", "12a4567"},
+		{"1e10", ",", "1e10"},
+		{"n/a", ",", "n/a"},
+	}
+	for _, tt := range tests {
+		if got := GroupDigits(tt.value, tt.sep); got != tt.want {
+			t.Errorf("GroupDigits(%q, %q) = %q, want %q", tt.value, tt.sep, got, tt.want)
+		}
+	}
+}
+
+func TestEllipsis(t *testing.T) {
+	tests := []struct {
+		value string
+		max   int
+		want  string
+	}{
+		{"", 5, ""},
+		{"short", 5, "short"},
+		{"shorter", 10, "shorter"},
+		{"too long", 5, "too …"},
+		{"too long", 1, "…"},
+		{"too long", 0, "…"},
+		{"äöüäöü", 4, "äöü…"},
+	}
+	for _, tt := range tests {
+		if got := Ellipsis(tt.value, tt.max); got != tt.want {
+			t.Errorf("Ellipsis(%q, %v) = %q, want %q", tt.value, tt.max, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	// the offsets keep a safe distance to the unit boundaries because the
+	// current time moves on during the test
+	tests := []struct {
+		offset time.Duration
+		want   string
+	}{
+		{-10 * time.Second, "10s ago"},
+		{-90 * time.Second, "1m ago"},
+		{-150 * time.Minute, "2h ago"},
+		{-36 * time.Hour, "1d ago"},
+		{-75 * 24 * time.Hour, "2mo ago"},
+		{-800 * 24 * time.Hour, "2y ago"},
+		{90 * time.Minute, "in 1h"},
+		{50 * time.Hour, "in 2d"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(time.Now().Add(tt.offset)); got != tt.want {
+			t.Errorf("RelativeTime(now%+v) = %q, want %q", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestRelativeTimeStr(t *testing.T) {
+	str := time.Now().Add(-3*time.Hour - time.Minute).Format(time.RFC3339)
+	if got := RelativeTimeStr(str); got != "3h ago" {
+		t.Errorf("RelativeTimeStr(%q) = %q, want %q", str, got, "3h ago")
+	}
+	for _, str := range []string{"", "-", "yesterday"} {
+		if got := RelativeTimeStr(str); got != str {
+			t.Errorf("RelativeTimeStr(%q) = %q, want %q", str, got, str)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// All color sequences have the same length, thus the tabwriter alignment is
+// not affected if all cells of a column are wrapped.
+var ansiColors = map[string]string{
+	"black":          "\x1b[30m",
+	"red":            "\x1b[31m",
+	"green":          "\x1b[32m",
+	"yellow":         "\x1b[33m",
+	"blue":           "\x1b[34m",
+	"magenta":        "\x1b[35m",
+	"cyan":           "\x1b[36m",
+	"white":          "\x1b[37m",
+	"gray":           "\x1b[90m",
+	"bright-red":     "\x1b[91m",
+	"bright-green":   "\x1b[92m",
+	"bright-yellow":  "\x1b[93m",
+	"bright-blue":    "\x1b[94m",
+	"bright-magenta": "\x1b[95m",
+	"bright-cyan":    "\x1b[96m",
+	"bright-white":   "\x1b[97m",
+}
+
+const (
+	ansiDefault = "\x1b[39m"
+	ansiReset   = "\x1b[0m"
+)
+
+// ColorEnabled returns true if the standard output is a terminal and colors
+// are not disabled by the environment variable NO_COLOR (see no-color.org)
+func ColorEnabled() bool {
+	if _, found := os.LookupEnv("NO_COLOR"); found {
+		return false
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return IsTerminal(os.Stdout)
+}
+
+// Paint wraps the text in the escape sequences of the named color
+func Paint(color, text string) string {
+	return ansiColors[color] + text + ansiReset
+}
+
+// IsTerminal returns true if the passed file is a character device, e.g. a
+// terminal
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// ValueMatcher matches a value either by the value (case-insensitive) or by
+// the regular expression
+type ValueMatcher struct {
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+	Match string `json:"match,omitempty" yaml:"match,omitempty"`
+	re    *regexp.Regexp
+}
+
+func (m *ValueMatcher) init() error {
+	if m.Match != "" {
+		re, err := regexp.Compile(m.Match)
+		if err != nil {
+			return err
+		}
+		m.re = re
+	}
+	return nil
+}
+
+func (m *ValueMatcher) matches(value string) bool {
+	if m.re != nil {
+		return m.re.MatchString(value)
+	}
+	return strings.EqualFold(m.Value, strings.TrimSpace(value))
+}
+
+// TableColumnStyle assigns a color to all matching values
+type TableColumnStyle struct {
+	ValueMatcher `yaml:",inline"`
+	Color        string `json:"color" yaml:"color"`
+}
+
+func (s *TableColumnStyle) init() error {
+	if _, ok := ansiColors[s.Color]; !ok {
+		return fmt.Errorf("unknown color %s", s.Color)
+	}
+	return s.ValueMatcher.init()
+}
+
+// ColumnStyles maps column names to styles
+type ColumnStyles map[string][]*TableColumnStyle
+
+// color returns the escape sequence for the first matching style or the
+// sequence for the default color
+func color(styles []*TableColumnStyle, value string) string {
+	for _, s := range styles {
+		if s.matches(value) {
+			return ansiColors[s.Color]
+		}
+	}
+	return ansiDefault
+}
@@ -0,0 +1,134 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func newStyles(t *testing.T, styles ...*TableColumnStyle) []*TableColumnStyle {
+	t.Helper()
+	for _, s := range styles {
+		if err := s.init(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return styles
+}
+
+func TestTableColumnStyleInit(t *testing.T) {
+	tests := []struct {
+		style *TableColumnStyle
+		err   bool
+	}{
+		{&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "success"}, Color: "green"}, false},
+		{&TableColumnStyle{ValueMatcher: ValueMatcher{Match: "^fail"}, Color: "bright-red"}, false},
+		{&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "success"}, Color: "pink"}, true},
+		{&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "success"}}, true},
+		{&TableColumnStyle{ValueMatcher: ValueMatcher{Match: "(fail"}, Color: "red"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.style.init(); (err != nil) != tt.err {
+			t.Errorf("init() of %+v returned %v, want error %v", tt.style, err, tt.err)
+		}
+	}
+}
+
+func TestValueMatcher(t *testing.T) {
+	tests := []struct {
+		matcher ValueMatcher
+		value   string
+		want    bool
+	}{
+		{ValueMatcher{Value: "success"}, "success", true},
+		{ValueMatcher{Value: "success"}, "SUCCESS", true},
+		{ValueMatcher{Value: "success"}, " success\t", true},
+		{ValueMatcher{Value: "success"}, "succeeded", false},
+		{ValueMatcher{Value: ""}, "", true},
+		{ValueMatcher{Match: "^fail"}, "failed", true},
+		{ValueMatcher{Match: "^fail"}, "not failed", false},
+		{ValueMatcher{Value: "ignored", Match: "^[0-9]+$"}, "ignored", false},
+		{ValueMatcher{Value: "ignored", Match: "^[0-9]+$"}, "42", true},
+	}
+	for _, tt := range tests {
+		m := tt.matcher
+		if err := m.init(); err != nil {
+			t.Fatal(err)
+		}
+		if got := m.matches(tt.value); got != tt.want {
+			t.Errorf("%+v matches(%q) = %v, want %v", tt.matcher, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestColor(t *testing.T) {
+	styles := newStyles(t,
+		&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "success"}, Color: "green"},
+		&TableColumnStyle{ValueMatcher: ValueMatcher{Match: "fail"}, Color: "red"},
+		&TableColumnStyle{ValueMatcher: ValueMatcher{Match: "."}, Color: "gray"},
+	)
+	tests := []struct {
+		value, want string
+	}{
+		{"success", ansiColors["green"]},
+		{"failed", ansiColors["red"]},
+		// the first matching style wins
+		{"fail", ansiColors["red"]},
+		{"running", ansiColors["gray"]},
+		{"", ansiDefault},
+	}
+	for _, tt := range tests {
+		if got := color(styles, tt.value); got != tt.want {
+			t.Errorf("color(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+	if got := color(nil, "success"); got != ansiDefault {
+		t.Errorf("color(nil) = %q, want %q", got, ansiDefault)
+	}
+}
+
+func TestRowWriterStyles(t *testing.T) {
+	styles := ColumnStyles{"STATE": newStyles(t,
+		&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "success"}, Color: "green"},
+		&TableColumnStyle{ValueMatcher: ValueMatcher{Value: "failed"}, Color: "red"},
+	)}
+	var sb strings.Builder
+	w := NewTableWriter(&sb).SetStyles(styles)
+	for _, row := range [][]string{
+		{"ID", "STATE"},
+		{"1", "success"},
+		{"2", "failed"},
+		{"3", "queued"},
+	} {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Join([]string{
+		"ID " + ansiDefault + "STATE" + ansiReset,
+		"1  " + ansiColors["green"] + "success" + ansiReset,
+		"2  " + ansiColors["red"] + "failed" + ansiReset,
+		"3  " + ansiDefault + "queued" + ansiReset,
+	}, "\n") + "\n"
+	if got := sb.String(); got != want {
+		t.Errorf("sb.String() = %q, want %q", got, want)
+	}
+}
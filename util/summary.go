@@ -0,0 +1,259 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SummaryDescriptor defines an aggregation of a column. Supported functions
+// are count, sum, avg, min and max. count needs no column.
+type SummaryDescriptor struct {
+	Name   string `json:"name,omitempty" yaml:"name,omitempty"`
+	Func   string `json:"func" yaml:"func"`
+	Column string `json:"column,omitempty" yaml:"column,omitempty"`
+	index  int
+}
+
+// init validates the aggregation
+func (d *SummaryDescriptor) init() error {
+	switch d.Func {
+	case "count":
+	case "sum", "avg", "min", "max":
+		if d.Column == "" {
+			return fmt.Errorf("summary function %s requires a column", d.Func)
+		}
+	default:
+		return fmt.Errorf("unknown summary function %q", d.Func)
+	}
+	return nil
+}
+
+// header returns the name or a generated name, e.g. SUM(SIZE)
+func (d *SummaryDescriptor) header() string {
+	switch {
+	case d.Name != "":
+		return d.Name
+	case d.Column == "":
+		return strings.ToUpper(d.Func)
+	}
+	return strings.ToUpper(d.Func) + "(" + d.Column + ")"
+}
+
+// Summary aggregates rendered rows, optionally grouped by the values of
+// columns. The first added row must be the header.
+type Summary struct {
+	GroupBy      []string
+	Aggregations []*SummaryDescriptor
+	groupIndex   []int
+	groups       map[string]*summaryGroup
+	prepared     bool
+}
+
+// summaryGroup contains the aggregated values of a group
+type summaryGroup struct {
+	keys   []string
+	count  int
+	sums   []float64
+	counts []int // number of numeric values for avg
+	values []string
+}
+
+// NewSummary creates an initialized Summary. Without aggregations the rows
+// are counted.
+func NewSummary(groupBy []string, aggregations []*SummaryDescriptor) (*Summary, error) {
+	if len(aggregations) == 0 {
+		aggregations = []*SummaryDescriptor{{Func: "count"}}
+	}
+	for _, a := range aggregations {
+		if err := a.init(); err != nil {
+			return nil, err
+		}
+	}
+	result := &Summary{
+		Aggregations: aggregations,
+		groups:       make(map[string]*summaryGroup),
+	}
+	for _, g := range groupBy {
+		if g = strings.TrimSpace(g); g != "" {
+			result.GroupBy = append(result.GroupBy, g)
+		}
+	}
+	return result, nil
+}
+
+// prepare resolves the column names with the index function
+func (s *Summary) prepare(index func(string) (int, error)) error {
+	s.prepared = true
+	s.groupIndex = make([]int, len(s.GroupBy))
+	for i, name := range s.GroupBy {
+		j, err := index(name)
+		if err != nil {
+			return err
+		}
+		s.groupIndex[i] = j
+	}
+	for _, a := range s.Aggregations {
+		if a.Column == "" {
+			continue
+		}
+		j, err := index(a.Column)
+		if err != nil {
+			return err
+		}
+		a.index = j
+	}
+	return nil
+}
+
+// parseNumber converts a rendered value to a number, digit group separators
+// are ignored
+func parseNumber(v string) (float64, bool) {
+	f, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(v), ",", ""), 64)
+	return f, err == nil
+}
+
+// compareSummaryValues compares numbers with digit group separators
+// numerically, other values like CompareValues
+func compareSummaryValues(a, b string) int {
+	fa, oka := parseNumber(a)
+	fb, okb := parseNumber(b)
+	if oka && okb {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return CompareValues(a, b)
+}
+
+// add aggregates the row
+func (s *Summary) add(v []string) {
+	value := func(i int) string {
+		if i < len(v) {
+			return v[i]
+		}
+		return ""
+	}
+	keys := make([]string, len(s.groupIndex))
+	for i, j := range s.groupIndex {
+		keys[i] = value(j)
+	}
+	key := strings.Join(keys, "\x00")
+	g, found := s.groups[key]
+	if !found {
+		l := len(s.Aggregations)
+		g = &summaryGroup{
+			keys:   keys,
+			sums:   make([]float64, l),
+			counts: make([]int, l),
+			values: make([]string, l),
+		}
+		s.groups[key] = g
+	}
+	g.count++
+	for i, a := range s.Aggregations {
+		if a.Column == "" {
+			continue
+		}
+		c := value(a.index)
+		if c == "" || c == "-" {
+			continue
+		}
+		switch a.Func {
+		case "sum", "avg":
+			if f, ok := parseNumber(c); ok {
+				g.sums[i] += f
+				g.counts[i]++
+			}
+		case "min", "max":
+			if g.values[i] == "" {
+				g.values[i] = c
+				break
+			}
+			r := compareSummaryValues(c, g.values[i])
+			if (a.Func == "min" && r < 0) || (a.Func == "max" && r > 0) {
+				g.values[i] = c
+			}
+		}
+	}
+}
+
+// Write writes the summary as table, one row for each group sorted by the
+// group values
+func (s *Summary) Write(w *RowWriter) error {
+	header := make([]string, 0, len(s.GroupBy)+len(s.Aggregations))
+	for _, g := range s.GroupBy {
+		header = append(header, strings.ToUpper(g))
+	}
+	for _, a := range s.Aggregations {
+		header = append(header, a.header())
+	}
+	if err := w.Write(header...); err != nil {
+		return err
+	}
+	groups := make([]*summaryGroup, 0, len(s.groups))
+	for _, g := range s.groups {
+		groups = append(groups, g)
+	}
+	// a summary without rows and groups has one row with zero values
+	if len(groups) == 0 && len(s.GroupBy) == 0 {
+		l := len(s.Aggregations)
+		groups = append(groups, &summaryGroup{sums: make([]float64, l), counts: make([]int, l), values: make([]string, l)})
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		for k := range groups[i].keys {
+			if r := compareSummaryValues(groups[i].keys[k], groups[j].keys[k]); r != 0 {
+				return r < 0
+			}
+		}
+		return false
+	})
+	for _, g := range groups {
+		row := append([]string{}, g.keys...)
+		for i, a := range s.Aggregations {
+			var v string
+			switch a.Func {
+			case "count":
+				v = strconv.Itoa(g.count)
+			case "sum":
+				v = strconv.FormatFloat(g.sums[i], 'f', -1, 64)
+			case "avg":
+				v = "-"
+				if g.counts[i] > 0 {
+					v = strconv.FormatFloat(g.sums[i]/float64(g.counts[i]), 'f', 2, 64)
+				}
+			default:
+				v = g.values[i]
+				if v == "" {
+					v = "-"
+				}
+			}
+			row = append(row, v)
+		}
+		if err := w.Write(row...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+/*
+Package util util consists of general utility functions and structures.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package util
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSummary(t *testing.T) {
+	s, err := NewSummary([]string{"state"}, []*SummaryDescriptor{
+		{Func: "count"},
+		{Func: "sum", Column: "SIZE"},
+		{Func: "max", Column: "SIZE", Name: "LARGEST"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := NewRowProcessor("", []string{"SIZE != 0"}, []string{"ID"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.SetSummary(s)
+	var sb strings.Builder
+	w := NewCSVWriter(&sb).SetProcessor(p)
+	for _, row := range [][]string{
+		{"ID", "STATE", "SIZE"},
+		{"1", "success", "1,000"},
+		{"2", "failed", "5"},
+		{"3", "success", "20"},
+		{"4", "success", "0"},
+	} {
+		if err := w.Write(row...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Write(NewCSVWriter(&sb)); err != nil {
+		t.Fatal(err)
+	}
+	want := "ID\n1\n2\n3\nSTATE,COUNT,SUM(SIZE),LARGEST\nfailed,1,5,5\nsuccess,2,1020,\"1,000\"\n"
+	if result := sb.String(); result != want {
+		t.Errorf(`sb.String() = %q, want %q`, result, want)
+	}
+}
+
+func TestSummaryErrors(t *testing.T) {
+	if _, err := NewSummary(nil, []*SummaryDescriptor{{Func: "median", Column: "A"}}); err == nil {
+		t.Error("NewSummary with unknown function succeeded, want error")
+	}
+	if _, err := NewSummary(nil, []*SummaryDescriptor{{Func: "sum"}}); err == nil {
+		t.Error("NewSummary with sum without column succeeded, want error")
+	}
+}
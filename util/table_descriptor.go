@@ -19,6 +19,7 @@ package util
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -37,8 +38,14 @@ type TableDescriptor struct {
 	Filter []string          `json:"filter,omitempty" yaml:"filter,omitempty"`
 	Vars   []*DescriptorVars `json:"vars,omitempty" yaml:"vars,omitempty"`
 	Range  *DescriptorRange  `json:"range,omitempty" yaml:"range,omitempty"`
-	thin   []*TableColumnDescriptor
-	wide   []*TableColumnDescriptor
+	// GroupBy and Summary define the default summary of --summary
+	GroupBy []string             `json:"groupBy,omitempty" yaml:"groupBy,omitempty"`
+	Summary []*SummaryDescriptor `json:"summary,omitempty" yaml:"summary,omitempty"`
+	thin    []*TableColumnDescriptor
+	wide    []*TableColumnDescriptor
+	rows    int // number of written rows
+	done    int // number of written rows with a terminal state
+	exit    int // highest exit code of all terminal states
 }
 
 // NewTableDescriptor creates an initialzed TableDescriptor. It accpets a
@@ -48,53 +55,85 @@ func NewTableDescriptor(def string) (*TableDescriptor, error) {
 	if err := yaml.Unmarshal([]byte(def), &result); err != nil {
 		return nil, err
 	}
-	if result.Iter == "" {
-		result.Iter = "array"
+	if err := result.init(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// WithColumns creates a copy of this TableDescriptor with the passed columns.
+// The path, iterator, variables, mappings and range of the original
+// descriptor are kept, thus the new columns are relative to the same objects.
+func (t *TableDescriptor) WithColumns(cols []*TableColumnDescriptor) (*TableDescriptor, error) {
+	result := *t
+	if t.Range != nil {
+		r := *t.Range
+		r.Columns = cols
+		result.Range = &r
+		result.Columns = nil
+	} else {
+		result.Columns = cols
+	}
+	if err := result.init(); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// init validates the descriptor and prepares the columns
+func (td *TableDescriptor) init() error {
+	if td.Iter == "" {
+		td.Iter = "array"
 	}
-	switch result.Iter {
+	switch td.Iter {
 	case "filter":
-		if len(result.Filter) == 0 {
-			return nil, errors.New("Iterator type filter requires filter attribute")
+		if len(td.Filter) == 0 {
+			return errors.New("Iterator type filter requires filter attribute")
 		}
 	case "object", "array":
-		if len(result.Filter) > 0 {
-			return nil, fmt.Errorf("Iterator type %v does not support filter attribute", result.Iter)
+		if len(td.Filter) > 0 {
+			return fmt.Errorf("Iterator type %v does not support filter attribute", td.Iter)
 		}
 	}
-	if len(result.Columns) > 0 && result.Range != nil {
-		return nil, errors.New("columns and range are defined")
+	if len(td.Columns) > 0 && td.Range != nil {
+		return errors.New("columns and range are defined")
+	}
+	for _, s := range td.Summary {
+		if err := s.init(); err != nil {
+			return err
+		}
 	}
 	var (
 		l        int
 		varTypes map[string]string
 		cols     []*TableColumnDescriptor
 	)
-	if result.Range != nil {
+	if td.Range != nil {
 		// range rows
-		varTypes = make(map[string]string, len(result.Vars)+len(result.Range.Vars))
-		for _, v := range result.Vars {
+		varTypes = make(map[string]string, len(td.Vars)+len(td.Range.Vars))
+		for _, v := range td.Vars {
 			varTypes[v.Name] = v.Type
 		}
-		for _, v := range result.Range.Vars {
+		for _, v := range td.Range.Vars {
 			varTypes[v.Name] = v.Type
 		}
-		cols = result.Range.Columns
+		cols = td.Range.Columns
 	} else {
 		// simple column
-		varTypes = make(map[string]string, len(result.Vars))
-		for _, v := range result.Vars {
+		varTypes = make(map[string]string, len(td.Vars))
+		for _, v := range td.Vars {
 			varTypes[v.Name] = v.Type
 		}
-		cols = result.Columns
+		cols = td.Columns
 	}
 	l = len(cols)
 	w := make([]*TableColumnDescriptor, 0, l)
 	t := make([]*TableColumnDescriptor, 0, l)
 	// initialize columns
 	for i, c := range cols {
-		c.parent = result
+		c.parent = td
 		if c.Name == "" {
-			return nil, fmt.Errorf("name is empty in column %v", i)
+			return fmt.Errorf("name is empty in column %v", i)
 		}
 		// determine column type
 		if c.Meta == "" {
@@ -111,7 +150,34 @@ func NewTableDescriptor(def string) (*TableDescriptor, error) {
 		}
 		if c.Query != nil {
 			if err := c.Query.Prepare(); err != nil {
-				return nil, err
+				return err
+			}
+		}
+		if c.Expr != "" {
+			expr, err := ParseExpression(c.Expr)
+			if err != nil {
+				return fmt.Errorf("invalid expression in column %v: %v", c.Name, err)
+			}
+			c.expr = expr
+		}
+		if c.Format == "regex" {
+			if len(c.Parameters) == 0 {
+				return fmt.Errorf("format regex requires a regular expression in column %v", c.Name)
+			}
+			re, err := regexp.Compile(c.Parameters[0])
+			if err != nil {
+				return err
+			}
+			c.re = re
+		}
+		for _, st := range c.Styles {
+			if err := st.init(); err != nil {
+				return fmt.Errorf("%v in column %v", err, c.Name)
+			}
+		}
+		for _, st := range c.Terminal {
+			if err := st.init(); err != nil {
+				return fmt.Errorf("%v in column %v", err, c.Name)
 			}
 		}
 		switch c.Mode {
@@ -124,9 +190,9 @@ func NewTableDescriptor(def string) (*TableDescriptor, error) {
 			w = append(w, c)
 		}
 	}
-	result.thin = t
-	result.wide = w
-	return result, nil
+	td.thin = t
+	td.wide = w
+	return nil
 }
 
 // Header extracts the table header
@@ -147,6 +213,72 @@ func (t *TableDescriptor) Header(wide bool) []string {
 	return result
 }
 
+// Names returns the short names of the columns. They differ from the header
+// if long names are used for wide output.
+func (t *TableDescriptor) Names(wide bool) []string {
+	cols := t.thin
+	if wide {
+		cols = t.wide
+	}
+	result := make([]string, len(cols))
+	for i, c := range cols {
+		result[i] = c.Name
+	}
+	return result
+}
+
+// Styles returns the styles of all columns with at least one style. The
+// header names are used as keys.
+func (t *TableDescriptor) Styles(wide bool) ColumnStyles {
+	cols := t.thin
+	if wide {
+		cols = t.wide
+	}
+	result := make(ColumnStyles)
+	for _, c := range cols {
+		if len(c.Styles) == 0 {
+			continue
+		}
+		result[c.Name] = c.Styles
+		if wide && c.Long != "" {
+			result[c.Long] = c.Styles
+		}
+	}
+	return result
+}
+
+// track counts the written rows and the rows with a terminal state
+func (t *TableDescriptor) track(cols []*TableColumnDescriptor, out []string) {
+	t.rows++
+	terminal := false
+	for i, c := range cols {
+		for _, st := range c.Terminal {
+			if st.matches(out[i]) {
+				terminal = true
+				if st.Exit > t.exit {
+					t.exit = st.Exit
+				}
+				break
+			}
+		}
+	}
+	if terminal {
+		t.done++
+	}
+}
+
+// Terminal returns true if at least one row has been written and all written
+// rows have reached a terminal state. The second value is the highest exit
+// code of the terminal states.
+func (t *TableDescriptor) Terminal() (bool, int) {
+	return t.rows > 0 && t.done == t.rows, t.exit
+}
+
+// ResetTerminal resets the counters of the terminal states
+func (t *TableDescriptor) ResetTerminal() {
+	t.rows, t.done, t.exit = 0, 0, 0
+}
+
 // Preprocess goes down the path and enters the list or object
 func (t *TableDescriptor) Preprocess(i JSONResponse) error {
 	if len(t.Path) == 1 && t.Path[0] == "$" {
@@ -172,6 +304,12 @@ func processColumns(scope *Scope, cols []*TableColumnDescriptor, q *Query) []str
 		default:
 			value = c.Extract(scope, q)
 		}
+		if c.Default != "" && (value == "" || value == "-") {
+			value = c.Default
+		}
+		if c.Width > 0 {
+			value = Ellipsis(value, c.Width)
+		}
 		result[i] = strings.Replace(value, "\t", " ", -1)
 	}
 	return result
@@ -186,6 +324,7 @@ func (t *TableDescriptor) WriteRow(q *Query, w *RowWriter, wide bool) error {
 	s := NewScope(nil, t.Vars, t.Mappings, q)
 	if t.Range == nil {
 		out := processColumns(s, cols, q)
+		t.track(cols, out)
 		return w.Write(out...)
 	}
 	r := t.Range
@@ -198,6 +337,7 @@ func (t *TableDescriptor) WriteRow(q *Query, w *RowWriter, wide bool) error {
 		return qp.RangeIE(func(_ int, qs *Query) error {
 			ss := NewScope(s, r.Vars, nil, qs)
 			out := processColumns(ss, cols, qs)
+			t.track(cols, out)
 			if r.Post != nil {
 				for _, v := range r.Post.Vars {
 					ss.Set(v.Name, v.Value)
@@ -210,6 +350,7 @@ func (t *TableDescriptor) WriteRow(q *Query, w *RowWriter, wide bool) error {
 	return qp.RangeAttributesE(func(_ string, qs *Query) error {
 		ss := NewScope(s, r.Vars, nil, qs)
 		out := processColumns(ss, cols, qs)
+		t.track(cols, out)
 		if r.Post != nil {
 			for _, v := range r.Post.Vars {
 				ss.Set(v.Name, v.Value)
@@ -237,20 +378,34 @@ func (t *TableDescriptor) AddMapping(name string, m Mapper) {
 	t.Mappings[name] = m
 }
 
+// TableColumnState defines a terminal state of a resource, e.g. SUCCESS or
+// FAILED, and the exit code of the watch mode
+type TableColumnState struct {
+	ValueMatcher `yaml:",inline"`
+	Exit         int `json:"exit" yaml:"exit"`
+}
+
 // TableColumnDescriptor contains all information to extract a column value
 type TableColumnDescriptor struct {
-	Name       string            `json:"name" yaml:"name"`
-	Long       string            `json:"long" yaml:"long"`
-	Type       string            `json:"type" yaml:"type"`
-	Meta       string            `json:"meta,omitempty" yaml:"meta,omitempty"`
-	Path       []string          `json:"path,omitempty" yaml:"path,omitempty"`
-	AltPath    []string          `json:"altPath,omitempty" yaml:"altPath,omitempty"`
-	Format     string            `json:"format,omitempty" yaml:"format,omitempty"`
-	Parameters []string          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
-	Var        string            `json:"var,omitempty" yaml:"var,omitempty"`
-	Mode       string            `json:"mode,omitempty" yaml:"mode,omitempty"`
-	Query      *QueryDescription `json:"query,omitempty" yaml:"query,omitempty"`
+	Name       string              `json:"name" yaml:"name"`
+	Long       string              `json:"long" yaml:"long"`
+	Type       string              `json:"type" yaml:"type"`
+	Meta       string              `json:"meta,omitempty" yaml:"meta,omitempty"`
+	Path       []string            `json:"path,omitempty" yaml:"path,omitempty"`
+	AltPath    []string            `json:"altPath,omitempty" yaml:"altPath,omitempty"`
+	Format     string              `json:"format,omitempty" yaml:"format,omitempty"`
+	Parameters []string            `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Var        string              `json:"var,omitempty" yaml:"var,omitempty"`
+	Mode       string              `json:"mode,omitempty" yaml:"mode,omitempty"`
+	Query      *QueryDescription   `json:"query,omitempty" yaml:"query,omitempty"`
+	Expr       string              `json:"expr,omitempty" yaml:"expr,omitempty"`
+	Default    string              `json:"default,omitempty" yaml:"default,omitempty"`
+	Width      int                 `json:"width,omitempty" yaml:"width,omitempty"`
+	Styles     []*TableColumnStyle `json:"styles,omitempty" yaml:"styles,omitempty"`
+	Terminal   []*TableColumnState `json:"terminal,omitempty" yaml:"terminal,omitempty"`
 	o          func(*Scope, *Query) string
+	re         *regexp.Regexp
+	expr       *Expression
 	parent     *TableDescriptor
 }
 
@@ -260,6 +415,9 @@ func (t *TableColumnDescriptor) Extract(scope *Scope, q *Query) string {
 	if t.o == nil {
 		t.assignFunc()
 	}
+	if t.expr != nil {
+		return t.o(scope, NewQuery(t.expr.Eval(scope, q)))
+	}
 	if t.Var == "" {
 		sq := q.Path(t.Path...)
 		if sq.Nil() && len(t.AltPath) > 0 {
@@ -294,6 +452,21 @@ func (t *TableColumnDescriptor) assignFunc() {
 					return LocalTimeStr(q.String())
 				}
 			}
+		case "relativeTime":
+			t.o = func(_ *Scope, q *Query) string {
+				return RelativeTimeStr(q.String())
+			}
+		case "regex":
+			t.o = func(_ *Scope, q *Query) string {
+				m := t.re.FindStringSubmatch(q.String())
+				switch len(m) {
+				case 0:
+					return ""
+				case 1:
+					return m[0]
+				}
+				return m[1]
+			}
 		case "map":
 			if len(t.Parameters) == 0 {
 				t.o = func(_ *Scope, q *Query) string {
@@ -321,6 +494,32 @@ func (t *TableColumnDescriptor) assignFunc() {
 			t.o = func(_ *Scope, q *Query) string {
 				return time.Duration(q.Integer() * int(time.Millisecond)).String()
 			}
+		case "relativeTime":
+			t.o = func(_ *Scope, q *Query) string {
+				v := q.Integer()
+				if v == 0 {
+					return "-"
+				}
+				return RelativeTime(time.Unix(int64(v)/1000, 0))
+			}
+		case "bytes":
+			t.o = func(_ *Scope, q *Query) string {
+				if q.Nil() {
+					return "-"
+				}
+				return ByteCountIEC(int64(q.Integer()))
+			}
+		case "grouped":
+			sep := ","
+			if len(t.Parameters) > 0 {
+				sep = t.Parameters[0]
+			}
+			t.o = func(_ *Scope, q *Query) string {
+				if q.Nil() {
+					return "-"
+				}
+				return GroupDigits(q.String(), sep)
+			}
 		}
 	case "json":
 		t.o = func(_ *Scope, q *Query) string {
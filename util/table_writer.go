@@ -25,12 +25,16 @@ import (
 
 // RowWriter writes rows to a stream
 type RowWriter struct {
-	w io.Writer    // writer
-	f func() error // flush
-	d string       // delimiter
-	e string       // escaped delimiter
-	c int          // counter
-	l int          // limit
+	w io.Writer             // writer
+	f func() error          // flush
+	d string                // delimiter
+	e string                // escaped delimiter
+	c int                   // counter
+	l int                   // limit
+	m markup                // optional markup, e.g. Markdown or HTML
+	p *RowProcessor         // optional sorting, filtering and column selection
+	s ColumnStyles          // optional column styles
+	h [][]*TableColumnStyle // styles by column index, initialized by the header
 }
 
 // NewTableWriter creates an initialized RowWriter with tabs as delimiter
@@ -45,14 +49,45 @@ func NewTableWriter(out io.Writer) *RowWriter {
 	return result
 }
 
-// NewCSVWriter creates an initialized RowWriter with commas as delimiter
+// NewCSVWriter creates an initialized RowWriter writing comma separated
+// values (RFC 4180)
 func NewCSVWriter(out io.Writer) *RowWriter {
-	result := &RowWriter{
-		w: out,
-		d: ",",
-		e: ";",
+	return &RowWriter{w: out, m: &csvMarkup{}}
+}
+
+// SetProcessor sets the RowProcessor for sorting, filtering and column
+// selection
+func (t *RowWriter) SetProcessor(p *RowProcessor) *RowWriter {
+	t.p = p
+	return t
+}
+
+// SetStyles sets the column styles. The values of styled columns will be
+// colored with ANSI escape sequences.
+func (t *RowWriter) SetStyles(s ColumnStyles) *RowWriter {
+	if len(s) > 0 {
+		t.s = s
 	}
-	return result
+	return t
+}
+
+// cell writes a part of a cell and wraps it with the color sequences for
+// styled columns. All cells of a styled column have the same overhead,
+// otherwise the tabwriter would break the alignment.
+func (t *RowWriter) cell(i int, str, value string, header bool) error {
+	if i >= len(t.h) || t.h[i] == nil {
+		if str == "" {
+			return nil
+		}
+		_, err := t.w.Write([]byte(str))
+		return err
+	}
+	c := ansiDefault
+	if !header {
+		c = color(t.h[i], value)
+	}
+	_, err := t.w.Write([]byte(c + str + ansiReset))
+	return err
 }
 
 // AutoFlush sets the limit for the automatic flush during writes
@@ -63,6 +98,9 @@ func (t *RowWriter) AutoFlush(l int) *RowWriter {
 
 // Write writes one row and terminates it with a newline
 func (t *RowWriter) WriteSingle(v ...string) error {
+	if t.m != nil {
+		return t.m.row(t.w, v)
+	}
 	for i, w := range v {
 		if i > 0 {
 			// write delimiter
@@ -88,8 +126,27 @@ func (t *RowWriter) WriteSingle(v ...string) error {
 // Write writes one row with mutliple lines and terminates it with a newline. v
 // is a slice of columns separated by the delimiter, e.g. a tab.
 func (t *RowWriter) Write(v ...string) error {
+	if t.p != nil {
+		return t.p.Process(v, t.write)
+	}
+	return t.write(v...)
+}
+
+func (t *RowWriter) write(v ...string) error {
+	if t.m != nil {
+		return t.m.row(t.w, v)
+	}
 	// l is number of columns
 	l := len(v)
+	// the first row is the header, it initializes the styles
+	header := false
+	if t.s != nil && t.h == nil {
+		header = true
+		t.h = make([][]*TableColumnStyle, l)
+		for i, name := range v {
+			t.h[i] = t.s[name]
+		}
+	}
 	values := make([]string, l)
 	lengths := make([]int, l)
 	// replace delimiters with escaped variant, e.g. tabs with spaces
@@ -115,11 +172,14 @@ func (t *RowWriter) Write(v ...string) error {
 			length := lengths[i]
 			if length == 0 {
 				done++
+				if err := t.cell(i, "", w, header); err != nil {
+					return err
+				}
 				continue
 			}
+			var str string
 			offset := offsets[i]
 			if offset < length {
-				var str string
 				current := w[offset:]
 				// search for next newline
 				index := strings.IndexByte(current, '\n')
@@ -144,9 +204,9 @@ func (t *RowWriter) Write(v ...string) error {
 					offsets[i] = length
 					done++
 				}
-				if _, err := t.w.Write([]byte(str)); err != nil {
-					return err
-				}
+			}
+			if err := t.cell(i, str, w, header); err != nil {
+				return err
 			}
 		}
 		// output new line
@@ -171,3 +231,23 @@ func (t *RowWriter) Flush() error {
 	}
 	return nil
 }
+
+// Close finishes the output, e.g. writes the closing tags of a markup format,
+// and flushes the underlying stream
+func (t *RowWriter) Close() error {
+	if t.p != nil {
+		p := t.p
+		t.p = nil
+		if err := p.Finish(t.write); err != nil {
+			return err
+		}
+	}
+	if t.m != nil {
+		m := t.m
+		t.m = nil
+		if err := m.close(t.w); err != nil {
+			return err
+		}
+	}
+	return t.Flush()
+}
@@ -16,7 +16,11 @@ specific language governing permissions and limitations under the License.
 */
 package util
 
-import "gopkg.in/yaml.v3"
+import (
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
 
 // YAMLQuery supports the querying and editing of YAML documents without loosing
 // the formating
@@ -145,3 +149,27 @@ func (q *YAMLQuery) SetMap(key, value string) {
 	}
 
 }
+
+// JSONToYAML converts the JSON document to YAML in block style and writes it
+// to the passed writer. The order of the attributes is preserved.
+func JSONToYAML(data []byte, w io.Writer) error {
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// blockStyle removes the JSON flow and quoting styles recursively
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, c := range node.Content {
+		blockStyle(c)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteAvro writes the schema as Avro record schema. Optional fields are
+// unions with null.
+func WriteAvro(w io.Writer, s *Schema, name string) error {
+	a := &avro{names: make(map[string]bool)}
+	result := a.record(&s.Field, Identifier(name, true))
+	if s.TenantID != "" {
+		result["namespace"] = "com.adobe.xdm." + Identifier(s.TenantID, false)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// avro keeps the names of the records unique
+type avro struct {
+	names map[string]bool
+}
+
+// name returns a unique record name
+func (a *avro) name(name string) string {
+	result := name
+	for i := 2; a.names[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	a.names[result] = true
+	return result
+}
+
+// record returns the record of an object
+func (a *avro) record(f *Field, name string) map[string]interface{} {
+	fields := make([]interface{}, 0, len(f.Fields))
+	for _, c := range f.Fields {
+		field := map[string]interface{}{"name": avroName(c.Name)}
+		t := a.typ(c, name+Identifier(c.Name, true))
+		if c.Required {
+			field["type"] = t
+		} else {
+			field["type"] = []interface{}{"null", t}
+			field["default"] = nil
+		}
+		if c.Description != "" {
+			field["doc"] = c.Description
+		}
+		fields = append(fields, field)
+	}
+	result := map[string]interface{}{
+		"type":   "record",
+		"name":   a.name(name),
+		"fields": fields,
+	}
+	if f.Description != "" {
+		result["doc"] = f.Description
+	}
+	return result
+}
+
+// typ returns the Avro type of the field
+func (a *avro) typ(f *Field, name string) interface{} {
+	switch f.Type {
+	case Object:
+		return a.record(f, name)
+	case Array:
+		return map[string]interface{}{"type": "array", "items": a.typ(f.Items, name+"Item")}
+	case Map:
+		return map[string]interface{}{"type": "map", "values": a.typ(f.Items, name+"Value")}
+	case Long:
+		return "long"
+	case Int, Short, Byte:
+		return "int"
+	case Double:
+		return "double"
+	case Boolean:
+		return "boolean"
+	case Date:
+		return map[string]interface{}{"type": "int", "logicalType": "date"}
+	case DateTime:
+		return map[string]interface{}{"type": "long", "logicalType": "timestamp-millis"}
+	}
+	return "string"
+}
+
+// avroName replaces the characters which are not allowed in Avro names
+func avroName(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,315 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseSchema returns the schema of the JSON document
+func parseSchema(t *testing.T, doc string) *Schema {
+	t.Helper()
+	var obj interface{}
+	if err := json.Unmarshal([]byte(doc), &obj); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// lookup returns the compact JSON of the value with the dot separated path,
+// numbers are array indices
+func lookup(t *testing.T, doc interface{}, path string) string {
+	t.Helper()
+	cur := doc
+	for _, p := range strings.Split(path, ".") {
+		switch v := cur.(type) {
+		case map[string]interface{}:
+			cur = v[p]
+		case []interface{}:
+			i, err := strconv.Atoi(p)
+			if err != nil || i >= len(v) {
+				t.Fatalf("invalid index %s of path %s", p, path)
+			}
+			cur = v[i]
+		default:
+			t.Fatalf("path %s not found", path)
+		}
+	}
+	data, err := json.Marshal(cur)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestWriteJSONFormats(t *testing.T) {
+	s := parseTestSchema(t)
+	writers := map[string]func(*bytes.Buffer) error{
+		"avro":       func(b *bytes.Buffer) error { return WriteAvro(b, s, "members") },
+		"jsonschema": func(b *bytes.Buffer) error { return WriteJSONSchema(b, s) },
+		"spark":      func(b *bytes.Buffer) error { return WriteSpark(b, s) },
+	}
+	docs := make(map[string]interface{}, len(writers))
+	for format, write := range writers {
+		var b bytes.Buffer
+		if err := write(&b); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		var doc interface{}
+		if err := json.Unmarshal(b.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid JSON %v", format, err)
+		}
+		docs[format] = doc
+	}
+	tests := []struct {
+		format, path, want string
+	}{
+		// records are named after the path, optional fields are unions
+		{"avro", "name", `"Members"`},
+		{"avro", "namespace", `"com.adobe.xdm.acme"`},
+		{"avro", "type", `"record"`},
+		{"avro", "fields.0.name", `"_acme"`},
+		{"avro", "fields.0.default", `null`},
+		{"avro", "fields.0.type.1.name", `"MembersAcme"`},
+		{"avro", "fields.0.type.1.fields.0", `{"default":null,"name":"level","type":["null","string"]}`},
+		{"avro", "fields.0.type.1.fields.1", `{"name":"loyaltyId","type":"string"}`},
+		{"avro", "fields.0.type.1.fields.3.type", `["null","int"]`},
+		{"avro", "fields.1", `{"name":"_id","type":"string"}`},
+		{"avro", "fields.3.type.1", `{"type":"map","values":"double"}`},
+		{"avro", "fields.4.type.1", `{"logicalType":"date","type":"int"}`},
+		{"avro", "fields.6.type.1.values.items.name", `"MembersIdentityMapValueItem"`},
+		{"avro", "fields.7.type.1", `{"items":"string","type":"array"}`},
+		{"avro", "fields.8.type.1", `{"logicalType":"timestamp-millis","type":"long"}`},
+		// the ranges of the XDM types become minimum and maximum
+		{"jsonschema", "$schema", `"https://json-schema.org/draft/2020-12/schema"`},
+		{"jsonschema", "$id", `"https://ns.adobe.com/acme/schemas/1"`},
+		{"jsonschema", "title", `"Members"`},
+		{"jsonschema", "required", `["_id"]`},
+		{"jsonschema", "properties._acme.required", `["loyaltyId"]`},
+		{"jsonschema", "properties._acme.properties.level", `{"enum":["gold","silver"],"type":"string"}`},
+		{"jsonschema", "properties._acme.properties.loyaltyId", `{"pattern":"^[0-9]+$","type":"string"}`},
+		{"jsonschema", "properties._acme.properties.points", `{"maximum":2147483647,"minimum":0,"type":"integer"}`},
+		{"jsonschema", "properties._acme.properties.small", `{"maximum":127,"minimum":-128,"type":"integer"}`},
+		{"jsonschema", "properties.attributes", `{"additionalProperties":{"type":"number"},"type":"object"}`},
+		{"jsonschema", "properties.birthDate", `{"format":"date","type":"string"}`},
+		{"jsonschema", "properties.timestamp", `{"format":"date-time","type":"string"}`},
+		{"jsonschema", "properties.tags", `{"items":{"type":"string"},"type":"array"}`},
+		// required fields are not nullable
+		{"spark", "type", `"struct"`},
+		{"spark", "fields.0.name", `"_acme"`},
+		{"spark", "fields.0.type.fields.1", `{"metadata":{},"name":"loyaltyId","nullable":false,"type":"string"}`},
+		{"spark", "fields.0.type.fields.2.type", `"integer"`},
+		{"spark", "fields.0.type.fields.3.type", `"byte"`},
+		{"spark", "fields.1", `{"metadata":{},"name":"_id","nullable":false,"type":"string"}`},
+		{"spark", "fields.3.type", `{"keyType":"string","type":"map","valueContainsNull":true,"valueType":"double"}`},
+		{"spark", "fields.4.type", `"date"`},
+		{"spark", "fields.6.type.valueType.elementType.fields.1.type", `"boolean"`},
+		{"spark", "fields.7.type", `{"containsNull":true,"elementType":"string","type":"array"}`},
+		{"spark", "fields.8.type", `"timestamp"`},
+	}
+	for _, tt := range tests {
+		if got := lookup(t, docs[tt.format], tt.path); got != tt.want {
+			t.Errorf("%s %s = %s, want %s", tt.format, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestWriteTextFormats(t *testing.T) {
+	s := parseTestSchema(t)
+	tests := []struct {
+		format string
+		write  func(*bytes.Buffer) error
+		want   string
+	}{
+		{"go", func(b *bytes.Buffer) error { return WriteGo(b, s, "members", "model") }, `package model
+
+import "time"
+
+type Members struct {
+	Acme        *MembersAcme                              ` + "`json:\"_acme,omitempty\"`" + `
+	Id          string                                    ` + "`json:\"_id\"`" + `
+	Active      *bool                                     ` + "`json:\"active,omitempty\"`" + `
+	Attributes  map[string]float64                        ` + "`json:\"attributes,omitempty\"`" + `
+	BirthDate   *string                                   ` + "`json:\"birthDate,omitempty\"`" + `
+	Email       *string                                   ` + "`json:\"email,omitempty\"`" + `
+	IdentityMap map[string][]*MembersIdentityMapValueItem ` + "`json:\"identityMap,omitempty\"`" + `
+	Tags        []string                                  ` + "`json:\"tags,omitempty\"`" + `
+	Timestamp   *time.Time                                ` + "`json:\"timestamp,omitempty\"`" + `
+}
+
+type MembersAcme struct {
+	Level     *string ` + "`json:\"level,omitempty\"`" + `
+	LoyaltyId string  ` + "`json:\"loyaltyId\"`" + `
+	Points    *int32  ` + "`json:\"points,omitempty\"`" + `
+	Small     *int8   ` + "`json:\"small,omitempty\"`" + `
+}
+
+type MembersIdentityMapValueItem struct {
+	Id      *string ` + "`json:\"id,omitempty\"`" + `
+	Primary *bool   ` + "`json:\"primary,omitempty\"`" + `
+}
+`},
+		{"sql", func(b *bytes.Buffer) error { return WriteSQL(b, s, "members") }, `CREATE TABLE members (
+  _acme struct<level: string, loyaltyId: string, points: int, small: tinyint>,
+  _id string NOT NULL,
+  active boolean,
+  attributes map<string, double>,
+  birthDate date,
+  email string,
+  identityMap map<string, array<struct<id: string, primary: boolean>>>,
+  tags array<string>,
+  timestamp timestamp
+);
+`},
+		{"typescript", func(b *bytes.Buffer) error { return WriteTypeScript(b, s, "members") }, `export interface Members {
+  _acme?: {
+    level?: "gold" | "silver";
+    loyaltyId: string;
+    points?: number;
+    small?: number;
+  };
+  _id: string;
+  active?: boolean;
+  attributes?: { [key: string]: number };
+  birthDate?: string;
+  email?: string;
+  identityMap?: { [key: string]: Array<{
+    id?: string;
+    primary?: boolean;
+  }> };
+  tags?: Array<string>;
+  timestamp?: string;
+}
+`},
+	}
+	for _, tt := range tests {
+		var b bytes.Buffer
+		if err := tt.write(&b); err != nil {
+			t.Errorf("%s: %v", tt.format, err)
+			continue
+		}
+		if got := b.String(); got != tt.want {
+			t.Errorf("%s output =\n%s\nwant\n%s", tt.format, got, tt.want)
+		}
+	}
+}
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		exported bool
+		want     string
+	}{
+		{"loyaltyId", true, "LoyaltyId"},
+		{"loyaltyId", false, "loyaltyId"},
+		{"_acme", true, "Acme"},
+		{"_acme", false, "acme"},
+		{"first name", true, "FirstName"},
+		{"first-name", false, "firstName"},
+		{"xdm:sourceSchema", true, "XdmSourceSchema"},
+		{"Members", false, "members"},
+		// identifiers must not start with a digit
+		{"1st name", true, "X1stName"},
+		{"1st name", false, "_1stName"},
+		{"_1st", true, "X1st"},
+		{"2021", true, "X2021"},
+		{"", true, "_"},
+		{"-_-", false, "_"},
+	}
+	for _, tt := range tests {
+		if got := Identifier(tt.name, tt.exported); got != tt.want {
+			t.Errorf("Identifier(%q, %v) = %q, want %q", tt.name, tt.exported, got, tt.want)
+		}
+	}
+}
+
+func TestSnake(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"members", "members"},
+		{"Loyalty Members", "loyalty_members"},
+		{"loyalty--members!", "loyalty_members"},
+		{"_acme", "acme"},
+		{"1st members", "_1st_members"},
+		{"", "_"},
+	}
+	for _, tt := range tests {
+		if got := Snake(tt.name); got != tt.want {
+			t.Errorf("Snake(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGoNames(t *testing.T) {
+	s := parseSchema(t, `{
+  "properties": {
+    "1st": {"type": "string"},
+    "a-b": {"type": "string"},
+    "a_b": {"type": "string"},
+    "x": {"type": "object", "properties": {"y": {"type": "string"}}},
+    "y": {"type": "object", "properties": {"z": {"type": "string"}}}
+  }
+}`)
+	var b bytes.Buffer
+	if err := WriteGo(&b, s, "x", "model"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"X1st *string `json:\"1st,omitempty\"`",
+		"AB   *string `json:\"a-b,omitempty\"`",
+		"AB2  *string `json:\"a_b,omitempty\"`",
+		"type X struct",
+		"type XX struct",
+		"type XY struct",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteGo() output does not contain %q\n%s", want, b.String())
+		}
+	}
+}
+
+func TestWriteSQLEmpty(t *testing.T) {
+	s := parseSchema(t, `{
+  "properties": {
+    "empty": {"type": "object", "properties": {}},
+    "nested": {"type": "object", "properties": {"empty": {"type": "object"}, "name": {"type": "string"}}},
+    "list": {"type": "array", "items": {"type": "object", "properties": {}}},
+    "name": {"type": "string"}
+  }
+}`)
+	var b bytes.Buffer
+	if err := WriteSQL(&b, s, "t"); err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE TABLE t (\n  name string,\n  nested struct<name: string>\n);\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteSQL() = %q, want %q", got, want)
+	}
+	s = parseSchema(t, `{"properties": {"empty": {"type": "object", "properties": {}}}}`)
+	if err := WriteSQL(&b, s, "t"); err == nil {
+		t.Error("WriteSQL() of a schema without SQL types returned no error")
+	}
+}
@@ -0,0 +1,300 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"errors"
+	"sort"
+	"strings"
+)
+
+// XDM types of fields
+const (
+	String   = "string"
+	Double   = "double"
+	Long     = "long"
+	Int      = "int"
+	Short    = "short"
+	Byte     = "byte"
+	Boolean  = "boolean"
+	Date     = "date"
+	DateTime = "date-time"
+	Object   = "object"
+	Array    = "array"
+	Map      = "map"
+)
+
+// Field is a field of a resolved XDM schema
+type Field struct {
+	Name        string
+	Title       string
+	Description string
+	// Type is the XDM type, e.g. long or date-time
+	Type     string
+	Format   string
+	Pattern  string
+	Enum     []interface{}
+	Required bool
+	Minimum  *float64
+	Maximum  *float64
+	MinLen   *int
+	MaxLen   *int
+	// Fields are the fields of objects
+	Fields []*Field
+	// Items are the elements of arrays and the values of maps
+	Items *Field
+	// Identity is the namespace of identity fields
+	Identity string
+	Primary  bool
+}
+
+// Schema is a resolved XDM schema
+type Schema struct {
+	Field
+	ID string
+	// TenantID is the tenant namespace without the leading underscore
+	TenantID string
+}
+
+// Parse returns the schema of the resolved document, see the accept header
+// application/vnd.adobe.xed-full+json
+func Parse(doc interface{}) (*Schema, error) {
+	m, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("the schema is not a JSON object")
+	}
+	props := make(map[string]interface{})
+	required := strs(m["required"])
+	merge(props, m["properties"])
+	// resources with references to definitions are merged
+	defs, _ := m["definitions"].(map[string]interface{})
+	for _, item := range arr(m["allOf"]) {
+		part, _ := item.(map[string]interface{})
+		merge(props, part["properties"])
+		required = append(required, strs(part["required"])...)
+		if ref, ok := part["$ref"].(string); ok && strings.HasPrefix(ref, "#/definitions/") {
+			def, _ := defs[strings.TrimPrefix(ref, "#/definitions/")].(map[string]interface{})
+			merge(props, def["properties"])
+			required = append(required, strs(def["required"])...)
+		}
+	}
+	result := &Schema{
+		ID:       str(m["$id"]),
+		TenantID: strings.TrimPrefix(str(m["meta:tenantNamespace"]), "_"),
+	}
+	result.Title, result.Description, result.Type = str(m["title"]), str(m["description"]), Object
+	result.Fields = fields(props, required)
+	if len(result.Fields) == 0 {
+		return nil, errors.New("the schema has no fields, request the resolved schema")
+	}
+	return result, nil
+}
+
+// AddIdentities marks the fields of the identity descriptors, see
+// xdm:descriptorIdentity
+func (s *Schema) AddIdentities(descriptors []interface{}) {
+	for _, d := range descriptors {
+		m := obj(d)
+		if f := s.Find(str(m["xdm:sourceProperty"])); f != nil {
+			f.Identity = str(m["xdm:namespace"])
+			f.Primary, _ = m["xdm:isPrimary"].(bool)
+		}
+	}
+}
+
+// Find returns the field of the path, e.g. /_tenant/loyaltyId, or nil
+func (s *Schema) Find(path string) *Field {
+	f := &s.Field
+	for _, name := range strings.Split(strings.Trim(path, "/"), "/") {
+		for f.Type == Array || f.Type == Map {
+			f = f.Items
+		}
+		var next *Field
+		for _, c := range f.Fields {
+			if c.Name == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil
+		}
+		f = next
+	}
+	return f
+}
+
+// merge adds the properties recursively
+func merge(props map[string]interface{}, obj interface{}) {
+	m, _ := obj.(map[string]interface{})
+	for name, value := range m {
+		old, ok := props[name].(map[string]interface{})
+		add, ok2 := value.(map[string]interface{})
+		if ok && ok2 {
+			merged := make(map[string]interface{}, len(old))
+			for k, v := range old {
+				merged[k] = v
+			}
+			children := make(map[string]interface{})
+			merge(children, old["properties"])
+			merge(children, add["properties"])
+			for k, v := range add {
+				merged[k] = v
+			}
+			merged["properties"] = children
+			props[name] = merged
+			continue
+		}
+		props[name] = value
+	}
+}
+
+// fields returns the fields of the properties ordered by name
+func fields(props map[string]interface{}, required []string) []*Field {
+	names := make([]string, 0, len(props))
+	for name := range props {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	result := make([]*Field, 0, len(names))
+	for _, name := range names {
+		m, ok := props[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		f := parseField(name, m)
+		for _, r := range required {
+			if r == name {
+				f.Required = true
+			}
+		}
+		result = append(result, f)
+	}
+	return result
+}
+
+// parseField converts the JSON schema of a field
+func parseField(name string, m map[string]interface{}) *Field {
+	f := &Field{
+		Name:        name,
+		Title:       str(m["title"]),
+		Description: str(m["description"]),
+		Format:      str(m["format"]),
+		Pattern:     str(m["pattern"]),
+		Enum:        arr(m["enum"]),
+		Minimum:     num(m["minimum"]),
+		Maximum:     num(m["maximum"]),
+		MinLen:      integer(m["minLength"]),
+		MaxLen:      integer(m["maxLength"]),
+	}
+	f.Type = xdmType(m)
+	switch f.Type {
+	case Object:
+		f.Fields = fields(obj(m["properties"]), strs(m["required"]))
+	case Array:
+		items := obj(m["items"])
+		f.Items = parseField("items", items)
+	case Map:
+		values := obj(m["additionalProperties"])
+		f.Items = parseField("values", values)
+	}
+	return f
+}
+
+// xdmType returns meta:xdmType or the XDM type derived from the JSON type
+func xdmType(m map[string]interface{}) string {
+	t, format := str(m["type"]), str(m["format"])
+	switch x := str(m["meta:xdmType"]); x {
+	case "":
+	case "number":
+		return Double
+	case "integer":
+		return Long
+	default:
+		if x == Object && len(obj(m["properties"])) == 0 && m["additionalProperties"] != nil {
+			return Map
+		}
+		return x
+	}
+	switch t {
+	case "string":
+		if format == Date || format == DateTime {
+			return format
+		}
+		return String
+	case "integer":
+		return Long
+	case "number":
+		return Double
+	case "boolean", "array":
+		return t
+	case "object":
+		if len(obj(m["properties"])) == 0 && m["additionalProperties"] != nil {
+			return Map
+		}
+		return Object
+	}
+	if m["properties"] != nil {
+		return Object
+	}
+	return String
+}
+
+// Leaf returns true for fields without children
+func (f *Field) Leaf() bool {
+	return f.Type != Object && f.Type != Array && f.Type != Map
+}
+
+func str(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func arr(v interface{}) []interface{} {
+	a, _ := v.([]interface{})
+	return a
+}
+
+func obj(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func strs(v interface{}) []string {
+	var result []string
+	for _, s := range arr(v) {
+		if s, ok := s.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+func num(v interface{}) *float64 {
+	if f, ok := v.(float64); ok {
+		return &f
+	}
+	return nil
+}
+
+func integer(v interface{}) *int {
+	if f, ok := v.(float64); ok {
+		i := int(f)
+		return &i
+	}
+	return nil
+}
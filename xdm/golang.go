@@ -0,0 +1,124 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// WriteGo writes the schema as Go structs. Nested objects become named
+// structs, optional scalar fields are pointers.
+func WriteGo(w io.Writer, s *Schema, name, pkg string) error {
+	g := &golang{names: make(map[string]bool)}
+	g.structType(&s.Field, Identifier(name, true))
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "package %s\n\n", pkg)
+	if g.time {
+		sb.WriteString("import \"time\"\n\n")
+	}
+	sb.WriteString(strings.Join(g.decls, "\n"))
+	src, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// golang collects the struct declarations
+type golang struct {
+	names map[string]bool
+	decls []string
+	time  bool
+}
+
+// structType adds the declaration of the struct and returns its name
+func (g *golang) structType(f *Field, name string) string {
+	result := name
+	for i := 2; g.names[result]; i++ {
+		result = name + strconv.Itoa(i)
+	}
+	g.names[result] = true
+	// reserve the slot to keep the order of declarations
+	index := len(g.decls)
+	g.decls = append(g.decls, "")
+	var sb strings.Builder
+	if f.Description != "" {
+		fmt.Fprintf(&sb, "// %s %s\n", result, comment(f.Description))
+	}
+	fmt.Fprintf(&sb, "type %s struct {\n", result)
+	fields := make(map[string]bool, len(f.Fields))
+	for _, c := range f.Fields {
+		field := Identifier(c.Name, true)
+		for i := 2; fields[field]; i++ {
+			field = Identifier(c.Name, true) + strconv.Itoa(i)
+		}
+		fields[field] = true
+		t := g.typ(c, result+Identifier(c.Name, true))
+		tag := c.Name
+		if !c.Required {
+			tag += ",omitempty"
+			if c.Leaf() {
+				t = "*" + t
+			}
+		}
+		fmt.Fprintf(&sb, "\t%s %s `json:\"%s\"`\n", field, t, tag)
+	}
+	sb.WriteString("}\n")
+	g.decls[index] = sb.String()
+	return result
+}
+
+// typ returns the Go type of the field
+func (g *golang) typ(f *Field, name string) string {
+	switch f.Type {
+	case Object:
+		return "*" + g.structType(f, name)
+	case Array:
+		return "[]" + g.typ(f.Items, name+"Item")
+	case Map:
+		return "map[string]" + g.typ(f.Items, name+"Value")
+	case Long:
+		return "int64"
+	case Int:
+		return "int32"
+	case Short:
+		return "int16"
+	case Byte:
+		return "int8"
+	case Double:
+		return "float64"
+	case Boolean:
+		return "bool"
+	case DateTime:
+		g.time = true
+		return "time.Time"
+	}
+	return "string"
+}
+
+// comment returns the first line of the description
+func comment(desc string) string {
+	if i := strings.IndexAny(desc, "\r\n"); i >= 0 {
+		desc = desc[:i]
+	}
+	return desc
+}
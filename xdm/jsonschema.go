@@ -0,0 +1,116 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// integer ranges of the XDM types
+var ranges = map[string][2]float64{
+	Long:  {-(1<<53 - 1), 1<<53 - 1},
+	Int:   {math.MinInt32, math.MaxInt32},
+	Short: {math.MinInt16, math.MaxInt16},
+	Byte:  {math.MinInt8, math.MaxInt8},
+}
+
+// WriteJSONSchema writes the schema as standalone JSON Schema (draft 2020-12)
+// without references
+func WriteJSONSchema(w io.Writer, s *Schema) error {
+	result := map[string]interface{}{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+	}
+	if s.ID != "" {
+		result["$id"] = s.ID
+	}
+	for k, v := range jsonSchema(&s.Field) {
+		result[k] = v
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(result)
+}
+
+// jsonSchema returns the JSON Schema of the field
+func jsonSchema(f *Field) map[string]interface{} {
+	result := make(map[string]interface{})
+	if f.Title != "" {
+		result["title"] = f.Title
+	}
+	if f.Description != "" {
+		result["description"] = f.Description
+	}
+	switch f.Type {
+	case Object:
+		result["type"] = "object"
+		props := make(map[string]interface{}, len(f.Fields))
+		var required []string
+		for _, c := range f.Fields {
+			props[c.Name] = jsonSchema(c)
+			if c.Required {
+				required = append(required, c.Name)
+			}
+		}
+		result["properties"] = props
+		if len(required) > 0 {
+			result["required"] = required
+		}
+	case Array:
+		result["type"] = "array"
+		result["items"] = jsonSchema(f.Items)
+	case Map:
+		result["type"] = "object"
+		result["additionalProperties"] = jsonSchema(f.Items)
+	case Long, Int, Short, Byte:
+		result["type"] = "integer"
+		r := ranges[f.Type]
+		result["minimum"], result["maximum"] = r[0], r[1]
+	case Double:
+		result["type"] = "number"
+	case Boolean:
+		result["type"] = "boolean"
+	case Date, DateTime:
+		result["type"] = "string"
+		result["format"] = f.Type
+	default:
+		result["type"] = "string"
+		if f.Format != "" {
+			result["format"] = f.Format
+		}
+	}
+	if f.Pattern != "" {
+		result["pattern"] = f.Pattern
+	}
+	if len(f.Enum) > 0 {
+		result["enum"] = f.Enum
+	}
+	if f.Minimum != nil {
+		result["minimum"] = *f.Minimum
+	}
+	if f.Maximum != nil {
+		result["maximum"] = *f.Maximum
+	}
+	if f.MinLen != nil {
+		result["minLength"] = *f.MinLen
+	}
+	if f.MaxLen != nil {
+		result["maxLength"] = *f.MaxLen
+	}
+	return result
+}
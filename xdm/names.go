@@ -0,0 +1,72 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Identifier converts the name to a camel case identifier. The first letter
+// is upper case if exported is true.
+func Identifier(name string, exported bool) string {
+	var sb strings.Builder
+	upper := exported
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = sb.Len() > 0 || exported
+			continue
+		}
+		if sb.Len() == 0 && unicode.IsDigit(r) {
+			// exported identifiers start with an upper case letter
+			if exported {
+				sb.WriteRune('X')
+			} else {
+				sb.WriteRune('_')
+			}
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		} else if sb.Len() == 0 {
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	if sb.Len() == 0 {
+		return "_"
+	}
+	return sb.String()
+}
+
+// Snake converts the name to a lower case identifier with underscores
+func Snake(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(unicode.ToLower(r))
+		case sb.Len() > 0 && !strings.HasSuffix(sb.String(), "_"):
+			sb.WriteRune('_')
+		}
+	}
+	result := strings.TrimSuffix(sb.String(), "_")
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "_" + result
+	}
+	return result
+}
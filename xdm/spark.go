@@ -0,0 +1,73 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// WriteSpark writes the schema as Spark StructType JSON, which is also used
+// for Parquet files
+func WriteSpark(w io.Writer, s *Schema) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sparkType(&s.Field))
+}
+
+// sparkType returns the Spark data type of the field
+func sparkType(f *Field) interface{} {
+	switch f.Type {
+	case Object:
+		fields := make([]interface{}, 0, len(f.Fields))
+		for _, c := range f.Fields {
+			metadata := map[string]interface{}{}
+			if c.Description != "" {
+				metadata["comment"] = c.Description
+			}
+			fields = append(fields, map[string]interface{}{
+				"name":     c.Name,
+				"type":     sparkType(c),
+				"nullable": !c.Required,
+				"metadata": metadata,
+			})
+		}
+		return map[string]interface{}{"type": "struct", "fields": fields}
+	case Array:
+		return map[string]interface{}{
+			"type":         "array",
+			"elementType":  sparkType(f.Items),
+			"containsNull": true,
+		}
+	case Map:
+		return map[string]interface{}{
+			"type":              "map",
+			"keyType":           "string",
+			"valueType":         sparkType(f.Items),
+			"valueContainsNull": true,
+		}
+	case Long:
+		return "long"
+	case Int:
+		return "integer"
+	case Short, Byte, Double, Boolean, Date:
+		return f.Type
+	case DateTime:
+		return "timestamp"
+	}
+	return "string"
+}
@@ -0,0 +1,114 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteSQL writes the schema as CREATE TABLE statement using the struct
+// notation of Query Service for nested fields. Objects without fields are
+// skipped, empty structs are invalid.
+func WriteSQL(w io.Writer, s *Schema, table string) error {
+	columns := sqlFields(&s.Field)
+	if len(columns) == 0 {
+		return errors.New("the schema has no fields with SQL types")
+	}
+	if _, err := fmt.Fprintf(w, "CREATE TABLE %s (\n", table); err != nil {
+		return err
+	}
+	for i, c := range columns {
+		sep := ","
+		if i == len(columns)-1 {
+			sep = ""
+		}
+		notNull := ""
+		if c.Required {
+			notNull = " NOT NULL"
+		}
+		if _, err := fmt.Fprintf(w, "  %s %s%s%s\n", sqlName(c.Name), sqlType(c), notNull, sep); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w, ");")
+	return err
+}
+
+// sqlType returns the SQL data type of the field
+func sqlType(f *Field) string {
+	switch f.Type {
+	case Object:
+		fields := make([]string, 0, len(f.Fields))
+		for _, c := range sqlFields(f) {
+			fields = append(fields, sqlName(c.Name)+": "+sqlType(c))
+		}
+		return "struct<" + strings.Join(fields, ", ") + ">"
+	case Array:
+		return "array<" + sqlType(f.Items) + ">"
+	case Map:
+		return "map<string, " + sqlType(f.Items) + ">"
+	case Long:
+		return "bigint"
+	case Int:
+		return "int"
+	case Short:
+		return "smallint"
+	case Byte:
+		return "tinyint"
+	case Double, Boolean, Date:
+		return f.Type
+	case DateTime:
+		return "timestamp"
+	}
+	return "string"
+}
+
+// sqlFields returns the fields of the object without empty objects
+func sqlFields(f *Field) []*Field {
+	result := make([]*Field, 0, len(f.Fields))
+	for _, c := range f.Fields {
+		if !empty(c) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// empty returns true for objects without fields and for arrays and maps of
+// empty objects
+func empty(f *Field) bool {
+	switch f.Type {
+	case Object:
+		return len(sqlFields(f)) == 0
+	case Array, Map:
+		return empty(f.Items)
+	}
+	return false
+}
+
+// sqlName quotes names which are not plain identifiers
+func sqlName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')) {
+			return "\"" + strings.ReplaceAll(name, "\"", "\"\"") + "\""
+		}
+	}
+	return name
+}
@@ -0,0 +1,98 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteTypeScript writes the schema as TypeScript interface with inline
+// types for nested objects
+func WriteTypeScript(w io.Writer, s *Schema, name string) error {
+	var sb strings.Builder
+	if s.Description != "" {
+		fmt.Fprintf(&sb, "/** %s */\n", comment(s.Description))
+	}
+	fmt.Fprintf(&sb, "export interface %s ", Identifier(name, true))
+	tsObject(&sb, &s.Field, "")
+	sb.WriteString("\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// tsObject writes the members of the object
+func tsObject(sb *strings.Builder, f *Field, indent string) {
+	sb.WriteString("{\n")
+	for _, c := range f.Fields {
+		if c.Description != "" {
+			fmt.Fprintf(sb, "%s  /** %s */\n", indent, comment(c.Description))
+		}
+		optional := "?"
+		if c.Required {
+			optional = ""
+		}
+		fmt.Fprintf(sb, "%s  %s%s: ", indent, tsName(c.Name), optional)
+		tsType(sb, c, indent+"  ")
+		sb.WriteString(";\n")
+	}
+	sb.WriteString(indent + "}")
+}
+
+// tsType writes the type of the field
+func tsType(sb *strings.Builder, f *Field, indent string) {
+	switch f.Type {
+	case Object:
+		tsObject(sb, f, indent)
+	case Array:
+		sb.WriteString("Array<")
+		tsType(sb, f.Items, indent)
+		sb.WriteString(">")
+	case Map:
+		sb.WriteString("{ [key: string]: ")
+		tsType(sb, f.Items, indent)
+		sb.WriteString(" }")
+	case Long, Int, Short, Byte, Double:
+		sb.WriteString("number")
+	case Boolean:
+		sb.WriteString("boolean")
+	default:
+		if len(f.Enum) == 0 {
+			sb.WriteString("string")
+			return
+		}
+		values := make([]string, 0, len(f.Enum))
+		for _, e := range f.Enum {
+			b, _ := json.Marshal(e)
+			values = append(values, string(b))
+		}
+		sb.WriteString(strings.Join(values, " | "))
+	}
+}
+
+// tsName quotes names which are not plain identifiers
+func tsName(name string) string {
+	for i, r := range name {
+		if !(r == '_' || r == '$' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (i > 0 && r >= '0' && r <= '9')) {
+			b, _ := json.Marshal(name)
+			return string(b)
+		}
+	}
+	return name
+}
@@ -0,0 +1,378 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Violation is a failed check of a record
+type Violation struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Validator checks records against a schema
+type Validator struct {
+	schema   *Schema
+	patterns map[string]*regexp.Regexp
+	// result of the current record
+	violations []Violation
+	primaries  int
+}
+
+// NewValidator creates an initialized Validator object
+func NewValidator(s *Schema) *Validator {
+	return &Validator{schema: s, patterns: make(map[string]*regexp.Regexp)}
+}
+
+var (
+	nameExp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	uuidExp = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+)
+
+// formats checks the values of string formats
+var formats = map[string]func(string) bool{
+	"email": func(v string) bool {
+		a, err := mail.ParseAddress(v)
+		return err == nil && a.Address == v
+	},
+	"uri": func(v string) bool {
+		u, err := url.Parse(v)
+		return err == nil && u.Scheme != ""
+	},
+	"uuid": uuidExp.MatchString,
+	"ipv4": func(v string) bool {
+		ip := net.ParseIP(v)
+		return ip != nil && ip.To4() != nil && !strings.Contains(v, ":")
+	},
+	"ipv6": func(v string) bool {
+		return net.ParseIP(v) != nil && strings.Contains(v, ":")
+	},
+	Date: func(v string) bool {
+		_, err := time.Parse("2006-01-02", v)
+		return err == nil
+	},
+	DateTime: func(v string) bool {
+		_, err := time.Parse(time.RFC3339Nano, v)
+		return err == nil
+	},
+}
+
+// Validate returns the violations of the record. Numbers are float64 or
+// json.Number values.
+func (v *Validator) Validate(record interface{}) []Violation {
+	v.violations, v.primaries = nil, 0
+	root, ok := record.(map[string]interface{})
+	if !ok {
+		v.add("$", "the record is not a JSON object")
+		return v.violations
+	}
+	tenant := "_" + v.schema.TenantID
+	for _, name := range keys(root) {
+		if find(&v.schema.Field, name) != nil {
+			continue
+		}
+		switch {
+		case v.schema.TenantID == "":
+			v.add(path("$", name), "unknown field")
+		case strings.HasPrefix(name, "_"):
+			v.add(path("$", name), fmt.Sprintf("unknown namespace, the tenant namespace is %s", tenant))
+		default:
+			v.add(path("$", name), fmt.Sprintf("unknown field, custom fields belong to the tenant namespace %s", tenant))
+		}
+	}
+	v.object(&v.schema.Field, root, "$")
+	if v.primaries > 1 {
+		v.add("$", fmt.Sprintf("%d primary identities, only one is allowed", v.primaries))
+	}
+	return v.violations
+}
+
+// add adds a violation
+func (v *Validator) add(path, msg string) {
+	v.violations = append(v.violations, Violation{Path: path, Message: msg})
+}
+
+// object checks the fields of the object, unknown fields of the root are
+// checked by Validate
+func (v *Validator) object(f *Field, m map[string]interface{}, p string) {
+	for _, c := range f.Fields {
+		value, ok := m[c.Name]
+		if !ok || value == nil {
+			if c.Required {
+				v.add(path(p, c.Name), "required field is missing")
+			}
+			continue
+		}
+		v.value(c, value, path(p, c.Name))
+	}
+	if p == "$" {
+		return
+	}
+	for _, name := range keys(m) {
+		if find(f, name) == nil {
+			v.add(path(p, name), "unknown field")
+		}
+	}
+}
+
+// value checks the value of the field
+func (v *Validator) value(f *Field, value interface{}, p string) {
+	switch f.Type {
+	case Object:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(p, "expected object, got "+kind(value))
+			return
+		}
+		v.object(f, m, p)
+		return
+	case Array:
+		a, ok := value.([]interface{})
+		if !ok {
+			v.add(p, "expected array, got "+kind(value))
+			return
+		}
+		for i, item := range a {
+			if item != nil {
+				v.value(f.Items, item, p+"["+strconv.Itoa(i)+"]")
+			}
+		}
+		return
+	case Map:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			v.add(p, "expected map, got "+kind(value))
+			return
+		}
+		for _, key := range keys(m) {
+			if p == "$.identityMap" {
+				v.identityMap(f, key, m[key])
+				continue
+			}
+			if item := m[key]; item != nil {
+				v.value(f.Items, item, path(p, key))
+			}
+		}
+		return
+	case Boolean:
+		if _, ok := value.(bool); !ok {
+			v.add(p, "expected boolean, got "+kind(value))
+		}
+	case Double, Long, Int, Short, Byte:
+		v.number(f, value, p)
+	default:
+		v.str(f, value, p)
+	}
+	if len(f.Enum) > 0 && !enum(value, f.Enum) {
+		v.add(p, fmt.Sprintf("value %v is not one of %v", value, f.Enum))
+	}
+}
+
+// number checks numbers and the range of integer types
+func (v *Validator) number(f *Field, value interface{}, p string) {
+	var n float64
+	switch t := value.(type) {
+	case float64:
+		n = t
+	case json.Number:
+		var err error
+		if n, err = t.Float64(); err != nil {
+			v.add(p, "invalid number "+t.String())
+			return
+		}
+	default:
+		v.add(p, "expected "+f.Type+", got "+kind(value))
+		return
+	}
+	if f.Type != Double {
+		if n != math.Trunc(n) {
+			v.add(p, fmt.Sprintf("expected %s, got %v", f.Type, value))
+			return
+		}
+		if r := ranges[f.Type]; n < r[0] || n > r[1] {
+			v.add(p, fmt.Sprintf("%v is out of the range of %s", value, f.Type))
+			return
+		}
+	}
+	if f.Minimum != nil && n < *f.Minimum {
+		v.add(p, fmt.Sprintf("%v is less than the minimum %v", value, *f.Minimum))
+	}
+	if f.Maximum != nil && n > *f.Maximum {
+		v.add(p, fmt.Sprintf("%v is greater than the maximum %v", value, *f.Maximum))
+	}
+}
+
+// str checks strings, dates and identities
+func (v *Validator) str(f *Field, value interface{}, p string) {
+	s, ok := value.(string)
+	if !ok {
+		v.add(p, "expected "+f.Type+", got "+kind(value))
+		return
+	}
+	format := f.Format
+	if f.Type == Date || f.Type == DateTime {
+		format = f.Type
+	}
+	if check, ok := formats[format]; ok && !check(s) {
+		v.add(p, fmt.Sprintf("%q is not a valid %s", s, format))
+	}
+	if f.Pattern != "" {
+		exp, ok := v.patterns[f.Pattern]
+		if !ok {
+			// invalid patterns of the schema are ignored
+			exp, _ = regexp.Compile(f.Pattern)
+			v.patterns[f.Pattern] = exp
+		}
+		if exp != nil && !exp.MatchString(s) {
+			v.add(p, fmt.Sprintf("%q does not match the pattern %s", s, f.Pattern))
+		}
+	}
+	if l := len([]rune(s)); f.MinLen != nil && l < *f.MinLen {
+		v.add(p, fmt.Sprintf("length %d is less than the minimum length %d", l, *f.MinLen))
+	} else if f.MaxLen != nil && l > *f.MaxLen {
+		v.add(p, fmt.Sprintf("length %d is greater than the maximum length %d", l, *f.MaxLen))
+	}
+	if f.Identity != "" {
+		v.identity(f.Identity, s, f.Primary, p)
+	}
+}
+
+// identity checks the value of an identity
+func (v *Validator) identity(namespace, id string, primary bool, p string) {
+	if strings.TrimSpace(id) == "" {
+		v.add(p, fmt.Sprintf("empty identity of namespace %s", namespace))
+	} else if strings.EqualFold(namespace, "Email") && !formats["email"](id) {
+		v.add(p, fmt.Sprintf("%q is not a valid email identity", id))
+	}
+	if primary {
+		v.primaries++
+	}
+}
+
+// identityMap checks the identities of a namespace
+func (v *Validator) identityMap(f *Field, namespace string, value interface{}) {
+	p := path("$.identityMap", namespace)
+	if !nameExp.MatchString(namespace) {
+		v.add(p, "invalid identity namespace")
+	}
+	v.value(f.Items, value, p)
+	a, _ := value.([]interface{})
+	for i, item := range a {
+		m, _ := item.(map[string]interface{})
+		if m == nil {
+			continue
+		}
+		ip := p + "[" + strconv.Itoa(i) + "]"
+		id, ok := m["id"].(string)
+		if !ok {
+			// missing ids of required fields are reported by value
+			if c := find(f.Items.Items, "id"); m["id"] == nil && (c == nil || !c.Required) {
+				v.add(path(ip, "id"), "required field is missing")
+			}
+			continue
+		}
+		primary, _ := m["primary"].(bool)
+		v.identity(namespace, id, primary, path(ip, "id"))
+	}
+}
+
+// find returns the child with the passed name or nil
+func find(f *Field, name string) *Field {
+	if f == nil {
+		return nil
+	}
+	for _, c := range f.Fields {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// keys returns the sorted keys of the object
+func keys(m map[string]interface{}) []string {
+	result := make([]string, 0, len(m))
+	for key := range m {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// path returns the JSON path of the child
+func path(p, name string) string {
+	if nameExp.MatchString(name) {
+		return p + "." + name
+	}
+	return p + "['" + strings.ReplaceAll(name, "'", "\\'") + "']"
+}
+
+// enum returns true if the value is one of the enum values
+func enum(value interface{}, values []interface{}) bool {
+	for _, e := range values {
+		if e == value {
+			return true
+		}
+		if a, ok := number(value); ok {
+			if b, ok := number(e); ok && a == b {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// number converts float64 and json.Number values
+func number(value interface{}) (float64, bool) {
+	switch t := value.(type) {
+	case float64:
+		return t, true
+	case json.Number:
+		f, err := t.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// kind returns the JSON type of the value
+func kind(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case float64, json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "null"
+}
@@ -0,0 +1,202 @@
+/*
+Package xdm contains the field model of resolved XDM schemas and its converters.
+
+Copyright 2021 Michael Bungenstock
+
+Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+this file except in compliance with the License. You may obtain a copy of the
+License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software distributed
+under the License is distributed on an "AS IS" BASIS, WITHOUT WARRANTIES OR
+CONDITIONS OF ANY KIND, either express or implied. See the License for the
+specific language governing permissions and limitations under the License.
+*/
+package xdm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+  "$id": "https://ns.adobe.com/acme/schemas/1",
+  "title": "Members",
+  "meta:tenantNamespace": "_acme",
+  "allOf": [{"$ref": "#/definitions/tenant"}],
+  "definitions": {
+    "tenant": {
+      "properties": {
+        "_acme": {
+          "type": "object",
+          "meta:xdmType": "object",
+          "required": ["loyaltyId"],
+          "properties": {
+            "loyaltyId": {"type": "string", "meta:xdmType": "string", "pattern": "^[0-9]+$"},
+            "level": {"type": "string", "meta:xdmType": "string", "enum": ["gold", "silver"]},
+            "points": {"type": "integer", "meta:xdmType": "int", "minimum": 0},
+            "small": {"type": "integer", "meta:xdmType": "byte"}
+          }
+        }
+      }
+    }
+  },
+  "required": ["_id"],
+  "properties": {
+    "_id": {"type": "string", "meta:xdmType": "string"},
+    "timestamp": {"type": "string", "format": "date-time", "meta:xdmType": "date-time"},
+    "birthDate": {"type": "string", "format": "date", "meta:xdmType": "date"},
+    "email": {"type": "string", "format": "email", "meta:xdmType": "string"},
+    "active": {"type": "boolean", "meta:xdmType": "boolean"},
+    "tags": {"type": "array", "meta:xdmType": "array", "items": {"type": "string"}},
+    "attributes": {"type": "object", "meta:xdmType": "map", "additionalProperties": {"type": "number"}},
+    "identityMap": {
+      "type": "object",
+      "meta:xdmType": "map",
+      "additionalProperties": {
+        "type": "array",
+        "items": {
+          "type": "object",
+          "properties": {"id": {"type": "string"}, "primary": {"type": "boolean"}}
+        }
+      }
+    }
+  }
+}`
+
+// testIdentities marks _acme.loyaltyId as primary identity
+var testIdentities = []interface{}{map[string]interface{}{
+	"@type":              "xdm:descriptorIdentity",
+	"xdm:sourceProperty": "/_acme/loyaltyId",
+	"xdm:namespace":      "Loyalty",
+	"xdm:isPrimary":      true,
+}}
+
+func parseTestSchema(t *testing.T) *Schema {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(testSchema), &doc); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Parse(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.AddIdentities(testIdentities)
+	return s
+}
+
+func TestParse(t *testing.T) {
+	s := parseTestSchema(t)
+	if s.ID != "https://ns.adobe.com/acme/schemas/1" || s.TenantID != "acme" || s.Title != "Members" {
+		t.Errorf("Parse() = %q %q %q", s.ID, s.TenantID, s.Title)
+	}
+	tests := []struct {
+		path     string
+		typ      string
+		required bool
+	}{
+		{"/_id", String, true},
+		{"/_acme", Object, false},
+		{"/_acme/loyaltyId", String, true},
+		{"/_acme/points", Int, false},
+		{"/timestamp", DateTime, false},
+		{"/birthDate", Date, false},
+		{"/tags", Array, false},
+		{"/attributes", Map, false},
+		{"/identityMap", Map, false},
+		{"/identityMap/id", String, false},
+		{"/identityMap/primary", Boolean, false},
+	}
+	for _, test := range tests {
+		f := s.Find(test.path)
+		if f == nil {
+			t.Errorf("Find(%q) = nil", test.path)
+			continue
+		}
+		if f.Type != test.typ || f.Required != test.required {
+			t.Errorf("Find(%q) = %s required %v, want %s required %v", test.path, f.Type, f.Required, test.typ, test.required)
+		}
+	}
+	if f := s.Find("/_acme/unknown"); f != nil {
+		t.Errorf("Find(unknown) = %v, want nil", f)
+	}
+	if items := s.Find("/tags").Items; items == nil || items.Type != String {
+		t.Errorf("items of tags = %v, want string", items)
+	}
+	if values := s.Find("/attributes").Items; values == nil || values.Type != Double {
+		t.Errorf("values of attributes = %v, want double", values)
+	}
+	if f := s.Find("/_acme/loyaltyId"); f.Identity != "Loyalty" || !f.Primary {
+		t.Errorf("identity of loyaltyId = %q primary %v", f.Identity, f.Primary)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	v := NewValidator(parseTestSchema(t))
+	tests := []struct {
+		record string
+		want   []string
+	}{
+		// valid records
+		{`{"_id": "1"}`, nil},
+		{`{"_id": "1", "_acme": {"loyaltyId": "42", "level": "gold", "points": 10, "small": -128},
+		   "timestamp": "2021-06-01T10:00:00.123Z", "birthDate": "1990-12-31", "email": "a@b.com",
+		   "active": true, "tags": ["a", "b"], "attributes": {"x": 1.5},
+		   "identityMap": {"Email": [{"id": "a@b.com"}]}}`, nil},
+		// types
+		{`[1]`, []string{"$: the record is not a JSON object"}},
+		{`{"_id": 1}`, []string{"$._id: expected string, got number"}},
+		{`{"_id": "1", "active": "yes"}`, []string{"$.active: expected boolean, got string"}},
+		{`{"_id": "1", "tags": "a"}`, []string{"$.tags: expected array, got string"}},
+		{`{"_id": "1", "tags": ["a", 2]}`, []string{"$.tags[1]: expected string, got number"}},
+		{`{"_id": "1", "attributes": {"x": "y"}}`, []string{"$.attributes.x: expected double, got string"}},
+		{`{"_id": "1", "_acme": []}`, []string{"$._acme: expected object, got array"}},
+		// integer ranges
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "points": 1.5}}`, []string{"$._acme.points: expected int, got 1.5"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "points": 2147483648}}`, []string{"$._acme.points: 2147483648 is out of the range of int"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "small": 128}}`, []string{"$._acme.small: 128 is out of the range of byte"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "points": -1}}`, []string{"$._acme.points: -1 is less than the minimum 0"}},
+		// required fields
+		{`{}`, []string{"$._id: required field is missing"}},
+		{`{"_id": null}`, []string{"$._id: required field is missing"}},
+		{`{"_id": "1", "_acme": {}}`, []string{"$._acme.loyaltyId: required field is missing"}},
+		// enum and pattern
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "level": "bronze"}}`, []string{"$._acme.level: value bronze is not one of [gold silver]"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "x1"}}`, []string{"$._acme.loyaltyId: \"x1\" does not match the pattern ^[0-9]+$"}},
+		// formats
+		{`{"_id": "1", "timestamp": "2021-06-01"}`, []string{"$.timestamp: \"2021-06-01\" is not a valid date-time"}},
+		{`{"_id": "1", "birthDate": "1990-02-30"}`, []string{"$.birthDate: \"1990-02-30\" is not a valid date"}},
+		{`{"_id": "1", "email": "nope"}`, []string{"$.email: \"nope\" is not a valid email"}},
+		// tenant namespace
+		{`{"_id": "1", "name": "x"}`, []string{"$.name: unknown field, custom fields belong to the tenant namespace _acme"}},
+		{`{"_id": "1", "_other": {}}`, []string{"$._other: unknown namespace, the tenant namespace is _acme"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "1", "foo": 1}}`, []string{"$._acme.foo: unknown field"}},
+		// identities
+		{`{"_id": "1", "identityMap": {"Email": [{"primary": true}]}}`, []string{"$.identityMap.Email[0].id: required field is missing"}},
+		{`{"_id": "1", "identityMap": {"Email": [{"id": " "}]}}`, []string{"$.identityMap.Email[0].id: empty identity of namespace Email"}},
+		{`{"_id": "1", "identityMap": {"Email": [{"id": "nope"}]}}`, []string{"$.identityMap.Email[0].id: \"nope\" is not a valid email identity"}},
+		{`{"_id": "1", "identityMap": {"my-ns": [{"id": "1"}]}}`, []string{"$.identityMap['my-ns']: invalid identity namespace"}},
+		{`{"_id": "1", "_acme": {"loyaltyId": "1"}, "identityMap": {"ECID": [{"id": "1", "primary": true}]}}`,
+			[]string{"$: 2 primary identities, only one is allowed"}},
+		{`{"_id": "1", "identityMap": {"ECID": [{"id": "1", "primary": true}, {"id": "2", "primary": true}]}}`,
+			[]string{"$: 2 primary identities, only one is allowed"}},
+	}
+	for _, test := range tests {
+		dec := json.NewDecoder(strings.NewReader(test.record))
+		dec.UseNumber()
+		var record interface{}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, violation := range v.Validate(record) {
+			got = append(got, violation.Path+": "+violation.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(test.want, "\n") {
+			t.Errorf("Validate(%s) = %q, want %q", test.record, got, test.want)
+		}
+	}
+}